@@ -1,68 +1,223 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/expiry"
+	"github.com/magneless/merch-shop/internal/fraud"
+	grpcserver "github.com/magneless/merch-shop/internal/grpc-server"
+	"github.com/magneless/merch-shop/internal/http-server/openapi"
 	"github.com/magneless/merch-shop/internal/http-server/router"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/notify"
+	notifymemory "github.com/magneless/merch-shop/internal/notify/memory"
+	"github.com/magneless/merch-shop/internal/notify/pgbroker"
+	"github.com/magneless/merch-shop/internal/outbox"
+	"github.com/magneless/merch-shop/internal/outbox/sink/memory"
 	"github.com/magneless/merch-shop/internal/repository"
+	"github.com/magneless/merch-shop/internal/scheduler"
 	"github.com/magneless/merch-shop/internal/storage/postgre"
+	"github.com/magneless/merch-shop/internal/webhook"
+	"google.golang.org/grpc"
 )
 
 const (
-	envLocal = "local"
-	envDev   = "dev"
-	envProd  = "prod"
+	shutdownTimeout = 10 * time.Second
+
+	brokerMemory   = "memory"
+	brokerPostgres = "postgres"
 )
 
 func main() {
 	cfg := config.MustLoad()
-	log := setupLogger(cfg.Env)
+	log, logLevel, err := logger.New(cfg.Env, cfg.Logger)
+	if err != nil {
+		slog.Error("failed to init logger", sl.Err(err))
+		os.Exit(1)
+	}
 
 	log.Info("starting merch-shop", slog.String("env", cfg.Env))
 	log.Debug("debug messages are enabled")
 
+	if err := i18n.Check(); err != nil {
+		log.Error("invalid message catalog", sl.Err(err))
+		os.Exit(1)
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Error("failed to load openapi spec", sl.Err(err))
+		os.Exit(1)
+	}
+
 	storage, err := postgre.New(cfg.Storage)
 	if err != nil {
 		log.Error("failed to init storage", sl.Err(err))
 		os.Exit(1)
 	}
 
-	repo := repository.New(storage)
+	if len(cfg.Approvals.Policies) > 0 && cfg.Approvals.Timeout <= 0 {
+		log.Error("approval timeout must be positive", slog.Duration("timeout", cfg.Approvals.Timeout))
+		os.Exit(1)
+	}
+
+	repo := repository.New(storage, cfg.Coins, cfg.TransferLimits, cfg.Approvals, cfg.Orders)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	localEvents := memory.New()
+	localEvents.Subscribe(func(_ context.Context, e events.Event) error {
+		log.Debug("event published", slog.Int64("event_id", e.ID), slog.String("event_type", e.Type))
+		return nil
+	})
+
+	var broker notify.Broker
+	var wg sync.WaitGroup
+
+	switch cfg.Notify.Broker {
+	case brokerMemory:
+		memBroker := notifymemory.New(cfg.Notify.Buffer)
+		broker = memBroker
+
+		// открытые SSE-потоки не дадут серверу остановиться, закрываем их вместе с контекстом
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ctx.Done()
+			memBroker.CloseAll()
+		}()
+	case brokerPostgres:
+		pgBroker := pgbroker.New(log, storage, postgre.DSN(cfg.Storage), cfg.Notify.Buffer)
+		broker = pgBroker
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pgBroker.Run(ctx); err != nil {
+				log.Error("notification broker stopped", sl.Err(err))
+			}
+		}()
+	default:
+		log.Error("unknown notification broker", slog.String("broker", cfg.Notify.Broker))
+		os.Exit(1)
+	}
+
+	dispatcher := outbox.NewDispatcher(log, repo, cfg.Outbox,
+		localEvents,
+		webhook.NewSink(repo),
+		notify.NewSink(broker),
+	)
+	deliverer := webhook.NewDeliverer(log, repo, cfg.Webhooks)
+	transferScheduler := scheduler.New(log, repo, cfg.Scheduler)
+	coinExpirer := expiry.New(log, repo, cfg.Coins)
+	fraudDetector := fraud.New(log, repo, cfg.Fraud)
 
-	srv := &http.Server {
-		Addr:         cfg.Address,
-		Handler:      router.New(log, repo),
-		ReadTimeout:  cfg.Timeout,
-		WriteTimeout: cfg.Timeout,
-		IdleTimeout:  cfg.IdleTimeout,
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		dispatcher.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		deliverer.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		transferScheduler.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		coinExpirer.Run(ctx)
+	}()
+	if cfg.Fraud.Enabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fraudDetector.Run(ctx)
+		}()
 	}
 
+	srv := &http.Server{
+		Addr: cfg.HTTPServer.Address,
+		Handler: router.New(log, repo, router.Options{
+			LogLevel:  logLevel,
+			Admins:    cfg.Admins,
+			Staff:     cfg.Staff,
+			Broker:    broker,
+			Heartbeat: cfg.Notify.Heartbeat,
+			WebSocket: cfg.WebSocket,
+			OpenAPI:   spec,
 
-	if err := srv.ListenAndServe(); err != nil {
+			CoinRequestTTL: cfg.CoinRequests.TTL,
+			FraudScanner:   fraudDetector,
 
+			ValidateRequests:  cfg.OpenAPI.ValidateRequests,
+			ValidateResponses: cfg.OpenAPI.ValidateResponses,
+			LegacyErrors:      cfg.HTTPServer.LegacyErrors,
+		}),
+		ReadTimeout:  cfg.HTTPServer.Timeout,
+		WriteTimeout: cfg.HTTPServer.Timeout,
+		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
 	}
-}
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envLocal:
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envDev:
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+	go func() {
+		log.Info("starting server", slog.String("address", cfg.HTTPServer.Address))
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("failed to start server", sl.Err(err))
+			stop()
+		}
+	}()
+
+	var grpcSrv *grpc.Server
+	if cfg.GRPCServer.Address != "" {
+		lis, err := net.Listen("tcp", cfg.GRPCServer.Address)
+		if err != nil {
+			log.Error("failed to listen grpc address", sl.Err(err))
+			os.Exit(1)
+		}
+
+		grpcSrv = grpcserver.New(log, repo)
+		go func() {
+			log.Info("starting grpc server", slog.String("address", cfg.GRPCServer.Address))
+
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Error("failed to start grpc server", sl.Err(err))
+				stop()
+			}
+		}()
 	}
-	return log
+
+	<-ctx.Done()
+	log.Info("stopping server")
+
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to stop server", sl.Err(err))
+	}
+
+	wg.Wait()
+	storage.Close()
+
+	log.Info("server stopped")
 }
@@ -10,9 +10,24 @@ import (
 )
 
 type Config struct {
-	Env        string `yaml:"env" env-required:"true"`
-	Storage    `yaml:"storage"`
-	HTTPServer `yaml:"http_server"`
+	Env            string `yaml:"env" env-required:"true"`
+	Storage        `yaml:"storage"`
+	HTTPServer     `yaml:"http_server"`
+	GRPCServer     `yaml:"grpc_server"`
+	Logger         `yaml:"logger"`
+	Access         `yaml:"access"`
+	Outbox         `yaml:"outbox"`
+	Webhooks       `yaml:"webhooks"`
+	Notify         `yaml:"notifications"`
+	WebSocket      `yaml:"websocket"`
+	OpenAPI        `yaml:"openapi"`
+	CoinRequests   `yaml:"coin_requests"`
+	Scheduler      `yaml:"scheduler"`
+	Coins          `yaml:"coins"`
+	TransferLimits `yaml:"transfer_limits"`
+	Fraud          `yaml:"fraud"`
+	Approvals      `yaml:"approvals"`
+	Orders         `yaml:"orders"`
 }
 
 type Storage struct {
@@ -28,6 +43,176 @@ type HTTPServer struct {
 	Address     string        `yaml:"address" env-required:"true"`
 	Timeout     time.Duration `yaml:"timeout" env-required:"false"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-required:"false"`
+	// LegacyErrors возвращает ошибки в старом формате {"error": "..."} вместо problem+json.
+	LegacyErrors bool `yaml:"legacy_errors" env:"LEGACY_ERRORS"`
+}
+
+// GRPCServer - адрес gRPC API. Пустой адрес отключает сервер.
+type GRPCServer struct {
+	Address string `yaml:"address" env:"GRPC_ADDRESS"`
+}
+
+// Logger описывает формат, уровень и вывод логов.
+// Пустой уровень выбирается по env.
+type Logger struct {
+	Level  string  `yaml:"level" env:"LOG_LEVEL"`
+	Format string  `yaml:"format" env:"LOG_FORMAT" env-default:"text"`
+	Output string  `yaml:"output" env:"LOG_OUTPUT" env-default:"stdout"`
+	File   LogFile `yaml:"file"`
+}
+
+// LogFile используется, когда Output = "file".
+type LogFile struct {
+	Path       string `yaml:"path" env:"LOG_FILE"`
+	MaxSizeMB  int    `yaml:"max_size_mb" env-default:"100"`
+	MaxBackups int    `yaml:"max_backups" env-default:"5"`
+	MaxAgeDays int    `yaml:"max_age_days" env-default:"30"`
+	Compress   bool   `yaml:"compress"`
+}
+
+type Access struct {
+	Admins []string `yaml:"admins"`
+	// Staff - сотрудники склада, которые ведут выдачу заказов.
+	Staff []string `yaml:"staff"`
+}
+
+// Outbox - настройки фоновой рассылки доменных событий.
+type Outbox struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`
+	RetryBase    time.Duration `yaml:"retry_base" env-default:"1s"`
+	RetryMax     time.Duration `yaml:"retry_max" env-default:"5m"`
+	// после MaxAttempts неудачных попыток событие переходит в dead-letter
+	MaxAttempts int `yaml:"max_attempts" env-default:"10"`
+	// на сколько взятое событие скрыто от других реплик
+	Lease time.Duration `yaml:"lease" env-default:"1m"`
+}
+
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"20"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"8"`
+	RetryBase    time.Duration `yaml:"retry_base" env-default:"5s"`
+	RetryMax     time.Duration `yaml:"retry_max" env-default:"1h"`
+	// AllowedNetworks - подсети (CIDR), куда можно доставлять вебхуки, хотя они
+	// локальные или частные. По умолчанию такие адреса запрещены.
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
+// Notify - настройки уведомлений в реальном времени.
+// Broker: "memory" для одного инстанса, "postgres" для нескольких реплик.
+type Notify struct {
+	Broker    string        `yaml:"broker" env-default:"memory"`
+	Buffer    int           `yaml:"buffer" env-default:"64"`
+	Heartbeat time.Duration `yaml:"heartbeat" env-default:"15s"`
+}
+
+type WebSocket struct {
+	// AllowedOrigins - разрешенные Origin помимо собственного хоста.
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	SendBuffer       int           `yaml:"send_buffer" env-default:"64"`
+	PingPeriod       time.Duration `yaml:"ping_period" env-default:"30s"`
+	PongWait         time.Duration `yaml:"pong_wait" env-default:"60s"`
+	MaxSubscriptions int           `yaml:"max_subscriptions" env-default:"16"`
+}
+
+// OpenAPI - проверка запросов и ответов по спецификации API.
+// Проверку ответов стоит включать только в dev-окружении: ответы буферизуются.
+type OpenAPI struct {
+	ValidateRequests  bool `yaml:"validate_requests" env-default:"true"`
+	ValidateResponses bool `yaml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES"`
+}
+
+// CoinRequests - просьбы о переводе монет. TTL - через сколько
+// неотвеченная просьба истекает.
+type CoinRequests struct {
+	TTL time.Duration `yaml:"ttl" env-default:"72h"`
+}
+
+// Scheduler - фоновое выполнение запланированных переводов, кампаний грантов и отмена несогласованных заказов.
+// Grace - на сколько запуск может опоздать, прежде чем правило catchUp=skip его пропустит.
+type Scheduler struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"10s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"50"`
+	Grace        time.Duration `yaml:"grace" env-default:"15m"`
+}
+
+// Правила срока годности монет при переводе.
+const (
+	// LotExpiryKeep - переведенные монеты сгорают тогда же, когда сгорели бы у отправителя.
+	LotExpiryKeep = "keep"
+	// LotExpiryReset - у получателя срок отсчитывается заново.
+	LotExpiryReset = "reset"
+)
+
+// Coins - начисления монет. InitialBalance получает каждый новый сотрудник.
+// Монеты сгорают через LotTTL после начисления (0 - не сгорают), TransferExpiry -
+// что происходит со сроком при переводе. Раз в ExpireInterval фоновая задача
+// списывает до ExpireBatch просроченных лотов.
+type Coins struct {
+	InitialBalance int           `yaml:"initial_balance" env-default:"1000"`
+	LotTTL         time.Duration `yaml:"lot_ttl" env-default:"8760h"`
+	TransferExpiry string        `yaml:"transfer_expiry" env-default:"keep"`
+	ExpireInterval time.Duration `yaml:"expire_interval" env-default:"1h"`
+	ExpireBatch    int           `yaml:"expire_batch" env-default:"500"`
+}
+
+// TransferLimits - лимиты исходящих переводов сотрудника по умолчанию, 0 - без ограничения.
+// Дневные лимиты сбрасываются в полночь UTC, недельные - в полночь на понедельник.
+// Администратор может переопределить их для отдельного сотрудника.
+type TransferLimits struct {
+	MaxTransfer     int `yaml:"max_transfer" env-default:"0"`
+	Daily           int `yaml:"daily" env-default:"0"`
+	Weekly          int `yaml:"weekly" env-default:"0"`
+	DailyRecipients int `yaml:"daily_recipients" env-default:"0"`
+}
+
+// Fraud - поиск подозрительных переводов. Раз в PollInterval анализируются новые
+// переводы, раз в BatchInterval (0 - никогда) - все переводы за Window.
+// HoldTransfers запрещает отмеченным сотрудникам переводить монеты до проверки.
+type Fraud struct {
+	Enabled       bool          `yaml:"enabled" env-default:"true"`
+	PollInterval  time.Duration `yaml:"poll_interval" env-default:"1m"`
+	BatchInterval time.Duration `yaml:"batch_interval" env-default:"24h"`
+	Window        time.Duration `yaml:"window" env-default:"168h"`
+	HoldTransfers bool          `yaml:"hold_transfers" env-default:"false"`
+	// RescanWindow - переводы моложе этого окна инкрементальный анализ проверяет
+	// повторно: перевод с меньшим ID может закоммититься позже курсора.
+	RescanWindow time.Duration `yaml:"rescan_window" env-default:"5m"`
+	// MaxCycleLength - самый длинный круг переводов A -> B -> ... -> A, который ищется.
+	MaxCycleLength int `yaml:"max_cycle_length" env-default:"4"`
+	// PingPongWindow - за сколько монеты должны вернуться отправителю, чтобы считаться
+	// пинг-понгом; PingPongMinReturns - сколько таких возвратов подозрительно.
+	PingPongWindow     time.Duration `yaml:"ping_pong_window" env-default:"1h"`
+	PingPongMinReturns int           `yaml:"ping_pong_min_returns" env-default:"2"`
+	// Новый сотрудник - зарегистрированный не раньше чем за FreshAccountAge до перевода.
+	// Подозрительно, если одному получателю переводят FanInMinSenders новых сотрудников.
+	FreshAccountAge time.Duration `yaml:"fresh_account_age" env-default:"24h"`
+	FanInMinSenders int           `yaml:"fan_in_min_senders" env-default:"3"`
+}
+
+// Approvals - согласование покупок. Покупка, под которую подходит одно из Policies,
+// не выполняется сразу: монеты резервируются, а заказ ждет решения руководителя
+// отдела покупателя не дольше Timeout.
+type Approvals struct {
+	Timeout  time.Duration    `yaml:"timeout" env-default:"72h"`
+	Policies []ApprovalPolicy `yaml:"policies"`
+}
+
+// ApprovalPolicy срабатывает, если выполнены все условия: покупатель из отдела
+// Departments, товар из Items, сумма покупки не меньше MinTotal. Пустой список - любой.
+type ApprovalPolicy struct {
+	Name        string   `yaml:"name"`
+	MinTotal    int      `yaml:"min_total"`
+	Items       []string `yaml:"items"`
+	Departments []string `yaml:"departments"`
+}
+
+// Orders - отмена и возврат покупок. В течение CancelWindow после покупки сотрудник
+// может сам отменить невыданный заказ, позже - только вернуть товар с одобрения склада.
+type Orders struct {
+	CancelWindow time.Duration `yaml:"cancel_window" env-default:"15m"`
 }
 
 func MustLoad() *Config {
@@ -49,6 +234,11 @@ func MustLoad() *Config {
 		log.Fatalf("cant read config: %s", err)
 	}
 
+	if cfg.Coins.TransferExpiry != LotExpiryKeep && cfg.Coins.TransferExpiry != LotExpiryReset {
+		log.Fatalf("unknown coins.transfer_expiry %q: want %q or %q",
+			cfg.Coins.TransferExpiry, LotExpiryKeep, LotExpiryReset)
+	}
+
 	cfg.Password = os.Getenv("DB_PASSWORD")
 
 	return &cfg
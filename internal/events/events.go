@@ -0,0 +1,160 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	TypeUserRegistered   = "UserRegistered"
+	TypeCoinsTransferred = "CoinsTransferred"
+	TypeMerchPurchased   = "MerchPurchased"
+	TypeCoinsExpired     = "CoinsExpired"
+
+	TypeScheduledTransferPaused = "ScheduledTransferPaused"
+	TypeOrderStatusChanged      = "OrderStatusChanged"
+)
+
+// Types - все известные типы событий.
+func Types() []string {
+	return []string{TypeUserRegistered, TypeCoinsTransferred, TypeMerchPurchased, TypeCoinsExpired, TypeScheduledTransferPaused, TypeOrderStatusChanged}
+}
+
+// Event - доменное событие в том виде, в каком оно лежит в outbox.
+// ID уникален и используется потребителями для дедупликации. Он выдается
+// до коммита, поэтому события публикуются не строго по возрастанию ID.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Usernames []string        `json:"usernames"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Pending - событие, взятое диспетчером outbox в работу.
+// DeliveredSinks - получатели, которые уже приняли его на прошлых попытках.
+type Pending struct {
+	Event
+	Attempts       int
+	DeliveredSinks []string
+}
+
+// Payload - тело конкретного события.
+type Payload interface {
+	EventType() string
+	// Participants возвращает сотрудников, которых касается событие.
+	Participants() []string
+}
+
+type UserRegistered struct {
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+}
+
+func (UserRegistered) EventType() string        { return TypeUserRegistered }
+func (e UserRegistered) Participants() []string { return []string{e.Username} }
+
+type CoinsTransferred struct {
+	FromUser    string `json:"fromUser"`
+	ToUser      string `json:"toUser"`
+	Amount      int    `json:"amount"`
+	FromBalance int    `json:"fromBalance"`
+	ToBalance   int    `json:"toBalance"`
+	BatchID     int64  `json:"batchId,omitempty"`
+}
+
+func (CoinsTransferred) EventType() string        { return TypeCoinsTransferred }
+func (e CoinsTransferred) Participants() []string { return []string{e.FromUser, e.ToUser} }
+
+// MerchPurchased - Username купил товар. С Recipient это подарок с поздравлением
+// Message: товар попал в инвентарь Recipient, Balance - баланс покупателя.
+type MerchPurchased struct {
+	Username  string `json:"username"`
+	Recipient string `json:"recipient,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Item      string `json:"item"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Total     int    `json:"total"`
+	Balance   int    `json:"balance"`
+}
+
+func (MerchPurchased) EventType() string { return TypeMerchPurchased }
+func (e MerchPurchased) Participants() []string {
+	if e.Recipient != "" {
+		return []string{e.Username, e.Recipient}
+	}
+	return []string{e.Username}
+}
+
+// CoinsExpired - у Username сгорели Amount монет, Balance - баланс после этого.
+type CoinsExpired struct {
+	Username string `json:"username"`
+	Amount   int    `json:"amount"`
+	Balance  int    `json:"balance"`
+}
+
+func (CoinsExpired) EventType() string        { return TypeCoinsExpired }
+func (e CoinsExpired) Participants() []string { return []string{e.Username} }
+
+// ScheduledTransferPaused - планировщик приостановил перевод Username,
+// например потому, что не хватило монет.
+type ScheduledTransferPaused struct {
+	Username   string `json:"username"`
+	ScheduleID int64  `json:"scheduleId"`
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Reason     string `json:"reason"`
+}
+
+func (ScheduledTransferPaused) EventType() string        { return TypeScheduledTransferPaused }
+func (e ScheduledTransferPaused) Participants() []string { return []string{e.Username} }
+
+// OrderStatusChanged - заказ OrderID сотрудника Username перешел в статус Status.
+// Balance - баланс покупателя после этого. Approvers - кто может согласовать заказ,
+// заполняется, когда заказ ждет согласования.
+type OrderStatusChanged struct {
+	OrderID   int64    `json:"orderId"`
+	Username  string   `json:"username"`
+	Item      string   `json:"item"`
+	Quantity  int      `json:"quantity"`
+	Total     int      `json:"total"`
+	Status    string   `json:"status"`
+	Reason    string   `json:"reason,omitempty"`
+	Balance   int      `json:"balance"`
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+func (OrderStatusChanged) EventType() string { return TypeOrderStatusChanged }
+func (e OrderStatusChanged) Participants() []string {
+	return append([]string{e.Username}, e.Approvers...)
+}
+
+// Decode разбирает Payload события в структуру нужного типа.
+func Decode(e Event) (Payload, error) {
+	var p Payload
+	switch e.Type {
+	case TypeUserRegistered:
+		p = &UserRegistered{}
+	case TypeCoinsTransferred:
+		p = &CoinsTransferred{}
+	case TypeMerchPurchased:
+		p = &MerchPurchased{}
+	case TypeCoinsExpired:
+		p = &CoinsExpired{}
+	case TypeScheduledTransferPaused:
+		p = &ScheduledTransferPaused{}
+	case TypeOrderStatusChanged:
+		p = &OrderStatusChanged{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+
+	if err := json.Unmarshal(e.Payload, p); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", e.Type, err)
+	}
+
+	return p, nil
+}
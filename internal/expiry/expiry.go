@@ -0,0 +1,91 @@
+// Package expiry списывает монеты, срок годности которых истек.
+package expiry
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+// Actor - от чьего имени списание пишется в журнал аудита.
+const Actor = "expiry"
+
+type Store interface {
+	DueCoinLots(ctx context.Context, now time.Time, limit int) ([]models.CoinLot, error)
+	ExpireCoinLot(ctx context.Context, actor string, lot models.CoinLot, now time.Time) error
+}
+
+// Expirer раз в ExpireInterval списывает просроченные лоты монет.
+type Expirer struct {
+	log   *slog.Logger
+	store Store
+	cfg   config.Coins
+}
+
+func New(log *slog.Logger, store Store, cfg config.Coins) *Expirer {
+	return &Expirer{
+		log:   log.With(slog.String("component", "expiry")),
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+func (e *Expirer) Run(ctx context.Context) {
+	e.log.Info("coin expiry started")
+
+	ticker := time.NewTicker(e.cfg.ExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		// полная пачка - вероятно, есть еще, не ждем следующего тика
+		for e.expireDue(ctx) == e.cfg.ExpireBatch && ctx.Err() == nil {
+		}
+
+		select {
+		case <-ctx.Done():
+			e.log.Info("coin expiry stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// expireDue списывает одну пачку лотов и возвращает, сколько лотов обработано без ошибок.
+func (e *Expirer) expireDue(ctx context.Context) int {
+	now := time.Now()
+
+	lots, err := e.store.DueCoinLots(ctx, now, e.cfg.ExpireBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.log.Error("failed to fetch expired coin lots", sl.Err(err))
+		}
+		return 0
+	}
+
+	done := 0
+	for _, lot := range lots {
+		if ctx.Err() != nil {
+			return 0
+		}
+
+		if err := e.store.ExpireCoinLot(ctx, Actor, lot, now); err != nil {
+			e.log.Error("failed to expire coin lot",
+				slog.Int64("lot_id", lot.ID),
+				slog.String("username", lot.Username),
+				sl.Err(err),
+			)
+			continue
+		}
+		done++
+	}
+
+	if done > 0 {
+		e.log.Info("expired coin lots processed", slog.Int("lots", done))
+	}
+
+	return done
+}
@@ -0,0 +1,237 @@
+package fraud
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+// maxCycleSteps ограничивает перебор путей от одного начального перевода, чтобы
+// плотный граф переводов не подвесил анализ. Бюджет у каждого перевода свой:
+// общий тратился бы на старые переводы, и круги через новые не находились бы.
+const maxCycleSteps = 20_000
+
+// Analyze ищет подозрительные схемы в переводах txs (по возрастанию ID).
+// Возвращаются только схемы, в которых есть перевод с ID больше afterID:
+// инкрементальный анализ передает последний проверенный перевод, полный - 0.
+func Analyze(txs []models.TransferRecord, cfg config.Fraud, afterID int64) []models.FraudFinding {
+	var findings []models.FraudFinding
+
+	findings = append(findings, cycles(txs, cfg.MaxCycleLength)...)
+	findings = append(findings, pingPongs(txs, cfg)...)
+	findings = append(findings, freshFanIns(txs, cfg)...)
+
+	result := findings[:0]
+	for _, f := range findings {
+		if slices.Max(f.TransactionIDs) > afterID {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// cycles ищет круги A -> B -> ... -> A длиной от 3 до maxLen, в которых каждый
+// следующий перевод сделан после предыдущего. Круги из двух человек - это пинг-понг.
+func cycles(txs []models.TransferRecord, maxLen int) []models.FraudFinding {
+	if maxLen < 3 {
+		return nil
+	}
+
+	outgoing := make(map[string][]int)
+	for i, t := range txs {
+		outgoing[t.FromUser] = append(outgoing[t.FromUser], i)
+	}
+
+	found := make(map[string]*models.FraudFinding)
+	var order []string
+	steps := 0
+
+	var path []int
+	var walk func(start string, last int)
+	walk = func(start string, last int) {
+		for _, i := range outgoing[txs[last].ToUser] {
+			if i <= last || steps >= maxCycleSteps {
+				continue
+			}
+			steps++
+
+			t := txs[i]
+			switch {
+			case t.ToUser == start && len(path)+1 >= 3:
+				addCycle(found, &order, txs, append(path, i))
+			case t.ToUser == start || len(path)+1 >= maxLen || visited(txs, path, t.ToUser):
+			default:
+				path = append(path, i)
+				walk(start, i)
+				path = path[:len(path)-1]
+			}
+		}
+	}
+
+	for i, t := range txs {
+		steps = 0
+		path = append(path[:0], i)
+		walk(t.FromUser, i)
+	}
+
+	result := make([]models.FraudFinding, 0, len(order))
+	for _, key := range order {
+		f := found[key]
+		slices.Sort(f.TransactionIDs)
+		f.TransactionIDs = slices.Compact(f.TransactionIDs)
+		result = append(result, *f)
+	}
+
+	return result
+}
+
+func visited(txs []models.TransferRecord, path []int, username string) bool {
+	for _, i := range path {
+		if txs[i].FromUser == username {
+			return true
+		}
+	}
+	return false
+}
+
+func addCycle(found map[string]*models.FraudFinding, order *[]string, txs []models.TransferRecord, path []int) {
+	usernames := make([]string, 0, len(path))
+	ids := make([]int64, 0, len(path))
+	route := make([]string, 0, len(path)+1)
+	for _, i := range path {
+		usernames = append(usernames, txs[i].FromUser)
+		ids = append(ids, txs[i].ID)
+		route = append(route, txs[i].FromUser)
+	}
+	route = append(route, txs[path[0]].FromUser)
+	sort.Strings(usernames)
+
+	key := strings.Join(usernames, ",")
+	if f, ok := found[key]; ok {
+		f.TransactionIDs = append(f.TransactionIDs, ids...)
+		return
+	}
+
+	found[key] = &models.FraudFinding{
+		Rule:           models.FraudCycle,
+		Key:            key,
+		Usernames:      usernames,
+		TransactionIDs: ids,
+		Details:        "circular transfers " + strings.Join(route, " -> "),
+	}
+	*order = append(*order, key)
+}
+
+// pingPongs ищет пары, которые возвращают монеты друг другу: перевод B -> A
+// не позже чем через PingPongWindow после A -> B. Подозрительно от PingPongMinReturns возвратов.
+func pingPongs(txs []models.TransferRecord, cfg config.Fraud) []models.FraudFinding {
+	type pair struct {
+		returns int
+		ids     []int64
+	}
+
+	pairs := make(map[string]*pair)
+	var order []string
+	// последний перевод в каждом направлении
+	last := make(map[[2]string]int)
+
+	for i, t := range txs {
+		a, b := t.FromUser, t.ToUser
+		if a > b {
+			a, b = b, a
+		}
+		key := a + "," + b
+
+		p, ok := pairs[key]
+		if !ok {
+			p = &pair{}
+			pairs[key] = p
+			order = append(order, key)
+		}
+
+		if j, ok := last[[2]string{t.ToUser, t.FromUser}]; ok && t.CreatedAt.Sub(txs[j].CreatedAt) <= cfg.PingPongWindow {
+			p.returns++
+			p.ids = append(p.ids, txs[j].ID, t.ID)
+		}
+		last[[2]string{t.FromUser, t.ToUser}] = i
+	}
+
+	var result []models.FraudFinding
+	for _, key := range order {
+		p := pairs[key]
+		if p.returns == 0 || p.returns < cfg.PingPongMinReturns {
+			continue
+		}
+
+		slices.Sort(p.ids)
+		usernames := strings.Split(key, ",")
+		result = append(result, models.FraudFinding{
+			Rule:           models.FraudPingPong,
+			Key:            key,
+			Usernames:      usernames,
+			TransactionIDs: slices.Compact(p.ids),
+			Details: fmt.Sprintf("%s and %s returned coins to each other %d times within %s",
+				usernames[0], usernames[1], p.returns, cfg.PingPongWindow),
+		})
+	}
+
+	return result
+}
+
+// freshFanIns ищет получателей, которым переводят FanInMinSenders и больше
+// сотрудников, зарегистрированных не раньше чем за FreshAccountAge до перевода.
+func freshFanIns(txs []models.TransferRecord, cfg config.Fraud) []models.FraudFinding {
+	if cfg.FanInMinSenders <= 0 {
+		return nil
+	}
+
+	type fanIn struct {
+		senders []string
+		ids     []int64
+	}
+
+	receivers := make(map[string]*fanIn)
+	var order []string
+
+	for _, t := range txs {
+		if t.CreatedAt.Sub(t.FromCreatedAt) > cfg.FreshAccountAge {
+			continue
+		}
+
+		f, ok := receivers[t.ToUser]
+		if !ok {
+			f = &fanIn{}
+			receivers[t.ToUser] = f
+			order = append(order, t.ToUser)
+		}
+		if !slices.Contains(f.senders, t.FromUser) {
+			f.senders = append(f.senders, t.FromUser)
+		}
+		f.ids = append(f.ids, t.ID)
+	}
+
+	var result []models.FraudFinding
+	for _, receiver := range order {
+		f := receivers[receiver]
+		if len(f.senders) < cfg.FanInMinSenders {
+			continue
+		}
+
+		sort.Strings(f.senders)
+		result = append(result, models.FraudFinding{
+			Rule:           models.FraudFreshFanIn,
+			Key:            receiver,
+			Usernames:      append([]string{receiver}, f.senders...),
+			TransactionIDs: f.ids,
+			Details: fmt.Sprintf("%s received coins from %d accounts younger than %s: %s",
+				receiver, len(f.senders), cfg.FreshAccountAge, strings.Join(f.senders, ", ")),
+		})
+	}
+
+	return result
+}
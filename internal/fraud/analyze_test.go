@@ -0,0 +1,203 @@
+package fraud
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+var (
+	baseTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	testCfg  = config.Fraud{
+		MaxCycleLength:     4,
+		PingPongWindow:     time.Hour,
+		PingPongMinReturns: 2,
+		FreshAccountAge:    24 * time.Hour,
+		FanInMinSenders:    3,
+	}
+)
+
+// transfer - перевод id через minute минут после baseTime от давно зарегистрированного сотрудника.
+func transfer(id int64, from, to string, minute int) models.TransferRecord {
+	return models.TransferRecord{
+		ID:            id,
+		FromUser:      from,
+		ToUser:        to,
+		Amount:        10,
+		CreatedAt:     baseTime.Add(time.Duration(minute) * time.Minute),
+		FromCreatedAt: baseTime.Add(-30 * 24 * time.Hour),
+	}
+}
+
+func fresh(t models.TransferRecord) models.TransferRecord {
+	t.FromCreatedAt = t.CreatedAt.Add(-time.Hour)
+	return t
+}
+
+type want struct {
+	rule string
+	key  string
+	ids  []int64
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		txs     []models.TransferRecord
+		afterID int64
+		want    []want
+	}{
+		{
+			name: "cycle of three",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "carol", 1),
+				transfer(3, "carol", "anna", 2),
+			},
+			want: []want{{models.FraudCycle, "anna,bob,carol", []int64{1, 2, 3}}},
+		},
+		{
+			name: "cycle out of time order",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "carol", "anna", 1),
+				transfer(3, "bob", "carol", 2),
+			},
+		},
+		{
+			name: "cycle longer than max length",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "carol", 1),
+				transfer(3, "carol", "dave", 2),
+				transfer(4, "dave", "eve", 3),
+				transfer(5, "eve", "anna", 4),
+			},
+		},
+		{
+			name: "cycle of four",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "carol", 1),
+				transfer(3, "carol", "dave", 2),
+				transfer(4, "dave", "anna", 3),
+			},
+			want: []want{{models.FraudCycle, "anna,bob,carol,dave", []int64{1, 2, 3, 4}}},
+		},
+		{
+			name: "ping-pong",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "anna", 10),
+				transfer(3, "anna", "bob", 20),
+			},
+			want: []want{{models.FraudPingPong, "anna,bob", []int64{1, 2, 3}}},
+		},
+		{
+			name: "single return is not ping-pong",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "anna", 10),
+			},
+		},
+		{
+			name: "slow returns",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "anna", 90),
+				transfer(3, "anna", "bob", 180),
+			},
+		},
+		{
+			name: "fresh fan-in",
+			txs: []models.TransferRecord{
+				fresh(transfer(1, "x1", "bob", 0)),
+				fresh(transfer(2, "x2", "bob", 1)),
+				transfer(3, "anna", "bob", 2),
+				fresh(transfer(4, "x3", "bob", 3)),
+			},
+			want: []want{{models.FraudFreshFanIn, "bob", []int64{1, 2, 4}}},
+		},
+		{
+			name: "fan-in from old accounts",
+			txs: []models.TransferRecord{
+				transfer(1, "x1", "bob", 0),
+				transfer(2, "x2", "bob", 1),
+				transfer(3, "x3", "bob", 2),
+			},
+		},
+		{
+			name: "scheme already checked",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "carol", 1),
+				transfer(3, "carol", "anna", 2),
+			},
+			afterID: 3,
+		},
+		{
+			name: "scheme completed by a new transfer",
+			txs: []models.TransferRecord{
+				transfer(1, "anna", "bob", 0),
+				transfer(2, "bob", "carol", 1),
+				transfer(3, "carol", "anna", 2),
+			},
+			afterID: 2,
+			want:    []want{{models.FraudCycle, "anna,bob,carol", []int64{1, 2, 3}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(tt.txs, testCfg, tt.afterID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %+v, want %+v", got, tt.want)
+			}
+			for i, w := range tt.want {
+				f := got[i]
+				if f.Rule != w.rule || f.Key != w.key || !slices.Equal(f.TransactionIDs, w.ids) {
+					t.Errorf("finding %d = %s %s %v, want %s %s %v", i, f.Rule, f.Key, f.TransactionIDs, w.rule, w.key, w.ids)
+				}
+			}
+		})
+	}
+}
+
+// TestAnalyzeDenseWindow: плотный граф старых переводов не отнимает перебор
+// у новых, и круг через новые переводы находится.
+func TestAnalyzeDenseWindow(t *testing.T) {
+	var txs []models.TransferRecord
+	var id int64
+	users := 8
+	for round := 0; round < 6; round++ {
+		for from := 0; from < users; from++ {
+			for to := 0; to < users; to++ {
+				if from == to {
+					continue
+				}
+				id++
+				// ответные переводы позже окна пинг-понга
+				txs = append(txs, transfer(id, fmt.Sprint("u", from), fmt.Sprint("u", to), int(id)*120))
+			}
+		}
+	}
+	afterID := id
+	for i, pair := range [][2]string{{"anna", "bob"}, {"bob", "carol"}, {"carol", "anna"}} {
+		id++
+		txs = append(txs, transfer(id, pair[0], pair[1], int(id)*120+i))
+	}
+
+	found := false
+	for _, f := range Analyze(txs, testCfg, afterID) {
+		if f.Rule == models.FraudCycle && f.Key == "anna,bob,carol" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("cycle through new transfers was not found")
+	}
+}
@@ -0,0 +1,118 @@
+// Package fraud ищет подозрительные переводы: круги, пинг-понг между двумя
+// сотрудниками и переводы одному получателю от только что зарегистрированных.
+package fraud
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+// Actor - от чьего имени отметки пишутся в журнал аудита.
+const Actor = "fraud"
+
+type Store interface {
+	FraudCursor(ctx context.Context) (int64, error)
+	TransfersSince(ctx context.Context, since time.Time) ([]models.TransferRecord, error)
+	SaveFraudFindings(ctx context.Context, actor string, findings []models.FraudFinding, lastID int64, hold bool) (int, error)
+}
+
+// Detector раз в PollInterval проверяет новые переводы, а раз в BatchInterval -
+// все переводы за Window.
+type Detector struct {
+	log   *slog.Logger
+	store Store
+	cfg   config.Fraud
+}
+
+func New(log *slog.Logger, store Store, cfg config.Fraud) *Detector {
+	return &Detector{
+		log:   log.With(slog.String("component", "fraud")),
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+func (d *Detector) Run(ctx context.Context) {
+	d.log.Info("fraud detector started")
+
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+
+	var batch <-chan time.Time
+	if d.cfg.BatchInterval > 0 {
+		t := time.NewTicker(d.cfg.BatchInterval)
+		defer t.Stop()
+		batch = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.log.Info("fraud detector stopped")
+			return
+		case <-poll.C:
+			if _, err := d.scan(ctx, false); err != nil && ctx.Err() == nil {
+				d.log.Error("failed to analyze new transfers", sl.Err(err))
+			}
+		case <-batch:
+			if _, err := d.Scan(ctx); err != nil && ctx.Err() == nil {
+				d.log.Error("failed to analyze transfers", sl.Err(err))
+			}
+		}
+	}
+}
+
+// Scan проверяет все переводы за Window и возвращает число новых отметок.
+func (d *Detector) Scan(ctx context.Context) (int, error) {
+	return d.scan(ctx, true)
+}
+
+func (d *Detector) scan(ctx context.Context, full bool) (int, error) {
+	cursor, err := d.store.FraudCursor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	txs, err := d.store.TransfersSince(ctx, time.Now().Add(-d.cfg.Window))
+	if err != nil {
+		return 0, err
+	}
+
+	lastID := cursor
+	if len(txs) > 0 {
+		lastID = max(lastID, txs[len(txs)-1].ID)
+	}
+
+	afterID := cursor
+	// ID выдается до коммита, поэтому перевод с меньшим ID может появиться уже
+	// после того, как курсор ушел дальше. Недавние переводы проверяются повторно:
+	// отметки той же схемы не дублируются.
+	tail := time.Now().Add(-d.cfg.RescanWindow)
+	for _, t := range txs {
+		if t.ID <= afterID && !t.CreatedAt.Before(tail) {
+			afterID = t.ID - 1
+		}
+	}
+	if full {
+		afterID = 0
+	}
+	if !full && lastID == cursor && afterID == cursor {
+		return 0, nil
+	}
+
+	flagged, err := d.store.SaveFraudFindings(ctx, Actor, Analyze(txs, d.cfg, afterID), lastID, d.cfg.HoldTransfers)
+	if err != nil {
+		return 0, err
+	}
+
+	if flagged > 0 {
+		d.log.Warn("suspicious transfers flagged", slog.Int("flags", flagged), slog.Bool("full", full))
+	}
+
+	return flagged, nil
+}
@@ -0,0 +1,76 @@
+package fraud
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+type fakeStore struct {
+	cursor   int64
+	txs      []models.TransferRecord
+	findings []models.FraudFinding
+	saved    bool
+}
+
+func (s *fakeStore) FraudCursor(context.Context) (int64, error) { return s.cursor, nil }
+
+func (s *fakeStore) TransfersSince(context.Context, time.Time) ([]models.TransferRecord, error) {
+	return s.txs, nil
+}
+
+func (s *fakeStore) SaveFraudFindings(_ context.Context, _ string, findings []models.FraudFinding, lastID int64, _ bool) (int, error) {
+	s.saved = true
+	s.findings = findings
+	s.cursor = max(s.cursor, lastID)
+	return len(findings), nil
+}
+
+// lateCycle - круг, в котором перевод 2 закоммитился уже после того, как курсор дошел до 3.
+func lateCycle(createdAt time.Time) []models.TransferRecord {
+	txs := []models.TransferRecord{
+		{ID: 1, FromUser: "anna", ToUser: "bob"},
+		{ID: 2, FromUser: "bob", ToUser: "carol"},
+		{ID: 3, FromUser: "carol", ToUser: "anna"},
+	}
+	for i := range txs {
+		txs[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Second)
+		txs[i].FromCreatedAt = createdAt.Add(-30 * 24 * time.Hour)
+	}
+	return txs
+}
+
+func newTestDetector(store Store) *Detector {
+	cfg := testCfg
+	cfg.RescanWindow = 5 * time.Minute
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, cfg)
+}
+
+func TestScanRechecksRecentTail(t *testing.T) {
+	store := &fakeStore{cursor: 3, txs: lateCycle(time.Now().Add(-time.Minute))}
+
+	if _, err := newTestDetector(store).scan(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.findings) != 1 || store.findings[0].Key != "anna,bob,carol" {
+		t.Fatalf("findings = %+v, want the cycle through the late transfer", store.findings)
+	}
+	if store.cursor != 3 {
+		t.Errorf("cursor = %d, want 3", store.cursor)
+	}
+}
+
+func TestScanSkipsOldTransfersBehindCursor(t *testing.T) {
+	store := &fakeStore{cursor: 3, txs: lateCycle(time.Now().Add(-time.Hour))}
+
+	if _, err := newTestDetector(store).scan(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if store.saved {
+		t.Fatalf("incremental scan rechecked old transfers: %+v", store.findings)
+	}
+}
@@ -0,0 +1,995 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: merch/v1/merch.proto
+
+package merchv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AuthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthRequest) Reset() {
+	*x = AuthRequest{}
+	mi := &file_merch_v1_merch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthRequest) ProtoMessage() {}
+
+func (x *AuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthRequest.ProtoReflect.Descriptor instead.
+func (*AuthRequest) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *AuthRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type AuthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthResponse) Reset() {
+	*x = AuthResponse{}
+	mi := &file_merch_v1_merch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthResponse) ProtoMessage() {}
+
+func (x *AuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthResponse.ProtoReflect.Descriptor instead.
+func (*AuthResponse) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AuthResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type GetInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoRequest) Reset() {
+	*x = GetInfoRequest{}
+	mi := &file_merch_v1_merch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoRequest) ProtoMessage() {}
+
+func (x *GetInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetInfoRequest) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{2}
+}
+
+type InventoryItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InventoryItem) Reset() {
+	*x = InventoryItem{}
+	mi := &file_merch_v1_merch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryItem) ProtoMessage() {}
+
+func (x *InventoryItem) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryItem.ProtoReflect.Descriptor instead.
+func (*InventoryItem) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InventoryItem) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *InventoryItem) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type CoinTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromUser      string                 `protobuf:"bytes,1,opt,name=from_user,json=fromUser,proto3" json:"from_user,omitempty"`
+	ToUser        string                 `protobuf:"bytes,2,opt,name=to_user,json=toUser,proto3" json:"to_user,omitempty"`
+	Amount        int32                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoinTransaction) Reset() {
+	*x = CoinTransaction{}
+	mi := &file_merch_v1_merch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoinTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinTransaction) ProtoMessage() {}
+
+func (x *CoinTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinTransaction.ProtoReflect.Descriptor instead.
+func (*CoinTransaction) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CoinTransaction) GetFromUser() string {
+	if x != nil {
+		return x.FromUser
+	}
+	return ""
+}
+
+func (x *CoinTransaction) GetToUser() string {
+	if x != nil {
+		return x.ToUser
+	}
+	return ""
+}
+
+func (x *CoinTransaction) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type CoinHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Received      []*CoinTransaction     `protobuf:"bytes,1,rep,name=received,proto3" json:"received,omitempty"`
+	Sent          []*CoinTransaction     `protobuf:"bytes,2,rep,name=sent,proto3" json:"sent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CoinHistory) Reset() {
+	*x = CoinHistory{}
+	mi := &file_merch_v1_merch_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoinHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoinHistory) ProtoMessage() {}
+
+func (x *CoinHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoinHistory.ProtoReflect.Descriptor instead.
+func (*CoinHistory) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CoinHistory) GetReceived() []*CoinTransaction {
+	if x != nil {
+		return x.Received
+	}
+	return nil
+}
+
+func (x *CoinHistory) GetSent() []*CoinTransaction {
+	if x != nil {
+		return x.Sent
+	}
+	return nil
+}
+
+type GetInfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coins         int32                  `protobuf:"varint,1,opt,name=coins,proto3" json:"coins,omitempty"`
+	Inventory     []*InventoryItem       `protobuf:"bytes,2,rep,name=inventory,proto3" json:"inventory,omitempty"`
+	CoinHistory   *CoinHistory           `protobuf:"bytes,3,opt,name=coin_history,json=coinHistory,proto3" json:"coin_history,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInfoResponse) Reset() {
+	*x = GetInfoResponse{}
+	mi := &file_merch_v1_merch_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInfoResponse) ProtoMessage() {}
+
+func (x *GetInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetInfoResponse) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetInfoResponse) GetCoins() int32 {
+	if x != nil {
+		return x.Coins
+	}
+	return 0
+}
+
+func (x *GetInfoResponse) GetInventory() []*InventoryItem {
+	if x != nil {
+		return x.Inventory
+	}
+	return nil
+}
+
+func (x *GetInfoResponse) GetCoinHistory() *CoinHistory {
+	if x != nil {
+		return x.CoinHistory
+	}
+	return nil
+}
+
+type SendCoinsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ToUser        string                 `protobuf:"bytes,1,opt,name=to_user,json=toUser,proto3" json:"to_user,omitempty"`
+	Amount        int32                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendCoinsRequest) Reset() {
+	*x = SendCoinsRequest{}
+	mi := &file_merch_v1_merch_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendCoinsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendCoinsRequest) ProtoMessage() {}
+
+func (x *SendCoinsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendCoinsRequest.ProtoReflect.Descriptor instead.
+func (*SendCoinsRequest) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SendCoinsRequest) GetToUser() string {
+	if x != nil {
+		return x.ToUser
+	}
+	return ""
+}
+
+func (x *SendCoinsRequest) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type SendCoinsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendCoinsResponse) Reset() {
+	*x = SendCoinsResponse{}
+	mi := &file_merch_v1_merch_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendCoinsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendCoinsResponse) ProtoMessage() {}
+
+func (x *SendCoinsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendCoinsResponse.ProtoReflect.Descriptor instead.
+func (*SendCoinsResponse) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{8}
+}
+
+type PurchaseMerchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Item  string                 `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	// 0 означает 1 штуку.
+	Quantity int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// Вариант товара, как параметры size и color в /api/buy/{item}.
+	Size  string `protobuf:"bytes,3,opt,name=size,proto3" json:"size,omitempty"`
+	Color string `protobuf:"bytes,4,opt,name=color,proto3" json:"color,omitempty"`
+	// Промокод, регистр не важен.
+	PromoCode string `protobuf:"bytes,5,opt,name=promo_code,json=promoCode,proto3" json:"promo_code,omitempty"`
+	// Подарок коллеге, как /api/buy/{item}/gift: платит покупатель, товар получает recipient.
+	Recipient string `protobuf:"bytes,6,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	// Поздравление к подарку, до 200 символов.
+	Message       string `protobuf:"bytes,7,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseMerchRequest) Reset() {
+	*x = PurchaseMerchRequest{}
+	mi := &file_merch_v1_merch_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseMerchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseMerchRequest) ProtoMessage() {}
+
+func (x *PurchaseMerchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseMerchRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseMerchRequest) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PurchaseMerchRequest) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+func (x *PurchaseMerchRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *PurchaseMerchRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *PurchaseMerchRequest) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *PurchaseMerchRequest) GetPromoCode() string {
+	if x != nil {
+		return x.PromoCode
+	}
+	return ""
+}
+
+func (x *PurchaseMerchRequest) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *PurchaseMerchRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Статус заказа как в REST API: "placed" - куплено, "pending_approval" - монеты
+// зарезервированы, заказ ждет согласования руководителя.
+type PurchaseMerchResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OrderId int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status  string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	// Списано монет с учетом скидки.
+	Total         int32 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Discount      int32 `protobuf:"varint,4,opt,name=discount,proto3" json:"discount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseMerchResponse) Reset() {
+	*x = PurchaseMerchResponse{}
+	mi := &file_merch_v1_merch_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseMerchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseMerchResponse) ProtoMessage() {}
+
+func (x *PurchaseMerchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseMerchResponse.ProtoReflect.Descriptor instead.
+func (*PurchaseMerchResponse) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PurchaseMerchResponse) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *PurchaseMerchResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PurchaseMerchResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PurchaseMerchResponse) GetDiscount() int32 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+type ListMerchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchRequest) Reset() {
+	*x = ListMerchRequest{}
+	mi := &file_merch_v1_merch_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchRequest) ProtoMessage() {}
+
+func (x *ListMerchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchRequest.ProtoReflect.Descriptor instead.
+func (*ListMerchRequest) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{11}
+}
+
+type MerchVariant struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Size  string                 `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`
+	Color string                 `protobuf:"bytes,3,opt,name=color,proto3" json:"color,omitempty"`
+	// Не задан - остаток не учитывается.
+	Stock         *int32 `protobuf:"varint,4,opt,name=stock,proto3,oneof" json:"stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerchVariant) Reset() {
+	*x = MerchVariant{}
+	mi := &file_merch_v1_merch_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerchVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerchVariant) ProtoMessage() {}
+
+func (x *MerchVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerchVariant.ProtoReflect.Descriptor instead.
+func (*MerchVariant) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MerchVariant) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *MerchVariant) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *MerchVariant) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *MerchVariant) GetStock() int32 {
+	if x != nil && x.Stock != nil {
+		return *x.Stock
+	}
+	return 0
+}
+
+type MerchItem struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Price    int32                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	Category string                 `protobuf:"bytes,3,opt,name=category,proto3" json:"category,omitempty"`
+	// Варианты в продаже.
+	Variants      []*MerchVariant `protobuf:"bytes,4,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerchItem) Reset() {
+	*x = MerchItem{}
+	mi := &file_merch_v1_merch_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerchItem) ProtoMessage() {}
+
+func (x *MerchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerchItem.ProtoReflect.Descriptor instead.
+func (*MerchItem) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MerchItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MerchItem) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *MerchItem) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *MerchItem) GetVariants() []*MerchVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type ListMerchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*MerchItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMerchResponse) Reset() {
+	*x = ListMerchResponse{}
+	mi := &file_merch_v1_merch_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMerchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMerchResponse) ProtoMessage() {}
+
+func (x *ListMerchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_merch_v1_merch_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMerchResponse.ProtoReflect.Descriptor instead.
+func (*ListMerchResponse) Descriptor() ([]byte, []int) {
+	return file_merch_v1_merch_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListMerchResponse) GetItems() []*MerchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var File_merch_v1_merch_proto protoreflect.FileDescriptor
+
+const file_merch_v1_merch_proto_rawDesc = "" +
+	"\n" +
+	"\x14merch/v1/merch.proto\x12\bmerch.v1\"E\n" +
+	"\vAuthRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"$\n" +
+	"\fAuthResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x10\n" +
+	"\x0eGetInfoRequest\"?\n" +
+	"\rInventoryItem\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"_\n" +
+	"\x0fCoinTransaction\x12\x1b\n" +
+	"\tfrom_user\x18\x01 \x01(\tR\bfromUser\x12\x17\n" +
+	"\ato_user\x18\x02 \x01(\tR\x06toUser\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x05R\x06amount\"s\n" +
+	"\vCoinHistory\x125\n" +
+	"\breceived\x18\x01 \x03(\v2\x19.merch.v1.CoinTransactionR\breceived\x12-\n" +
+	"\x04sent\x18\x02 \x03(\v2\x19.merch.v1.CoinTransactionR\x04sent\"\x98\x01\n" +
+	"\x0fGetInfoResponse\x12\x14\n" +
+	"\x05coins\x18\x01 \x01(\x05R\x05coins\x125\n" +
+	"\tinventory\x18\x02 \x03(\v2\x17.merch.v1.InventoryItemR\tinventory\x128\n" +
+	"\fcoin_history\x18\x03 \x01(\v2\x15.merch.v1.CoinHistoryR\vcoinHistory\"C\n" +
+	"\x10SendCoinsRequest\x12\x17\n" +
+	"\ato_user\x18\x01 \x01(\tR\x06toUser\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x05R\x06amount\"\x13\n" +
+	"\x11SendCoinsResponse\"\xc7\x01\n" +
+	"\x14PurchaseMerchRequest\x12\x12\n" +
+	"\x04item\x18\x01 \x01(\tR\x04item\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\tR\x04size\x12\x14\n" +
+	"\x05color\x18\x04 \x01(\tR\x05color\x12\x1d\n" +
+	"\n" +
+	"promo_code\x18\x05 \x01(\tR\tpromoCode\x12\x1c\n" +
+	"\trecipient\x18\x06 \x01(\tR\trecipient\x12\x18\n" +
+	"\amessage\x18\a \x01(\tR\amessage\"|\n" +
+	"\x15PurchaseMerchResponse\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x1a\n" +
+	"\bdiscount\x18\x04 \x01(\x05R\bdiscount\"\x12\n" +
+	"\x10ListMerchRequest\"m\n" +
+	"\fMerchVariant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\tR\x04size\x12\x14\n" +
+	"\x05color\x18\x03 \x01(\tR\x05color\x12\x19\n" +
+	"\x05stock\x18\x04 \x01(\x05H\x00R\x05stock\x88\x01\x01B\b\n" +
+	"\x06_stock\"\x85\x01\n" +
+	"\tMerchItem\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x05R\x05price\x12\x1a\n" +
+	"\bcategory\x18\x03 \x01(\tR\bcategory\x122\n" +
+	"\bvariants\x18\x04 \x03(\v2\x16.merch.v1.MerchVariantR\bvariants\">\n" +
+	"\x11ListMerchResponse\x12)\n" +
+	"\x05items\x18\x01 \x03(\v2\x13.merch.v1.MerchItemR\x05items2\xe0\x02\n" +
+	"\tMerchShop\x125\n" +
+	"\x04Auth\x12\x15.merch.v1.AuthRequest\x1a\x16.merch.v1.AuthResponse\x12>\n" +
+	"\aGetInfo\x12\x18.merch.v1.GetInfoRequest\x1a\x19.merch.v1.GetInfoResponse\x12D\n" +
+	"\tSendCoins\x12\x1a.merch.v1.SendCoinsRequest\x1a\x1b.merch.v1.SendCoinsResponse\x12P\n" +
+	"\rPurchaseMerch\x12\x1e.merch.v1.PurchaseMerchRequest\x1a\x1f.merch.v1.PurchaseMerchResponse\x12D\n" +
+	"\tListMerch\x12\x1a.merch.v1.ListMerchRequest\x1a\x1b.merch.v1.ListMerchResponseBKZIgithub.com/magneless/merch-shop/internal/grpc-server/gen/merch/v1;merchv1b\x06proto3"
+
+var (
+	file_merch_v1_merch_proto_rawDescOnce sync.Once
+	file_merch_v1_merch_proto_rawDescData []byte
+)
+
+func file_merch_v1_merch_proto_rawDescGZIP() []byte {
+	file_merch_v1_merch_proto_rawDescOnce.Do(func() {
+		file_merch_v1_merch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_merch_v1_merch_proto_rawDesc), len(file_merch_v1_merch_proto_rawDesc)))
+	})
+	return file_merch_v1_merch_proto_rawDescData
+}
+
+var file_merch_v1_merch_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_merch_v1_merch_proto_goTypes = []any{
+	(*AuthRequest)(nil),           // 0: merch.v1.AuthRequest
+	(*AuthResponse)(nil),          // 1: merch.v1.AuthResponse
+	(*GetInfoRequest)(nil),        // 2: merch.v1.GetInfoRequest
+	(*InventoryItem)(nil),         // 3: merch.v1.InventoryItem
+	(*CoinTransaction)(nil),       // 4: merch.v1.CoinTransaction
+	(*CoinHistory)(nil),           // 5: merch.v1.CoinHistory
+	(*GetInfoResponse)(nil),       // 6: merch.v1.GetInfoResponse
+	(*SendCoinsRequest)(nil),      // 7: merch.v1.SendCoinsRequest
+	(*SendCoinsResponse)(nil),     // 8: merch.v1.SendCoinsResponse
+	(*PurchaseMerchRequest)(nil),  // 9: merch.v1.PurchaseMerchRequest
+	(*PurchaseMerchResponse)(nil), // 10: merch.v1.PurchaseMerchResponse
+	(*ListMerchRequest)(nil),      // 11: merch.v1.ListMerchRequest
+	(*MerchVariant)(nil),          // 12: merch.v1.MerchVariant
+	(*MerchItem)(nil),             // 13: merch.v1.MerchItem
+	(*ListMerchResponse)(nil),     // 14: merch.v1.ListMerchResponse
+}
+var file_merch_v1_merch_proto_depIdxs = []int32{
+	4,  // 0: merch.v1.CoinHistory.received:type_name -> merch.v1.CoinTransaction
+	4,  // 1: merch.v1.CoinHistory.sent:type_name -> merch.v1.CoinTransaction
+	3,  // 2: merch.v1.GetInfoResponse.inventory:type_name -> merch.v1.InventoryItem
+	5,  // 3: merch.v1.GetInfoResponse.coin_history:type_name -> merch.v1.CoinHistory
+	12, // 4: merch.v1.MerchItem.variants:type_name -> merch.v1.MerchVariant
+	13, // 5: merch.v1.ListMerchResponse.items:type_name -> merch.v1.MerchItem
+	0,  // 6: merch.v1.MerchShop.Auth:input_type -> merch.v1.AuthRequest
+	2,  // 7: merch.v1.MerchShop.GetInfo:input_type -> merch.v1.GetInfoRequest
+	7,  // 8: merch.v1.MerchShop.SendCoins:input_type -> merch.v1.SendCoinsRequest
+	9,  // 9: merch.v1.MerchShop.PurchaseMerch:input_type -> merch.v1.PurchaseMerchRequest
+	11, // 10: merch.v1.MerchShop.ListMerch:input_type -> merch.v1.ListMerchRequest
+	1,  // 11: merch.v1.MerchShop.Auth:output_type -> merch.v1.AuthResponse
+	6,  // 12: merch.v1.MerchShop.GetInfo:output_type -> merch.v1.GetInfoResponse
+	8,  // 13: merch.v1.MerchShop.SendCoins:output_type -> merch.v1.SendCoinsResponse
+	10, // 14: merch.v1.MerchShop.PurchaseMerch:output_type -> merch.v1.PurchaseMerchResponse
+	14, // 15: merch.v1.MerchShop.ListMerch:output_type -> merch.v1.ListMerchResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_merch_v1_merch_proto_init() }
+func file_merch_v1_merch_proto_init() {
+	if File_merch_v1_merch_proto != nil {
+		return
+	}
+	file_merch_v1_merch_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_merch_v1_merch_proto_rawDesc), len(file_merch_v1_merch_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_merch_v1_merch_proto_goTypes,
+		DependencyIndexes: file_merch_v1_merch_proto_depIdxs,
+		MessageInfos:      file_merch_v1_merch_proto_msgTypes,
+	}.Build()
+	File_merch_v1_merch_proto = out.File
+	file_merch_v1_merch_proto_goTypes = nil
+	file_merch_v1_merch_proto_depIdxs = nil
+}
@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: merch/v1/merch.proto
+
+package merchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MerchShop_Auth_FullMethodName          = "/merch.v1.MerchShop/Auth"
+	MerchShop_GetInfo_FullMethodName       = "/merch.v1.MerchShop/GetInfo"
+	MerchShop_SendCoins_FullMethodName     = "/merch.v1.MerchShop/SendCoins"
+	MerchShop_PurchaseMerch_FullMethodName = "/merch.v1.MerchShop/PurchaseMerch"
+	MerchShop_ListMerch_FullMethodName     = "/merch.v1.MerchShop/ListMerch"
+)
+
+// MerchShopClient is the client API for MerchShop service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MerchShop повторяет REST API магазина.
+// Все методы, кроме Auth, требуют метаданные "authorization: Bearer <token>".
+type MerchShopClient interface {
+	// Вход или регистрация, как /api/auth.
+	Auth(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	// Баланс, инвентарь и история переводов, как /api/info.
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	// Перевод монет, как /api/sendCoin.
+	SendCoins(ctx context.Context, in *SendCoinsRequest, opts ...grpc.CallOption) (*SendCoinsResponse, error)
+	// Покупка мерча, как /api/buy/{item}.
+	PurchaseMerch(ctx context.Context, in *PurchaseMerchRequest, opts ...grpc.CallOption) (*PurchaseMerchResponse, error)
+	// Каталог, как /api/merch.
+	ListMerch(ctx context.Context, in *ListMerchRequest, opts ...grpc.CallOption) (*ListMerchResponse, error)
+}
+
+type merchShopClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMerchShopClient(cc grpc.ClientConnInterface) MerchShopClient {
+	return &merchShopClient{cc}
+}
+
+func (c *merchShopClient) Auth(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, MerchShop_Auth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchShopClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, MerchShop_GetInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchShopClient) SendCoins(ctx context.Context, in *SendCoinsRequest, opts ...grpc.CallOption) (*SendCoinsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendCoinsResponse)
+	err := c.cc.Invoke(ctx, MerchShop_SendCoins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchShopClient) PurchaseMerch(ctx context.Context, in *PurchaseMerchRequest, opts ...grpc.CallOption) (*PurchaseMerchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchaseMerchResponse)
+	err := c.cc.Invoke(ctx, MerchShop_PurchaseMerch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *merchShopClient) ListMerch(ctx context.Context, in *ListMerchRequest, opts ...grpc.CallOption) (*ListMerchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMerchResponse)
+	err := c.cc.Invoke(ctx, MerchShop_ListMerch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MerchShopServer is the server API for MerchShop service.
+// All implementations must embed UnimplementedMerchShopServer
+// for forward compatibility.
+//
+// MerchShop повторяет REST API магазина.
+// Все методы, кроме Auth, требуют метаданные "authorization: Bearer <token>".
+type MerchShopServer interface {
+	// Вход или регистрация, как /api/auth.
+	Auth(context.Context, *AuthRequest) (*AuthResponse, error)
+	// Баланс, инвентарь и история переводов, как /api/info.
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	// Перевод монет, как /api/sendCoin.
+	SendCoins(context.Context, *SendCoinsRequest) (*SendCoinsResponse, error)
+	// Покупка мерча, как /api/buy/{item}.
+	PurchaseMerch(context.Context, *PurchaseMerchRequest) (*PurchaseMerchResponse, error)
+	// Каталог, как /api/merch.
+	ListMerch(context.Context, *ListMerchRequest) (*ListMerchResponse, error)
+	mustEmbedUnimplementedMerchShopServer()
+}
+
+// UnimplementedMerchShopServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMerchShopServer struct{}
+
+func (UnimplementedMerchShopServer) Auth(context.Context, *AuthRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Auth not implemented")
+}
+func (UnimplementedMerchShopServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedMerchShopServer) SendCoins(context.Context, *SendCoinsRequest) (*SendCoinsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendCoins not implemented")
+}
+func (UnimplementedMerchShopServer) PurchaseMerch(context.Context, *PurchaseMerchRequest) (*PurchaseMerchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseMerch not implemented")
+}
+func (UnimplementedMerchShopServer) ListMerch(context.Context, *ListMerchRequest) (*ListMerchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMerch not implemented")
+}
+func (UnimplementedMerchShopServer) mustEmbedUnimplementedMerchShopServer() {}
+func (UnimplementedMerchShopServer) testEmbeddedByValue()                   {}
+
+// UnsafeMerchShopServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MerchShopServer will
+// result in compilation errors.
+type UnsafeMerchShopServer interface {
+	mustEmbedUnimplementedMerchShopServer()
+}
+
+func RegisterMerchShopServer(s grpc.ServiceRegistrar, srv MerchShopServer) {
+	// If the following call pancis, it indicates UnimplementedMerchShopServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MerchShop_ServiceDesc, srv)
+}
+
+func _MerchShop_Auth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchShopServer).Auth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchShop_Auth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchShopServer).Auth(ctx, req.(*AuthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchShop_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchShopServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchShop_GetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchShopServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchShop_SendCoins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendCoinsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchShopServer).SendCoins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchShop_SendCoins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchShopServer).SendCoins(ctx, req.(*SendCoinsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchShop_PurchaseMerch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseMerchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchShopServer).PurchaseMerch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchShop_PurchaseMerch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchShopServer).PurchaseMerch(ctx, req.(*PurchaseMerchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MerchShop_ListMerch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMerchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MerchShopServer).ListMerch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MerchShop_ListMerch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MerchShopServer).ListMerch(ctx, req.(*ListMerchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MerchShop_ServiceDesc is the grpc.ServiceDesc for MerchShop service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MerchShop_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "merch.v1.MerchShop",
+	HandlerType: (*MerchShopServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Auth",
+			Handler:    _MerchShop_Auth_Handler,
+		},
+		{
+			MethodName: "GetInfo",
+			Handler:    _MerchShop_GetInfo_Handler,
+		},
+		{
+			MethodName: "SendCoins",
+			Handler:    _MerchShop_SendCoins_Handler,
+		},
+		{
+			MethodName: "PurchaseMerch",
+			Handler:    _MerchShop_PurchaseMerch_Handler,
+		},
+		{
+			MethodName: "ListMerch",
+			Handler:    _MerchShop_ListMerch_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "merch/v1/merch.proto",
+}
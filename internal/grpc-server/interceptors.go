@@ -0,0 +1,140 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const requestIDHeader = "x-request-id"
+
+type ctxKey int
+
+const (
+	usernameKey ctxKey = iota
+	requestIDKey
+)
+
+func usernameFromContext(ctx context.Context) (string, bool) {
+	username, ok := ctx.Value(usernameKey).(string)
+	return username, ok
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// requestIDInterceptor берет x-request-id из метаданных или генерирует новый
+// и возвращает его клиенту в заголовке ответа.
+func requestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var id string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(requestIDHeader); len(v) > 0 {
+				id = v[0]
+			}
+		}
+		if id == "" {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+		return handler(context.WithValue(ctx, requestIDKey, id), req)
+	}
+}
+
+func loggingInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		t1 := time.Now()
+		resp, err := handler(ctx, req)
+
+		log.Info("request completed",
+			slog.String("method", info.FullMethod),
+			slog.String("code", status.Code(err).String()),
+			slog.String("request_id", requestIDFromContext(ctx)),
+			slog.String("duration", time.Since(t1).String()),
+		)
+
+		return resp, err
+	}
+}
+
+func recoveryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Error("panic in grpc handler",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// auditInterceptor кладет в контекст данные запроса для журнала аудита, как mwAudit.
+func auditInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		meta := audit.Meta{RequestID: requestIDFromContext(ctx)}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			meta.ClientIP = p.Addr.String()
+			if host, _, err := net.SplitHostPort(meta.ClientIP); err == nil {
+				meta.ClientIP = host
+			}
+		}
+
+		return handler(audit.WithMeta(ctx, meta), req)
+	}
+}
+
+// authInterceptor проверяет тот же JWT, что и mwAuth. Методы из public пропускаются.
+func authInterceptor(log *slog.Logger, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		for _, method := range public {
+			if info.FullMethod == method {
+				return handler(ctx, req)
+			}
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is missed")
+		}
+
+		parts := strings.Split(values[0], " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+
+		username, err := jwt_token.ValidateAccessToken(parts[1])
+		if err != nil {
+			log.Error("error in token validation", sl.Err(err))
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired access token")
+		}
+
+		return handler(context.WithValue(ctx, usernameKey, username), req)
+	}
+}
@@ -0,0 +1,42 @@
+package grpcserver
+
+//go:generate protoc -I ../../api/proto --go_out=gen --go_opt=paths=source_relative --go-grpc_out=gen --go-grpc_opt=paths=source_relative merch/v1/merch.proto
+
+import (
+	"context"
+	"log/slog"
+
+	merchv1 "github.com/magneless/merch-shop/internal/grpc-server/gen/merch/v1"
+	"github.com/magneless/merch-shop/internal/models"
+	"google.golang.org/grpc"
+)
+
+type Repository interface {
+	GetUser(ctx context.Context, username, passwordHash string, canRegister bool) error
+	GetInventory(userID int) ([]models.InventoryItem, error)
+	GetReceivedTransactions(userID int, toUsername string) ([]models.CoinTransaction, error)
+	GetSentTransactions(userID int, fromUsername string) ([]models.CoinTransaction, error)
+	GetBalanceAndId(username string) (int, int, error)
+	SendCoins(ctx context.Context, senderUsername, receiverUsername string, amount int) error
+	PurchaseMerch(ctx context.Context, username string, p models.Purchase) (models.Order, error)
+	ListMerch(ctx context.Context) ([]models.MerchItem, error)
+}
+
+// New собирает gRPC-сервер поверх того же репозитория, что и REST API.
+func New(log *slog.Logger, repo Repository) *grpc.Server {
+	log = log.With(slog.String("component", "grpc-server"))
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			requestIDInterceptor(),
+			loggingInterceptor(log),
+			recoveryInterceptor(log),
+			auditInterceptor(),
+			authInterceptor(log, merchv1.MerchShop_Auth_FullMethodName),
+		),
+	)
+
+	merchv1.RegisterMerchShopServer(srv, &service{log: log, repo: repo})
+
+	return srv
+}
@@ -0,0 +1,298 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	merchv1 "github.com/magneless/merch-shop/internal/grpc-server/gen/merch/v1"
+	"github.com/magneless/merch-shop/internal/lib/hashing"
+	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxGiftMessageLength - как validate:"max=200" у buy.GiftRequest.
+const maxGiftMessageLength = 200
+
+type service struct {
+	merchv1.UnimplementedMerchShopServer
+
+	log  *slog.Logger
+	repo Repository
+}
+
+func (s *service) Auth(ctx context.Context, req *merchv1.AuthRequest) (*merchv1.AuthResponse, error) {
+	const op = "grpc.Auth"
+	log := s.log.With(slog.String("op", op), slog.String("request_id", requestIDFromContext(ctx)))
+
+	if req.GetUsername() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "username and password are required")
+	}
+	// правила проверяются только для нового аккаунта, старые сотрудники входят как раньше
+	var policyErr error
+	switch {
+	case !validate.Username(req.GetUsername()):
+		policyErr = status.Error(codes.InvalidArgument, "username is not valid")
+	case !validate.Password(req.GetPassword()):
+		policyErr = status.Error(codes.InvalidArgument, "password does not meet the password policy")
+	}
+
+	passwordHash, err := hashing.HashPassword(req.GetPassword())
+	if err != nil {
+		log.Error("failed to hash password", sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	err = s.repo.GetUser(ctx, req.GetUsername(), passwordHash, policyErr == nil)
+	if errors.Is(err, storage.ErrRegistrationRejected) {
+		return nil, policyErr
+	}
+	if err != nil {
+		log.Error("failed to auth user", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	token, err := jwt_token.GenerateAccessToken(req.GetUsername())
+	if err != nil {
+		log.Error("failed to generate access token", sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &merchv1.AuthResponse{Token: token}, nil
+}
+
+func (s *service) GetInfo(ctx context.Context, _ *merchv1.GetInfoRequest) (*merchv1.GetInfoResponse, error) {
+	const op = "grpc.GetInfo"
+	log := s.log.With(slog.String("op", op), slog.String("request_id", requestIDFromContext(ctx)))
+
+	username, ok := usernameFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	userID, balance, err := s.repo.GetBalanceAndId(username)
+	if err != nil {
+		log.Error("failed to get balance or id from bd", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	sent, err := s.repo.GetSentTransactions(userID, username)
+	if err != nil {
+		log.Error("failed to get sent transactions from bd", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	received, err := s.repo.GetReceivedTransactions(userID, username)
+	if err != nil {
+		log.Error("failed to get received transactions from bd", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	inventory, err := s.repo.GetInventory(userID)
+	if err != nil {
+		log.Error("failed to get inventory from bd", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	resp := &merchv1.GetInfoResponse{
+		Coins: int32(balance),
+		CoinHistory: &merchv1.CoinHistory{
+			Sent:     transactionsToProto(sent),
+			Received: transactionsToProto(received),
+		},
+	}
+	for _, item := range inventory {
+		resp.Inventory = append(resp.Inventory, &merchv1.InventoryItem{
+			Type:     item.Type,
+			Quantity: int32(item.Quantity),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *service) SendCoins(ctx context.Context, req *merchv1.SendCoinsRequest) (*merchv1.SendCoinsResponse, error) {
+	const op = "grpc.SendCoins"
+	log := s.log.With(slog.String("op", op), slog.String("request_id", requestIDFromContext(ctx)))
+
+	username, ok := usernameFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	if !validate.Username(req.GetToUser()) {
+		return nil, status.Error(codes.InvalidArgument, "toUser is not valid")
+	}
+	if req.GetToUser() == username {
+		return nil, status.Error(codes.InvalidArgument, "toUser must differ from sender")
+	}
+	if !validate.Amount(int(req.GetAmount())) {
+		return nil, status.Error(codes.InvalidArgument, "amount is out of range")
+	}
+
+	if err := s.repo.SendCoins(ctx, username, req.GetToUser(), int(req.GetAmount())); err != nil {
+		log.Error("failed to send coins", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	return &merchv1.SendCoinsResponse{}, nil
+}
+
+func (s *service) PurchaseMerch(ctx context.Context, req *merchv1.PurchaseMerchRequest) (*merchv1.PurchaseMerchResponse, error) {
+	const op = "grpc.PurchaseMerch"
+	log := s.log.With(slog.String("op", op), slog.String("request_id", requestIDFromContext(ctx)))
+
+	username, ok := usernameFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	quantity := int(req.GetQuantity())
+	if quantity == 0 {
+		quantity = 1
+	}
+	if req.GetItem() == "" || quantity < 0 {
+		return nil, status.Error(codes.InvalidArgument, "item and non-negative quantity are required")
+	}
+	recipient, message := req.GetRecipient(), strings.TrimSpace(req.GetMessage())
+	if recipient != "" {
+		if !validate.Username(recipient) {
+			return nil, status.Error(codes.InvalidArgument, "recipient is not valid")
+		}
+		if recipient == username {
+			return nil, status.Error(codes.InvalidArgument, "recipient must differ from buyer")
+		}
+	}
+	if message != "" && recipient == "" {
+		return nil, status.Error(codes.InvalidArgument, "message requires recipient")
+	}
+	if utf8.RuneCountInString(message) > maxGiftMessageLength {
+		return nil, status.Error(codes.InvalidArgument, "message is too long")
+	}
+
+	order, err := s.repo.PurchaseMerch(ctx, username, models.Purchase{
+		Item: req.GetItem(),
+		Variant: models.VariantChoice{
+			Size:  req.GetSize(),
+			Color: req.GetColor(),
+		},
+		Quantity:  quantity,
+		PromoCode: strings.ToUpper(strings.TrimSpace(req.GetPromoCode())),
+		Recipient: recipient,
+		Message:   message,
+	})
+	if err != nil {
+		log.Error("failed to purchase merch", sl.Err(err))
+		return nil, toStatus(err)
+	}
+	if order.Status == models.OrderPendingApproval {
+		log.Info("purchase awaits approval", slog.Int64("order_id", order.ID))
+	}
+
+	return &merchv1.PurchaseMerchResponse{
+		OrderId:  order.ID,
+		Status:   order.Status,
+		Total:    int32(order.Total),
+		Discount: int32(order.Discount),
+	}, nil
+}
+
+func (s *service) ListMerch(ctx context.Context, _ *merchv1.ListMerchRequest) (*merchv1.ListMerchResponse, error) {
+	const op = "grpc.ListMerch"
+	log := s.log.With(slog.String("op", op), slog.String("request_id", requestIDFromContext(ctx)))
+
+	items, err := s.repo.ListMerch(ctx)
+	if err != nil {
+		log.Error("failed to list merch", sl.Err(err))
+		return nil, toStatus(err)
+	}
+
+	resp := &merchv1.ListMerchResponse{}
+	for _, item := range items {
+		pb := &merchv1.MerchItem{Name: item.Name, Price: int32(item.Price), Category: item.Category}
+		for _, v := range item.Variants {
+			variant := &merchv1.MerchVariant{Id: int64(v.ID), Size: v.Size, Color: v.Color}
+			if v.Stock != nil {
+				stock := int32(*v.Stock)
+				variant.Stock = &stock
+			}
+			pb.Variants = append(pb.Variants, variant)
+		}
+		resp.Items = append(resp.Items, pb)
+	}
+
+	return resp, nil
+}
+
+// toStatus переводит ошибки хранилища в коды gRPC.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrWrongPassword):
+		return status.Error(codes.Unauthenticated, "wrong password or login")
+	case errors.Is(err, storage.ErrRegistrationRejected):
+		return status.Error(codes.InvalidArgument, "username or password does not meet the policy for new accounts")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, storage.ErrMerchNotFound):
+		return status.Error(codes.NotFound, "merch not found")
+	case errors.Is(err, storage.ErrInsufficientBalance):
+		return status.Error(codes.FailedPrecondition, "insufficient balance")
+	case errors.Is(err, storage.ErrLimitExceeded):
+		var limitErr *storage.LimitError
+		if errors.As(err, &limitErr) {
+			return status.Error(codes.ResourceExhausted, limitErr.Error())
+		}
+		return status.Error(codes.ResourceExhausted, "transfer limit exceeded")
+	case errors.Is(err, storage.ErrVariantNotFound):
+		return status.Error(codes.NotFound, "merch variant not found")
+	case errors.Is(err, storage.ErrVariantRequired):
+		return status.Error(codes.FailedPrecondition, "merch has several variants, choose size and color")
+	case errors.Is(err, storage.ErrPromoNotFound):
+		return status.Error(codes.NotFound, "promotion not found")
+	case errors.Is(err, storage.ErrPromoNotApplicable):
+		return status.Error(codes.FailedPrecondition, "promotion does not apply to this purchase")
+	case errors.Is(err, storage.ErrPromoExhausted):
+		return status.Error(codes.ResourceExhausted, "promotion usage limit reached")
+	case errors.Is(err, storage.ErrPurchaseLimit):
+		var purchaseErr *storage.PurchaseLimitError
+		if errors.As(err, &purchaseErr) {
+			return status.Error(codes.ResourceExhausted, purchaseErr.Error())
+		}
+		return status.Error(codes.ResourceExhausted, "merch purchase limit exceeded")
+	case errors.Is(err, storage.ErrNotReleased):
+		var releaseErr *storage.NotReleasedError
+		if errors.As(err, &releaseErr) {
+			return status.Error(codes.FailedPrecondition, releaseErr.Error())
+		}
+		return status.Error(codes.FailedPrecondition, "merch is not released yet")
+	case errors.Is(err, storage.ErrOutOfStock):
+		return status.Error(codes.FailedPrecondition, "merch is out of stock")
+	case errors.Is(err, storage.ErrTransfersHeld):
+		return status.Error(codes.PermissionDenied, "transfers are held for review")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func transactionsToProto(transactions []models.CoinTransaction) []*merchv1.CoinTransaction {
+	result := make([]*merchv1.CoinTransaction, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, &merchv1.CoinTransaction{
+			FromUser: t.FromUser,
+			ToUser:   t.ToUser,
+			Amount:   int32(t.Amount),
+		})
+	}
+	return result
+}
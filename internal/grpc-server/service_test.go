@@ -0,0 +1,347 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	merchv1 "github.com/magneless/merch-shop/internal/grpc-server/gen/merch/v1"
+	"github.com/magneless/merch-shop/internal/lib/hashing"
+	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeRepo struct {
+	err error
+
+	users    map[string]string
+	purchase models.Purchase
+	order    models.Order
+	sent     struct {
+		from, to string
+		amount   int
+	}
+}
+
+func (r *fakeRepo) GetUser(_ context.Context, username, passwordHash string, canRegister bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	hash, ok := r.users[username]
+	switch {
+	case ok && hash != passwordHash:
+		return storage.ErrWrongPassword
+	case !ok && !canRegister:
+		return storage.ErrRegistrationRejected
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetInventory(int) ([]models.InventoryItem, error) {
+	return []models.InventoryItem{{Type: "cup", Quantity: 2}}, r.err
+}
+
+func (r *fakeRepo) GetReceivedTransactions(int, string) ([]models.CoinTransaction, error) {
+	return []models.CoinTransaction{{FromUser: "bob", ToUser: "anna", Amount: 10}}, r.err
+}
+
+func (r *fakeRepo) GetSentTransactions(int, string) ([]models.CoinTransaction, error) {
+	return nil, r.err
+}
+
+func (r *fakeRepo) GetBalanceAndId(string) (int, int, error) {
+	return 1, 990, r.err
+}
+
+func (r *fakeRepo) SendCoins(_ context.Context, from, to string, amount int) error {
+	r.sent.from, r.sent.to, r.sent.amount = from, to, amount
+	return r.err
+}
+
+func (r *fakeRepo) PurchaseMerch(_ context.Context, _ string, p models.Purchase) (models.Order, error) {
+	r.purchase = p
+	return r.order, r.err
+}
+
+func (r *fakeRepo) ListMerch(context.Context) ([]models.MerchItem, error) {
+	stock := 3
+	return []models.MerchItem{{
+		Name:     "hoody",
+		Price:    300,
+		Category: "hoodies",
+		Variants: []models.MerchVariant{
+			{ID: 1, Size: "M", Color: "black", Stock: &stock, Active: true},
+			{ID: 2, Size: "L", Color: "black", Active: true},
+		},
+	}}, r.err
+}
+
+func newClient(t *testing.T, repo Repository) merchv1.MerchShopClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := New(slog.New(slog.NewTextHandler(io.Discard, nil)), repo)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial bufnet: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return merchv1.NewMerchShopClient(conn)
+}
+
+func authed(t *testing.T, username string) context.Context {
+	t.Helper()
+
+	token, err := jwt_token.GenerateAccessToken(username)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+
+	if got := status.Code(err); got != want {
+		t.Fatalf("code = %s, want %s (err: %v)", got, want, err)
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client := newClient(t, &fakeRepo{})
+
+	tests := []struct {
+		name string
+		md   []string
+	}{
+		{name: "missing token"},
+		{name: "not bearer", md: []string{"authorization", "Basic YW5uYTpwYXNz"}},
+		{name: "no token", md: []string{"authorization", "Bearer"}},
+		{name: "invalid token", md: []string{"authorization", "Bearer not-a-jwt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.AppendToOutgoingContext(ctx, tt.md...)
+			}
+
+			_, err := client.GetInfo(ctx, &merchv1.GetInfoRequest{})
+			wantCode(t, err, codes.Unauthenticated)
+		})
+	}
+
+	// Auth - публичный метод, токен ему не нужен
+	if _, err := client.Auth(context.Background(), &merchv1.AuthRequest{Username: "anna", Password: "secret123"}); err != nil {
+		t.Fatalf("Auth without token: %v", err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	hash, err := hashing.HashPassword("secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyHash, err := hashing.HashPassword("weak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(t, &fakeRepo{users: map[string]string{"anna": hash, "Bo Li": legacyHash}})
+
+	resp, err := client.Auth(context.Background(), &merchv1.AuthRequest{Username: "anna", Password: "secret123"})
+	if err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	username, err := jwt_token.ValidateAccessToken(resp.GetToken())
+	if err != nil || username != "anna" {
+		t.Fatalf("token username = %q, err = %v", username, err)
+	}
+
+	_, err = client.Auth(context.Background(), &merchv1.AuthRequest{Username: "anna", Password: "wrong1234"})
+	wantCode(t, err, codes.Unauthenticated)
+
+	// сотрудник, созданный до правил, входит со старым логином и паролем
+	if _, err := client.Auth(context.Background(), &merchv1.AuthRequest{Username: "Bo Li", Password: "weak"}); err != nil {
+		t.Fatalf("legacy Auth: %v", err)
+	}
+
+	_, err = client.Auth(context.Background(), &merchv1.AuthRequest{Username: "a", Password: "secret123"})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = client.Auth(context.Background(), &merchv1.AuthRequest{Username: "newbie", Password: "short"})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = client.Auth(context.Background(), &merchv1.AuthRequest{Username: "anna"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGetInfo(t *testing.T) {
+	client := newClient(t, &fakeRepo{})
+
+	resp, err := client.GetInfo(authed(t, "anna"), &merchv1.GetInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	if resp.GetCoins() != 990 {
+		t.Errorf("coins = %d, want 990", resp.GetCoins())
+	}
+	if inv := resp.GetInventory(); len(inv) != 1 || inv[0].GetType() != "cup" || inv[0].GetQuantity() != 2 {
+		t.Errorf("inventory = %v", inv)
+	}
+	received := resp.GetCoinHistory().GetReceived()
+	if len(received) != 1 || received[0].GetFromUser() != "bob" || received[0].GetAmount() != 10 {
+		t.Errorf("received = %v", received)
+	}
+	if sent := resp.GetCoinHistory().GetSent(); len(sent) != 0 {
+		t.Errorf("sent = %v, want empty", sent)
+	}
+}
+
+func TestSendCoins(t *testing.T) {
+	repo := &fakeRepo{}
+	client := newClient(t, repo)
+	ctx := authed(t, "anna")
+
+	if _, err := client.SendCoins(ctx, &merchv1.SendCoinsRequest{ToUser: "bob", Amount: 50}); err != nil {
+		t.Fatalf("SendCoins: %v", err)
+	}
+	if repo.sent.from != "anna" || repo.sent.to != "bob" || repo.sent.amount != 50 {
+		t.Errorf("repo got %+v", repo.sent)
+	}
+
+	_, err := client.SendCoins(ctx, &merchv1.SendCoinsRequest{ToUser: "anna", Amount: 50})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = client.SendCoins(ctx, &merchv1.SendCoinsRequest{ToUser: "bob", Amount: 0})
+	wantCode(t, err, codes.InvalidArgument)
+
+	repo.err = fmt.Errorf("repository.SendCoins: %w", storage.ErrInsufficientBalance)
+	_, err = client.SendCoins(ctx, &merchv1.SendCoinsRequest{ToUser: "bob", Amount: 50})
+	wantCode(t, err, codes.FailedPrecondition)
+}
+
+func TestPurchaseMerch(t *testing.T) {
+	repo := &fakeRepo{order: models.Order{ID: 42, Status: models.OrderPendingApproval, Total: 270, Discount: 30}}
+	client := newClient(t, repo)
+	ctx := authed(t, "anna")
+
+	resp, err := client.PurchaseMerch(ctx, &merchv1.PurchaseMerchRequest{
+		Item:      "hoody",
+		Size:      "M",
+		Color:     "black",
+		PromoCode: " welcome10 ",
+		Recipient: "bob",
+		Message:   " С днем рождения! ",
+	})
+	if err != nil {
+		t.Fatalf("PurchaseMerch: %v", err)
+	}
+	if resp.GetOrderId() != 42 || resp.GetStatus() != models.OrderPendingApproval || resp.GetTotal() != 270 || resp.GetDiscount() != 30 {
+		t.Errorf("response = %v", resp)
+	}
+
+	want := models.Purchase{
+		Item:      "hoody",
+		Variant:   models.VariantChoice{Size: "M", Color: "black"},
+		Quantity:  1,
+		PromoCode: "WELCOME10",
+		Recipient: "bob",
+		Message:   "С днем рождения!",
+	}
+	if repo.purchase != want {
+		t.Errorf("repo got %+v, want %+v", repo.purchase, want)
+	}
+
+	invalid := []*merchv1.PurchaseMerchRequest{
+		{},
+		{Item: "cup", Quantity: -1},
+		{Item: "cup", Recipient: "anna"},
+		{Item: "cup", Message: "no recipient"},
+	}
+	for _, req := range invalid {
+		_, err := client.PurchaseMerch(ctx, req)
+		wantCode(t, err, codes.InvalidArgument)
+	}
+}
+
+func TestListMerch(t *testing.T) {
+	client := newClient(t, &fakeRepo{})
+
+	resp, err := client.ListMerch(authed(t, "anna"), &merchv1.ListMerchRequest{})
+	if err != nil {
+		t.Fatalf("ListMerch: %v", err)
+	}
+
+	items := resp.GetItems()
+	if len(items) != 1 || items[0].GetName() != "hoody" || items[0].GetPrice() != 300 || items[0].GetCategory() != "hoodies" {
+		t.Fatalf("items = %v", items)
+	}
+	variants := items[0].GetVariants()
+	if len(variants) != 2 {
+		t.Fatalf("variants = %v", variants)
+	}
+	if variants[0].Stock == nil || variants[0].GetStock() != 3 {
+		t.Errorf("variant M stock = %v, want 3", variants[0].Stock)
+	}
+	if variants[1].Stock != nil {
+		t.Errorf("variant L stock = %v, want unset", variants[1].GetStock())
+	}
+}
+
+func TestToStatus(t *testing.T) {
+	resetsAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{storage.ErrWrongPassword, codes.Unauthenticated},
+		{storage.ErrUserNotFound, codes.NotFound},
+		{storage.ErrMerchNotFound, codes.NotFound},
+		{storage.ErrInsufficientBalance, codes.FailedPrecondition},
+		{storage.ErrLimitExceeded, codes.ResourceExhausted},
+		{&storage.LimitError{Limit: models.LimitDaily, Max: 100, ResetsAt: &resetsAt}, codes.ResourceExhausted},
+		{storage.ErrVariantNotFound, codes.NotFound},
+		{storage.ErrVariantRequired, codes.FailedPrecondition},
+		{storage.ErrPromoNotFound, codes.NotFound},
+		{storage.ErrPromoNotApplicable, codes.FailedPrecondition},
+		{storage.ErrPromoExhausted, codes.ResourceExhausted},
+		{storage.ErrPurchaseLimit, codes.ResourceExhausted},
+		{&storage.PurchaseLimitError{Period: models.PeriodDay, Max: 1, ResetsAt: &resetsAt}, codes.ResourceExhausted},
+		{storage.ErrNotReleased, codes.FailedPrecondition},
+		{&storage.NotReleasedError{ReleaseAt: resetsAt}, codes.FailedPrecondition},
+		{storage.ErrOutOfStock, codes.FailedPrecondition},
+		{storage.ErrTransfersHeld, codes.PermissionDenied},
+		{context.Canceled, codes.Canceled},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{errors.New("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			// репозиторий оборачивает ошибки в op
+			wantCode(t, toStatus(fmt.Errorf("repository.Op: %w", tt.err)), tt.want)
+		})
+	}
+}
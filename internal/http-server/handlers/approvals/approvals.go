@@ -0,0 +1,164 @@
+package approvals
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const maxReasonLength = 200
+
+type ListResponse struct {
+	Orders []models.Order `json:"orders"`
+}
+
+type RejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ApprovalLister interface {
+	ListPendingApprovals(ctx context.Context, approver string, admin bool) ([]models.Order, error)
+}
+
+type OrderApprover interface {
+	ApproveOrder(ctx context.Context, approver string, admin bool, id int64) (models.Order, error)
+}
+
+type OrderRejecter interface {
+	RejectOrder(ctx context.Context, approver string, admin bool, id int64, reason string) (models.Order, error)
+}
+
+// List возвращает заказы, которые ждут решения: руководителю - заказы его отдела,
+// администратору - все.
+func List(log *slog.Logger, approvalLister ApprovalLister, admins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.approvals.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		orders, err := approvalLister.ListPendingApprovals(r.Context(), username, slices.Contains(admins, username))
+		if err != nil {
+			log.Error("failed to list pending approvals", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Orders: orders})
+	}
+}
+
+// Approve согласует заказ, товар попадает в инвентарь покупателя.
+func Approve(log *slog.Logger, orderApprover OrderApprover, admins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.approvals.Approve"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		order, err := orderApprover.ApproveOrder(r.Context(), username, slices.Contains(admins, username), id)
+		if err != nil {
+			log.Error("failed to approve order", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order approved", slog.String("username", username), slog.Int64("order_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// Reject отклоняет заказ, зарезервированные монеты возвращаются покупателю.
+// Тело с причиной необязательно.
+func Reject(log *slog.Logger, orderRejecter OrderRejecter, admins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.approvals.Reject"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		var req RejectRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		req.Reason = strings.TrimSpace(req.Reason)
+		if len(req.Reason) > maxReasonLength {
+			lang := i18n.FromContext(r.Context())
+			response.FailValidation(w, r, response.NewFieldError(lang, "reason", "max", i18n.MsgFieldMaxLength, "reason", strconv.Itoa(maxReasonLength)))
+			return
+		}
+
+		order, err := orderRejecter.RejectOrder(r.Context(), username, slices.Contains(admins, username), id, req.Reason)
+		if err != nil {
+			log.Error("failed to reject order", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order rejected", slog.String("username", username), slog.Int64("order_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
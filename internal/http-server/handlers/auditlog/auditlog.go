@@ -0,0 +1,169 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/csv"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type AuditLister interface {
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type ListResponse struct {
+	Entries []models.AuditEntry `json:"entries"`
+}
+
+func List(log *slog.Logger, auditLister AuditLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auditlog.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid filter", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+		if filter.Limit == 0 || filter.Limit > maxLimit {
+			filter.Limit = defaultLimit
+		}
+
+		entries, err := auditLister.ListAudit(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list audit entries", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if entries == nil {
+			entries = []models.AuditEntry{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Entries: entries})
+	}
+}
+
+// Export отдает журнал в CSV. Фильтры те же, что у List, но без ограничения по умолчанию.
+func Export(log *slog.Logger, auditLister AuditLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.auditlog.Export"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			log.Error("invalid filter", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		entries, err := auditLister.ListAudit(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list audit entries", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+		w.WriteHeader(http.StatusOK)
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{
+			"id", "created_at", "actor", "action", "target", "amount",
+			"actor_balance_before", "actor_balance_after",
+			"target_balance_before", "target_balance_after",
+			"request_id", "client_ip", "outcome", "details",
+		})
+		for _, e := range entries {
+			cw.Write([]string{
+				strconv.FormatInt(e.ID, 10),
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				e.Actor,
+				e.Action,
+				e.Target,
+				optInt(e.Amount),
+				optInt(e.ActorBalanceBefore),
+				optInt(e.ActorBalanceAfter),
+				optInt(e.TargetBalanceBefore),
+				optInt(e.TargetBalanceAfter),
+				e.RequestID,
+				e.ClientIP,
+				e.Outcome,
+				e.Details,
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			log.Error("failed to write csv", sl.Err(err))
+			return
+		}
+
+		log.Info("audit log exported", slog.Int("entries", len(entries)))
+	}
+}
+
+func parseFilter(q url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:     q.Get("actor"),
+		Target:    q.Get("target"),
+		Action:    q.Get("action"),
+		Outcome:   q.Get("outcome"),
+		RequestID: q.Get("request_id"),
+	}
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, i18n.NewError(i18n.MsgParamTime, "from")
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, i18n.NewError(i18n.MsgParamTime, "to")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			return filter, i18n.NewError(i18n.MsgParamNonNegative, "limit")
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, i18n.NewError(i18n.MsgParamNonNegative, "offset")
+		}
+	}
+
+	return filter, nil
+}
+
+func optInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
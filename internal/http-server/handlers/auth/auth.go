@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"io"
 	"log/slog"
@@ -11,17 +12,28 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/magneless/merch-shop/internal/lib/api/response"
 	"github.com/magneless/merch-shop/internal/lib/hashing"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
 	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/storage"
 )
 
+// AuthRequest - вход или регистрация. При входе правила логина и пароля
+// не проверяются: сотрудники, созданные до них, должны входить как раньше.
 type AuthRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// newAccount - правила для логина и пароля нового аккаунта.
+type newAccount struct {
+	Username string `json:"username" validate:"username"`
+	Password string `json:"password" validate:"password"`
 }
 
 type UserGetter interface {
-	GetUser(username, passwordHash string) error
+	GetUser(ctx context.Context, username, passwordHash string, canRegister bool) error
 }
 
 func New(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
@@ -38,49 +50,53 @@ func New(log *slog.Logger, userGetter UserGetter) http.HandlerFunc {
 		err := render.DecodeJSON(r.Body, &req)
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ErrorResponse{Error: "request body is empty"})
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
 			return
 		}
 		if err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
 			return
 		}
 
 		log.Info("request body decoded", slog.String("username", req.Username))
 
-		if err := validator.New().Struct(req); err != nil {
+		if err := validate.Struct(req); err != nil {
 			validateErr := err.(validator.ValidationErrors)
 			log.Error("invalid request", sl.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ValidationError(validateErr))
+			response.FailValidation(w, r, response.ValidationFields(i18n.FromContext(r.Context()), validateErr)...)
 			return
 		}
 
+		var policyErr validator.ValidationErrors
+		if err := validate.Struct(newAccount(req)); err != nil {
+			policyErr = err.(validator.ValidationErrors)
+		}
+
 		passwordHash, err := hashing.HashPassword(req.Password)
 		if err != nil {
 			log.Error("failed to hash password", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
 			return
 		}
 
 		// по api я совместил регистрацию и вход в аккаунт
-		err = userGetter.GetUser(req.Username, passwordHash)
+		err = userGetter.GetUser(r.Context(), req.Username, passwordHash, policyErr == nil)
+		if errors.Is(err, storage.ErrRegistrationRejected) {
+			log.Info("new account rejected", sl.Err(policyErr))
+			response.FailValidation(w, r, response.ValidationFields(i18n.FromContext(r.Context()), policyErr)...)
+			return
+		}
 		if err != nil {
 			log.Error("failed to auth user", sl.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ErrorResponse{Error: "wrong password or login"})
+			response.FailError(w, r, err)
 			return
 		}
 
 		accessToken, err := jwt_token.GenerateAccessToken(req.Username)
 		if err != nil {
 			log.Error("failed to generate access token", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
 			return
 		}
 
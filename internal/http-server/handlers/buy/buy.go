@@ -1,19 +1,39 @@
 package buy
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
 	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+
+	"github.com/go-playground/validator/v10"
 )
 
+// GiftRequest - подарок товара коллеге ToUser с необязательным поздравлением.
+type GiftRequest struct {
+	// Sender берется из токена, а не из тела запроса.
+	Sender  string `json:"-" validate:"required"`
+	ToUser  string `json:"toUser" validate:"required,username,nefield=Sender"`
+	Message string `json:"message,omitempty" validate:"max=200"`
+	Size    string `json:"size,omitempty"`
+	Color   string `json:"color,omitempty"`
+	Promo   string `json:"promo,omitempty"`
+}
+
 type MerchPurchaser interface {
-	PurchaseMerch(username, merchName string, quantity int) error
+	PurchaseMerch(ctx context.Context, username string, p models.Purchase) (models.Order, error)
 }
 
 // добавить специализированный ответ, если недостаточно средств
@@ -29,23 +49,40 @@ func New(log *slog.Logger, merchPurchaser MerchPurchaser) http.HandlerFunc {
 		item := chi.URLParam(r, "item")
 		if item == "" {
 			log.Error("item is empty")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ErrorResponse{Error: "chose item"})
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgItemRequired)
+			return
 		}
 
 		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
 		if !ok {
 			log.Error("failed to get username form context")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
 			return
 		}
 
-		err := merchPurchaser.PurchaseMerch(username, item, 1)
+		query := r.URL.Query()
+		purchase := models.Purchase{
+			Item: item,
+			Variant: models.VariantChoice{
+				Size:  query.Get("size"),
+				Color: query.Get("color"),
+			},
+			Quantity:  1,
+			PromoCode: strings.ToUpper(strings.TrimSpace(query.Get("promo"))),
+		}
+
+		order, err := merchPurchaser.PurchaseMerch(r.Context(), username, purchase)
 		if err != nil {
 			log.Error("failed to purchase merch from db", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.FailError(w, r, err)
+			return
+		}
+
+		// покупка ждет согласования руководителя, монеты зарезервированы
+		if order.Status == models.OrderPendingApproval {
+			log.Info("purchase awaits approval", slog.String("username", username), slog.Int64("order_id", order.ID))
+			render.Status(r, http.StatusAccepted)
+			render.JSON(w, r, order)
 			return
 		}
 
@@ -54,3 +91,81 @@ func New(log *slog.Logger, merchPurchaser MerchPurchaser) http.HandlerFunc {
 		log.Info("user bought item", slog.String("username", username))
 	}
 }
+
+// Gift покупает товар в подарок: монеты списываются с покупателя,
+// товар попадает в инвентарь req.ToUser.
+func Gift(log *slog.Logger, merchPurchaser MerchPurchaser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.buy.Gift"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		item := chi.URLParam(r, "item")
+		if item == "" {
+			log.Error("item is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgItemRequired)
+			return
+		}
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req GiftRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		req.Sender = username
+		req.Message = strings.TrimSpace(req.Message)
+		if err := validate.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			response.FailValidation(w, r, response.ValidationFields(i18n.FromContext(r.Context()), validateErr)...)
+			return
+		}
+
+		order, err := merchPurchaser.PurchaseMerch(r.Context(), username, models.Purchase{
+			Item: item,
+			Variant: models.VariantChoice{
+				Size:  req.Size,
+				Color: req.Color,
+			},
+			Quantity:  1,
+			PromoCode: strings.ToUpper(strings.TrimSpace(req.Promo)),
+			Recipient: req.ToUser,
+			Message:   req.Message,
+		})
+		if err != nil {
+			log.Error("failed to purchase gift", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("user gifted item", slog.String("username", username), slog.String("recipient", req.ToUser),
+			slog.Int64("order_id", order.ID))
+
+		// подарок ждет согласования руководителя покупателя
+		if order.Status == models.OrderPendingApproval {
+			render.Status(r, http.StatusAccepted)
+		} else {
+			render.Status(r, http.StatusOK)
+		}
+		render.JSON(w, r, order)
+	}
+}
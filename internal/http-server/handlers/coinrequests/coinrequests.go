@@ -0,0 +1,212 @@
+package coinrequests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+type CreateRequest struct {
+	// Requester берется из токена, а не из тела запроса.
+	Requester string `json:"-" validate:"required"`
+	Payer     string `json:"payer" validate:"required,username,nefield=Requester"`
+	Amount    int    `json:"amount" validate:"amount"`
+	Note      string `json:"note" validate:"max=200"`
+}
+
+type ListResponse struct {
+	Requests []models.CoinRequest `json:"requests"`
+}
+
+type CoinRequestCreator interface {
+	CreateCoinRequest(ctx context.Context, requester, payer string, amount int, note string, ttl time.Duration) (models.CoinRequest, error)
+}
+
+type CoinRequestLister interface {
+	ListCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error)
+}
+
+type CoinRequestAnswerer interface {
+	AcceptCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error)
+	DeclineCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error)
+}
+
+// Create просит другого сотрудника перевести монеты. Просьба истекает через ttl.
+func Create(log *slog.Logger, coinRequestCreator CoinRequestCreator, ttl time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.coinrequests.Create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		req.Requester = username
+		if err := validate.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			response.FailValidation(w, r, response.ValidationFields(i18n.FromContext(r.Context()), validateErr)...)
+			return
+		}
+
+		cr, err := coinRequestCreator.CreateCoinRequest(r.Context(), username, req.Payer, req.Amount, req.Note, ttl)
+		if err != nil {
+			log.Error("failed to create coin request", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("coin request created", slog.String("username", username), slog.Int64("coin_request_id", cr.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, cr)
+	}
+}
+
+// List отдает просьбы, в которых участвует пользователь.
+// Параметры: role (incoming, outgoing), status и limit.
+func List(log *slog.Logger, coinRequestLister CoinRequestLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.coinrequests.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		filter := models.CoinRequestFilter{
+			Username: username,
+			Role:     r.URL.Query().Get("role"),
+			Status:   r.URL.Query().Get("status"),
+			Limit:    defaultListLimit,
+		}
+
+		if filter.Role != "" && !slices.Contains([]string{models.CoinRequestIncoming, models.CoinRequestOutgoing}, filter.Role) {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownCoinRequestRole)
+			return
+		}
+		if filter.Status != "" && !slices.Contains([]string{
+			models.CoinRequestPending, models.CoinRequestAccepted, models.CoinRequestDeclined, models.CoinRequestExpired,
+		}, filter.Status) {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownCoinRequestStatus)
+			return
+		}
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > maxListLimit {
+				response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidLimit)
+				return
+			}
+			filter.Limit = limit
+		}
+
+		requests, err := coinRequestLister.ListCoinRequests(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list coin requests", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if requests == nil {
+			requests = []models.CoinRequest{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Requests: requests})
+	}
+}
+
+// Accept переводит монеты по просьбе. Отвечает только плательщик.
+func Accept(log *slog.Logger, coinRequestAnswerer CoinRequestAnswerer) http.HandlerFunc {
+	return answer(log, "handlers.coinrequests.Accept", coinRequestAnswerer.AcceptCoinRequest)
+}
+
+// Decline отклоняет просьбу. Отвечает только плательщик.
+func Decline(log *slog.Logger, coinRequestAnswerer CoinRequestAnswerer) http.HandlerFunc {
+	return answer(log, "handlers.coinrequests.Decline", coinRequestAnswerer.DeclineCoinRequest)
+}
+
+func answer(log *slog.Logger, op string, fn func(ctx context.Context, payer string, id int64) (models.CoinRequest, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid coin request id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidCoinRequestID)
+			return
+		}
+
+		cr, err := fn(r.Context(), username, id)
+		if err != nil {
+			log.Error("failed to answer coin request", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("coin request answered",
+			slog.String("username", username),
+			slog.Int64("coin_request_id", id),
+			slog.String("status", cr.Status),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, cr)
+	}
+}
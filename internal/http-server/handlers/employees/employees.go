@@ -0,0 +1,183 @@
+package employees
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const maxDepartmentLength = 100
+
+type EmployeeUpdater interface {
+	UpdateEmployee(ctx context.Context, actor, username string, upd models.EmployeeUpdate) (models.Employee, error)
+}
+
+type LimitsGetter interface {
+	GetEmployeeLimits(ctx context.Context, username string) (models.EmployeeLimits, error)
+}
+
+type LimitsSetter interface {
+	SetTransferLimits(ctx context.Context, actor, username string, override models.TransferLimitOverride) (models.EmployeeLimits, error)
+}
+
+// Update меняет отдел сотрудника и признак активности. Неактивные сотрудники
+// не получают гранты.
+func Update(log *slog.Logger, employeeUpdater EmployeeUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.employees.Update"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req models.EmployeeUpdate
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if req.Department != nil {
+			department := strings.TrimSpace(*req.Department)
+			req.Department = &department
+			if len(department) > maxDepartmentLength {
+				lang := i18n.FromContext(r.Context())
+				response.FailValidation(w, r, response.NewFieldError(lang, "department", "max", i18n.MsgFieldMaxLength, "department", strconv.Itoa(maxDepartmentLength)))
+				return
+			}
+		}
+
+		target := chi.URLParam(r, "username")
+		e, err := employeeUpdater.UpdateEmployee(r.Context(), username, target, req)
+		if err != nil {
+			log.Error("failed to update employee", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("employee updated", slog.String("username", username), slog.String("employee", target))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, e)
+	}
+}
+
+// Limits показывает действующие лимиты переводов сотрудника и сколько он уже отправил.
+func Limits(log *slog.Logger, limitsGetter LimitsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.employees.Limits"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		limits, err := limitsGetter.GetEmployeeLimits(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			log.Error("failed to get employee limits", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, limits)
+	}
+}
+
+// SetLimits заменяет лимиты сотрудника: null - как в конфиге, 0 - без ограничения.
+func SetLimits(log *slog.Logger, limitsSetter LimitsSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.employees.SetLimits"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req models.TransferLimitOverride
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if fields := validateLimits(i18n.FromContext(r.Context()), req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		target := chi.URLParam(r, "username")
+		limits, err := limitsSetter.SetTransferLimits(r.Context(), username, target, req)
+		if err != nil {
+			log.Error("failed to set employee limits", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Warn("employee limits changed", slog.String("username", username), slog.String("employee", target))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, limits)
+	}
+}
+
+func validateLimits(lang i18n.Lang, req models.TransferLimitOverride) []response.FieldError {
+	var fields []response.FieldError
+
+	for _, f := range []struct {
+		name  string
+		value *int
+	}{
+		{"maxTransfer", req.MaxTransfer},
+		{"daily", req.Daily},
+		{"weekly", req.Weekly},
+		{"dailyRecipients", req.DailyRecipients},
+	} {
+		if f.value != nil && *f.value < 0 {
+			fields = append(fields, response.NewFieldError(lang, f.name, "gte", i18n.MsgFieldNotNegative, f.name))
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,166 @@
+package fraud
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+type ListResponse struct {
+	Flags []models.FraudFlag `json:"flags"`
+}
+
+type ScanResponse struct {
+	Flagged int `json:"flagged"`
+}
+
+type FlagLister interface {
+	ListFraudFlags(ctx context.Context, filter models.FraudFlagFilter) ([]models.FraudFlag, error)
+}
+
+type FlagResolver interface {
+	ResolveFraudFlag(ctx context.Context, actor string, id int64, status string) (models.FraudFlag, error)
+}
+
+type Scanner interface {
+	Scan(ctx context.Context) (int, error)
+}
+
+// List возвращает отметки подозрительных сотрудников от новых к старым.
+func List(log *slog.Logger, flagLister FlagLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.fraud.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+		filter := models.FraudFlagFilter{
+			Username: query.Get("username"),
+			Status:   query.Get("status"),
+			Rule:     query.Get("rule"),
+			Limit:    defaultListLimit,
+		}
+
+		if filter.Status != "" && !slices.Contains([]string{
+			models.FraudFlagOpen, models.FraudFlagDismissed, models.FraudFlagConfirmed,
+		}, filter.Status) {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownFraudFlagStatus)
+			return
+		}
+		if filter.Rule != "" && !slices.Contains([]string{
+			models.FraudCycle, models.FraudPingPong, models.FraudFreshFanIn,
+		}, filter.Rule) {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownFraudRule)
+			return
+		}
+		if v := query.Get("limit"); v != "" {
+			limit, err := strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > maxListLimit {
+				response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidLimit)
+				return
+			}
+			filter.Limit = limit
+		}
+
+		flags, err := flagLister.ListFraudFlags(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list fraud flags", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Flags: flags})
+	}
+}
+
+// Dismiss закрывает отметку как ложную тревогу. Если открытых отметок
+// у сотрудника не осталось, его переводы снова разрешаются.
+func Dismiss(log *slog.Logger, flagResolver FlagResolver) http.HandlerFunc {
+	return resolve(log, "handlers.fraud.Dismiss", flagResolver, models.FraudFlagDismissed)
+}
+
+// Confirm подтверждает мошенничество. Запрет переводов остается.
+func Confirm(log *slog.Logger, flagResolver FlagResolver) http.HandlerFunc {
+	return resolve(log, "handlers.fraud.Confirm", flagResolver, models.FraudFlagConfirmed)
+}
+
+func resolve(log *slog.Logger, op string, flagResolver FlagResolver, status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid fraud flag id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidFraudFlagID)
+			return
+		}
+
+		flag, err := flagResolver.ResolveFraudFlag(r.Context(), username, id, status)
+		if err != nil {
+			log.Error("failed to resolve fraud flag", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("fraud flag resolved",
+			slog.String("username", username),
+			slog.Int64("flag_id", id),
+			slog.String("status", status),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, flag)
+	}
+}
+
+// Scan сразу проверяет все переводы за окно анализа, не дожидаясь пакетного запуска.
+func Scan(log *slog.Logger, scanner Scanner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.fraud.Scan"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		flagged, err := scanner.Scan(r.Context())
+		if err != nil {
+			log.Error("failed to scan transfers", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		log.Info("transfers scanned", slog.Int("flagged", flagged))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ScanResponse{Flagged: flagged})
+	}
+}
@@ -0,0 +1,339 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	maxNameLength       = 100
+	maxDepartmentLength = 100
+	maxNoteLength       = 200
+)
+
+// CreateRequest - кампания на Amount монет каждому активному сотруднику
+// (или отделу Department). С ScheduledAt кампания выполнится сама, без него
+// ее запускает администратор.
+type CreateRequest struct {
+	Name        string     `json:"name"`
+	Amount      int        `json:"amount"`
+	Department  string     `json:"department,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
+}
+
+type ListResponse struct {
+	Campaigns []models.GrantCampaign `json:"campaigns"`
+}
+
+type GrantCreator interface {
+	CreateGrantCampaign(ctx context.Context, c models.GrantCampaign) (models.GrantCampaign, error)
+}
+
+type GrantLister interface {
+	ListGrantCampaigns(ctx context.Context) ([]models.GrantCampaign, error)
+}
+
+type GrantGetter interface {
+	GetGrantCampaign(ctx context.Context, id int64) (models.GrantCampaign, error)
+}
+
+type GrantDeleter interface {
+	DeleteGrantCampaign(ctx context.Context, actor string, id int64) error
+}
+
+type GrantPreviewer interface {
+	PreviewGrantCampaign(ctx context.Context, id int64) (models.GrantPreview, error)
+}
+
+type GrantExecutor interface {
+	ExecuteGrantCampaign(ctx context.Context, actor string, id int64) (models.GrantRun, error)
+}
+
+type GrantReporter interface {
+	GrantCampaignReport(ctx context.Context, id int64) (models.GrantReport, error)
+}
+
+func Create(log *slog.Logger, grantCreator GrantCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		req.Department = strings.TrimSpace(req.Department)
+		if fields := validateCreate(i18n.FromContext(r.Context()), req, time.Now()); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		c := models.GrantCampaign{
+			Name:        req.Name,
+			Amount:      req.Amount,
+			Department:  req.Department,
+			Note:        req.Note,
+			Status:      models.GrantDraft,
+			ScheduledAt: req.ScheduledAt,
+			CreatedBy:   username,
+		}
+		if c.ScheduledAt != nil {
+			c.Status = models.GrantScheduled
+		}
+
+		c, err = grantCreator.CreateGrantCampaign(r.Context(), c)
+		if err != nil {
+			log.Error("failed to create grant campaign", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("grant campaign created", slog.String("username", username), slog.Int64("campaign_id", c.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, c)
+	}
+}
+
+func List(log *slog.Logger, grantLister GrantLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		campaigns, err := grantLister.ListGrantCampaigns(r.Context())
+		if err != nil {
+			log.Error("failed to list grant campaigns", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Campaigns: campaigns})
+	}
+}
+
+func Get(log *slog.Logger, grantGetter GrantGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidGrantID)
+			return
+		}
+
+		c, err := grantGetter.GetGrantCampaign(r.Context(), id)
+		if err != nil {
+			log.Error("failed to get grant campaign", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, c)
+	}
+}
+
+// Delete удаляет кампанию, по которой еще не было выплат.
+func Delete(log *slog.Logger, grantDeleter GrantDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Delete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidGrantID)
+			return
+		}
+
+		if err := grantDeleter.DeleteGrantCampaign(r.Context(), username, id); err != nil {
+			log.Error("failed to delete grant campaign", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("grant campaign deleted", slog.String("username", username), slog.Int64("campaign_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.MessageResponse{Message: i18n.FromContext(r.Context()).T(i18n.MsgGrantDeleted)})
+	}
+}
+
+func Preview(log *slog.Logger, grantPreviewer GrantPreviewer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Preview"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidGrantID)
+			return
+		}
+
+		preview, err := grantPreviewer.PreviewGrantCampaign(r.Context(), id)
+		if err != nil {
+			log.Error("failed to preview grant campaign", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, preview)
+	}
+}
+
+// Execute выплачивает кампанию сейчас. Повторный запуск доплачивает только
+// тем, кто еще не получил грант (например, новым сотрудникам).
+func Execute(log *slog.Logger, grantExecutor GrantExecutor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Execute"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidGrantID)
+			return
+		}
+
+		run, err := grantExecutor.ExecuteGrantCampaign(r.Context(), username, id)
+		if err != nil {
+			log.Error("failed to execute grant campaign", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("grant campaign executed",
+			slog.String("username", username),
+			slog.Int64("campaign_id", id),
+			slog.Int("recipients", run.Recipients),
+			slog.Int("total", run.Total),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, run)
+	}
+}
+
+func Report(log *slog.Logger, grantReporter GrantReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.grants.Report"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidGrantID)
+			return
+		}
+
+		report, err := grantReporter.GrantCampaignReport(r.Context(), id)
+		if err != nil {
+			log.Error("failed to get grant campaign report", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, report)
+	}
+}
+
+func validateCreate(lang i18n.Lang, req CreateRequest, now time.Time) []response.FieldError {
+	var fields []response.FieldError
+
+	switch {
+	case req.Name == "":
+		fields = append(fields, response.NewFieldError(lang, "name", "required", i18n.MsgFieldRequired, "name"))
+	case len(req.Name) > maxNameLength:
+		fields = append(fields, response.NewFieldError(lang, "name", "max", i18n.MsgFieldMaxLength, "name", strconv.Itoa(maxNameLength)))
+	}
+	if !validate.Amount(req.Amount) {
+		fields = append(fields, response.NewFieldError(lang, "amount", "amount", i18n.MsgFieldAmount, "amount", validate.MaxTransferAmount))
+	}
+	if len(req.Department) > maxDepartmentLength {
+		fields = append(fields, response.NewFieldError(lang, "department", "max", i18n.MsgFieldMaxLength, "department", strconv.Itoa(maxDepartmentLength)))
+	}
+	if len(req.Note) > maxNoteLength {
+		fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", strconv.Itoa(maxNoteLength)))
+	}
+	if req.ScheduledAt != nil && !req.ScheduledAt.After(now) {
+		fields = append(fields, response.NewFieldError(lang, "scheduledAt", "future", i18n.MsgFieldFuture, "scheduledAt"))
+	}
+
+	return fields
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/render"
 	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
 	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
 	"github.com/magneless/merch-shop/internal/models"
 )
@@ -17,6 +18,8 @@ type InfoGetter interface {
 	GetReceivedTransactions(userID int, toUsername string) ([]models.CoinTransaction, error)
 	GetSentTransactions(userID int, fromUsername string) ([]models.CoinTransaction, error)
 	GetBalanceAndId(username string) (int, int, error)
+	GetExpiringCoins(userID int) ([]models.ExpiringCoins, error)
+	GetOpenOrders(userID int) ([]models.Order, error)
 }
 
 func New(log *slog.Logger, infoGetter InfoGetter) http.HandlerFunc {
@@ -30,37 +33,55 @@ func New(log *slog.Logger, infoGetter InfoGetter) http.HandlerFunc {
 		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
 		if !ok {
 			log.Error("failed to get username form context")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
 			return
 		}
 
 		userID, balance, err := infoGetter.GetBalanceAndId(username)
 		if err != nil {
 			log.Error("failed to get balance or id from bd", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
+			return
 		}
 
 		sent, err := infoGetter.GetSentTransactions(userID, username)
 		if err != nil {
 			log.Error("failed to get sent transactions from bd", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
+			return
 		}
 
 		received, err := infoGetter.GetReceivedTransactions(userID, username)
 		if err != nil {
 			log.Error("failed to get received transactions from bd", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
+			return
 		}
 
 		inventory, err := infoGetter.GetInventory(userID)
 		if err != nil {
 			log.Error("failed to get inventory from bd", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		expiring, err := infoGetter.GetExpiringCoins(userID)
+		if err != nil {
+			log.Error("failed to get expiring coins from bd", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		orders, err := infoGetter.GetOpenOrders(userID)
+		if err != nil {
+			log.Error("failed to get open orders from bd", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		lang := i18n.FromContext(r.Context())
+		for i := range expiring {
+			expiring[i].Message = lang.T(i18n.MsgCoinsExpiring, expiring[i].Amount, expiring[i].ExpiresOn)
 		}
 
 		log.Info("user got his info", slog.String("username", username))
@@ -72,6 +93,8 @@ func New(log *slog.Logger, infoGetter InfoGetter) http.HandlerFunc {
 				Sent:     sent,
 				Received: received,
 			},
+			Expiring: expiring,
+			Orders:   orders,
 		})
 	}
 }
@@ -0,0 +1,100 @@
+package loglevel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+type LevelRequest struct {
+	Level string `json:"level"`
+}
+
+type LevelResponse struct {
+	Level string `json:"level"`
+}
+
+type AuditWriter interface {
+	WriteAudit(ctx context.Context, entry models.AuditEntry) error
+}
+
+func Get(log *slog.Logger, level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, LevelResponse{Level: level.Level().String()})
+	}
+}
+
+func Set(log *slog.Logger, level *slog.LevelVar, auditWriter AuditWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.loglevel.Set"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req LevelRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		var newLevel slog.Level
+		if err := newLevel.UnmarshalText([]byte(req.Level)); err != nil {
+			log.Error("invalid log level", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownLogLevel)
+			return
+		}
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		oldLevel := level.Level()
+
+		err = auditWriter.WriteAudit(r.Context(), models.AuditEntry{
+			Actor:   username,
+			Action:  audit.ActionAdminLogLevel,
+			Details: oldLevel.String() + " -> " + newLevel.String(),
+		})
+		if err != nil {
+			log.Error("failed to write audit entry", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		level.Set(newLevel)
+
+		log.Warn("log level changed",
+			slog.String("username", username),
+			slog.String("from", oldLevel.String()),
+			slog.String("to", newLevel.String()),
+		)
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, LevelResponse{Level: newLevel.String()})
+	}
+}
@@ -0,0 +1,311 @@
+package merch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+type MerchLister interface {
+	ListMerch(ctx context.Context) ([]models.MerchItem, error)
+}
+
+func New(log *slog.Logger, merchLister MerchLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.merch.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		items, err := merchLister.ListMerch(r.Context())
+		if err != nil {
+			log.Error("failed to list merch", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if items == nil {
+			items = []models.MerchItem{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.MerchResponse{Items: items})
+	}
+}
+
+const maxVariantFieldLength = 32
+
+type VariantsResponse struct {
+	Variants []models.MerchVariant `json:"variants"`
+}
+
+type VariantCreateRequest struct {
+	Size  string `json:"size"`
+	Color string `json:"color"`
+	// Stock - остаток на складе, null или отсутствие - без ограничений.
+	Stock *int `json:"stock"`
+}
+
+type VariantUpdateRequest struct {
+	// Stock - остаток на складе, null - без ограничений.
+	Stock  *int `json:"stock"`
+	Active bool `json:"active"`
+}
+
+// RuleRequest - правило покупки товара. Без maxQuantity и releaseAt ограничений нет,
+// пустой period - PeriodTotal.
+type RuleRequest struct {
+	MaxQuantity *int       `json:"maxQuantity"`
+	Period      string     `json:"period"`
+	ReleaseAt   *time.Time `json:"releaseAt"`
+}
+
+type VariantLister interface {
+	ListMerchVariants(ctx context.Context, item string) ([]models.MerchVariant, error)
+}
+
+type VariantCreator interface {
+	CreateMerchVariant(ctx context.Context, actor, item string, v models.MerchVariant) (models.MerchVariant, error)
+}
+
+type VariantUpdater interface {
+	UpdateMerchVariant(ctx context.Context, actor, item string, id int, stock *int, active bool) (models.MerchVariant, error)
+}
+
+type RuleSetter interface {
+	SetPurchaseRule(ctx context.Context, actor, item string, rule models.PurchaseRule) (models.PurchaseRule, error)
+}
+
+// Variants возвращает все варианты товара, включая снятые с продажи.
+func Variants(log *slog.Logger, variantLister VariantLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.merch.Variants"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		variants, err := variantLister.ListMerchVariants(r.Context(), chi.URLParam(r, "item"))
+		if err != nil {
+			log.Error("failed to list merch variants", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, VariantsResponse{Variants: variants})
+	}
+}
+
+// CreateVariant добавляет товару вариант (размер, цвет) с остатком на складе.
+func CreateVariant(log *slog.Logger, variantCreator VariantCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.merch.CreateVariant"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req VariantCreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		lang := i18n.FromContext(r.Context())
+		req.Size = strings.TrimSpace(req.Size)
+		req.Color = strings.TrimSpace(req.Color)
+
+		var fields []response.FieldError
+		if req.Size == "" && req.Color == "" {
+			fields = append(fields, response.NewFieldError(lang, "size", "required_without", i18n.MsgFieldRequiredWithout, "size", "color"))
+		}
+		for _, f := range []struct{ name, value string }{{"size", req.Size}, {"color", req.Color}} {
+			if len(f.value) > maxVariantFieldLength {
+				fields = append(fields, response.NewFieldError(lang, f.name, "max", i18n.MsgFieldMaxLength, f.name, strconv.Itoa(maxVariantFieldLength)))
+			}
+		}
+		if req.Stock != nil && *req.Stock < 0 {
+			fields = append(fields, response.NewFieldError(lang, "stock", "gte", i18n.MsgFieldNotNegative, "stock"))
+		}
+		if len(fields) > 0 {
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		item := chi.URLParam(r, "item")
+		v, err := variantCreator.CreateMerchVariant(r.Context(), username, item, models.MerchVariant{
+			Size:   req.Size,
+			Color:  req.Color,
+			Stock:  req.Stock,
+			Active: true,
+		})
+		if err != nil {
+			log.Error("failed to create merch variant", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("merch variant created", slog.String("username", username), slog.String("item", item), slog.Int("variant_id", v.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, v)
+	}
+}
+
+// UpdateVariant задает остаток варианта и снимает его с продажи или возвращает в продажу.
+func UpdateVariant(log *slog.Logger, variantUpdater VariantUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.merch.UpdateVariant"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Error("invalid variant id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidVariantID)
+			return
+		}
+
+		var req VariantUpdateRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if req.Stock != nil && *req.Stock < 0 {
+			lang := i18n.FromContext(r.Context())
+			response.FailValidation(w, r, response.NewFieldError(lang, "stock", "gte", i18n.MsgFieldNotNegative, "stock"))
+			return
+		}
+
+		item := chi.URLParam(r, "item")
+		v, err := variantUpdater.UpdateMerchVariant(r.Context(), username, item, id, req.Stock, req.Active)
+		if err != nil {
+			log.Error("failed to update merch variant", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("merch variant updated", slog.String("username", username), slog.String("item", item), slog.Int("variant_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, v)
+	}
+}
+
+// SetRule задает лимит покупок товара на сотрудника и дату начала продаж.
+func SetRule(log *slog.Logger, ruleSetter RuleSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.merch.SetRule"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req RuleRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if req.Period == "" {
+			req.Period = models.PeriodTotal
+		}
+		if fields := validateRule(i18n.FromContext(r.Context()), req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		item := chi.URLParam(r, "item")
+		rule, err := ruleSetter.SetPurchaseRule(r.Context(), username, item, models.PurchaseRule(req))
+		if err != nil {
+			log.Error("failed to set purchase rule", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("purchase rule set", slog.String("username", username), slog.String("item", item))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, rule)
+	}
+}
+
+func validateRule(lang i18n.Lang, req RuleRequest) []response.FieldError {
+	var fields []response.FieldError
+
+	if req.MaxQuantity != nil && *req.MaxQuantity <= 0 {
+		fields = append(fields, response.NewFieldError(lang, "maxQuantity", "gt", i18n.MsgFieldGreaterThan, "maxQuantity", "0"))
+	}
+	if !slices.Contains(models.PurchasePeriods, req.Period) {
+		fields = append(fields, response.NewFieldError(lang, "period", "oneof", i18n.MsgFieldOneOf, "period", strings.Join(models.PurchasePeriods, ", ")))
+	}
+
+	return fields
+}
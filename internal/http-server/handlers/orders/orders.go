@@ -0,0 +1,451 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+	maxNoteLength    = 200
+)
+
+// staffStatuses - статусы, в которые заказ переводит склад.
+var staffStatuses = []string{
+	models.OrderReadyForPickup, models.OrderShipped, models.OrderDelivered, models.OrderCancelled,
+}
+
+type ListResponse struct {
+	Orders []models.Order `json:"orders"`
+}
+
+type StatusRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+type OrderLister interface {
+	ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+}
+
+type OrderGetter interface {
+	GetOrder(ctx context.Context, id int64) (models.Order, error)
+}
+
+type OrderAdvancer interface {
+	AdvanceOrder(ctx context.Context, actor string, id int64, status, note string) (models.Order, error)
+}
+
+// List возвращает заказы текущего сотрудника и подарки ему от новых к старым.
+func List(log *slog.Logger, orderLister OrderLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		filter, ok := parseFilter(w, r)
+		if !ok {
+			return
+		}
+		filter.Username = username
+
+		orders, err := orderLister.ListOrders(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list orders", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Orders: orders})
+	}
+}
+
+// Get возвращает заказ текущего сотрудника или подарок ему с историей статусов.
+// Чужой заказ выглядит как несуществующий.
+func Get(log *slog.Logger, orderGetter OrderGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		order, ok := getOrder(w, r, log, orderGetter)
+		if !ok {
+			return
+		}
+		// подарок видят и покупатель, и получатель
+		if order.Username != username && order.Recipient != username {
+			response.Fail(w, r, response.KindOrderNotFound, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// StaffList возвращает заказы всех сотрудников от новых к старым.
+func StaffList(log *slog.Logger, orderLister OrderLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.StaffList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter, ok := parseFilter(w, r)
+		if !ok {
+			return
+		}
+		filter.Username = r.URL.Query().Get("username")
+
+		orders, err := orderLister.ListOrders(r.Context(), filter)
+		if err != nil {
+			log.Error("failed to list orders", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Orders: orders})
+	}
+}
+
+// StaffGet возвращает любой заказ с историей статусов.
+func StaffGet(log *slog.Logger, orderGetter OrderGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.StaffGet"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		order, ok := getOrder(w, r, log, orderGetter)
+		if !ok {
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// Advance переводит заказ в следующий статус. При отмене монеты возвращаются покупателю.
+func Advance(log *slog.Logger, orderAdvancer OrderAdvancer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.Advance"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		var req StatusRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		lang := i18n.FromContext(r.Context())
+		req.Note = strings.TrimSpace(req.Note)
+
+		var fields []response.FieldError
+		if !slices.Contains(staffStatuses, req.Status) {
+			fields = append(fields, response.NewFieldError(lang, "status", "oneof", i18n.MsgFieldOneOf, "status", strings.Join(staffStatuses, ", ")))
+		}
+		if len(req.Note) > maxNoteLength {
+			fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", strconv.Itoa(maxNoteLength)))
+		}
+		if len(fields) > 0 {
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		order, err := orderAdvancer.AdvanceOrder(r.Context(), username, id, req.Status, req.Note)
+		if err != nil {
+			log.Error("failed to change order status", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order status changed",
+			slog.String("username", username),
+			slog.Int64("order_id", id),
+			slog.String("status", req.Status),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+func parseFilter(w http.ResponseWriter, r *http.Request) (models.OrderFilter, bool) {
+	query := r.URL.Query()
+	filter := models.OrderFilter{
+		Status: query.Get("status"),
+		Limit:  defaultListLimit,
+	}
+
+	if filter.Status != "" && !slices.Contains(models.OrderStatuses, filter.Status) {
+		response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownOrderStatus)
+		return filter, false
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidLimit)
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+
+	return filter, true
+}
+
+func getOrder(w http.ResponseWriter, r *http.Request, log *slog.Logger, orderGetter OrderGetter) (models.Order, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		log.Error("invalid order id", sl.Err(err))
+		response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+		return models.Order{}, false
+	}
+
+	order, err := orderGetter.GetOrder(r.Context(), id)
+	if err != nil {
+		log.Error("failed to get order", sl.Err(err))
+		response.FailError(w, r, err)
+		return order, false
+	}
+
+	return order, true
+}
+
+type ReturnRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ReturnResolveRequest struct {
+	Note string `json:"note"`
+}
+
+type OrderCanceller interface {
+	CancelOrder(ctx context.Context, username string, id int64) (models.Order, error)
+}
+
+type ReturnRequester interface {
+	RequestReturn(ctx context.Context, username string, id int64, reason string) (models.Order, error)
+}
+
+type ReturnResolver interface {
+	ResolveReturn(ctx context.Context, actor string, id int64, accept bool, note string) (models.Order, error)
+}
+
+// Cancel отменяет свой заказ, пока не закрылось окно отмены. Монеты возвращаются сразу.
+func Cancel(log *slog.Logger, orderCanceller OrderCanceller) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.Cancel"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		order, err := orderCanceller.CancelOrder(r.Context(), username, id)
+		if err != nil {
+			log.Error("failed to cancel order", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order cancelled", slog.String("username", username), slog.Int64("order_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// RequestReturn просит склад принять товар обратно. Тело с причиной необязательно.
+func RequestReturn(log *slog.Logger, returnRequester ReturnRequester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.orders.RequestReturn"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		var req ReturnRequest
+		if !decodeNote(w, r, log, &req, &req.Reason, "reason") {
+			return
+		}
+
+		order, err := returnRequester.RequestReturn(r.Context(), username, id, req.Reason)
+		if err != nil {
+			log.Error("failed to request return", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order return requested", slog.String("username", username), slog.Int64("order_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// AcceptReturn принимает возврат: монеты возвращаются покупателю, товар - на склад.
+func AcceptReturn(log *slog.Logger, returnResolver ReturnResolver) http.HandlerFunc {
+	return resolveReturn(log, "handlers.orders.AcceptReturn", returnResolver, true)
+}
+
+// RejectReturn отказывает в возврате, заказ возвращается в прежний статус.
+func RejectReturn(log *slog.Logger, returnResolver ReturnResolver) http.HandlerFunc {
+	return resolveReturn(log, "handlers.orders.RejectReturn", returnResolver, false)
+}
+
+func resolveReturn(log *slog.Logger, op string, returnResolver ReturnResolver, accept bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid order id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidOrderID)
+			return
+		}
+
+		var req ReturnResolveRequest
+		if !decodeNote(w, r, log, &req, &req.Note, "note") {
+			return
+		}
+
+		order, err := returnResolver.ResolveReturn(r.Context(), username, id, accept, req.Note)
+		if err != nil {
+			log.Error("failed to resolve return", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("order return resolved",
+			slog.String("username", username),
+			slog.Int64("order_id", id),
+			slog.Bool("accepted", accept),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, order)
+	}
+}
+
+// decodeNote разбирает необязательное тело req с текстовым полем note длиной до maxNoteLength.
+func decodeNote(w http.ResponseWriter, r *http.Request, log *slog.Logger, req any, note *string, field string) bool {
+	err := render.DecodeJSON(r.Body, req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Error("failed to decode request body", sl.Err(err))
+		response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+		return false
+	}
+
+	*note = strings.TrimSpace(*note)
+	if len(*note) > maxNoteLength {
+		lang := i18n.FromContext(r.Context())
+		response.FailValidation(w, r, response.NewFieldError(lang, field, "max", i18n.MsgFieldMaxLength, field, strconv.Itoa(maxNoteLength)))
+		return false
+	}
+
+	return true
+}
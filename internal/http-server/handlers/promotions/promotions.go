@@ -0,0 +1,259 @@
+package promotions
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	maxNameLength = 100
+	maxCodeLength = 32
+)
+
+// codePattern - промокод после приведения к верхнему регистру.
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+// CreateRequest - промоакция. Без Code она применяется к подходящим покупкам сама.
+// Пустые Items, Categories и Usernames - без ограничения.
+type CreateRequest struct {
+	Name           string     `json:"name"`
+	Code           string     `json:"code,omitempty"`
+	Kind           string     `json:"kind"`
+	Value          int        `json:"value"`
+	Items          []string   `json:"items,omitempty"`
+	Categories     []string   `json:"categories,omitempty"`
+	Usernames      []string   `json:"usernames,omitempty"`
+	StartsAt       *time.Time `json:"startsAt,omitempty"`
+	EndsAt         *time.Time `json:"endsAt,omitempty"`
+	MaxUses        *int       `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser,omitempty"`
+	Stackable      bool       `json:"stackable"`
+}
+
+type ListResponse struct {
+	Promotions []models.Promotion `json:"promotions"`
+}
+
+type PromotionCreator interface {
+	CreatePromotion(ctx context.Context, p models.Promotion) (models.Promotion, error)
+}
+
+type PromotionLister interface {
+	ListPromotions(ctx context.Context) ([]models.Promotion, error)
+}
+
+type PromotionGetter interface {
+	GetPromotion(ctx context.Context, id int64) (models.Promotion, error)
+}
+
+type PromotionDisabler interface {
+	DisablePromotion(ctx context.Context, actor string, id int64) (models.Promotion, error)
+}
+
+func Create(log *slog.Logger, promotionCreator PromotionCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.promotions.Create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+		if fields := validateCreate(i18n.FromContext(r.Context()), req, time.Now()); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		p, err := promotionCreator.CreatePromotion(r.Context(), models.Promotion{
+			Name:           req.Name,
+			Code:           req.Code,
+			Kind:           req.Kind,
+			Value:          req.Value,
+			Items:          req.Items,
+			Categories:     req.Categories,
+			Usernames:      req.Usernames,
+			StartsAt:       req.StartsAt,
+			EndsAt:         req.EndsAt,
+			MaxUses:        req.MaxUses,
+			MaxUsesPerUser: req.MaxUsesPerUser,
+			Stackable:      req.Stackable,
+			CreatedBy:      username,
+		})
+		if err != nil {
+			log.Error("failed to create promotion", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("promotion created", slog.String("username", username), slog.Int64("promotion_id", p.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, p)
+	}
+}
+
+func List(log *slog.Logger, promotionLister PromotionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.promotions.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		promos, err := promotionLister.ListPromotions(r.Context())
+		if err != nil {
+			log.Error("failed to list promotions", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Promotions: promos})
+	}
+}
+
+func Get(log *slog.Logger, promotionGetter PromotionGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.promotions.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidPromoID)
+			return
+		}
+
+		p, err := promotionGetter.GetPromotion(r.Context(), id)
+		if err != nil {
+			log.Error("failed to get promotion", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, p)
+	}
+}
+
+// Disable прекращает промоакцию досрочно. Удалить ее нельзя: на нее ссылаются заказы.
+func Disable(log *slog.Logger, promotionDisabler PromotionDisabler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.promotions.Disable"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidPromoID)
+			return
+		}
+
+		p, err := promotionDisabler.DisablePromotion(r.Context(), username, id)
+		if err != nil {
+			log.Error("failed to disable promotion", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("promotion disabled", slog.String("username", username), slog.Int64("promotion_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, p)
+	}
+}
+
+func validateCreate(lang i18n.Lang, req CreateRequest, now time.Time) []response.FieldError {
+	var fields []response.FieldError
+
+	switch {
+	case req.Name == "":
+		fields = append(fields, response.NewFieldError(lang, "name", "required", i18n.MsgFieldRequired, "name"))
+	case len(req.Name) > maxNameLength:
+		fields = append(fields, response.NewFieldError(lang, "name", "max", i18n.MsgFieldMaxLength, "name", strconv.Itoa(maxNameLength)))
+	}
+	switch {
+	case len(req.Code) > maxCodeLength:
+		fields = append(fields, response.NewFieldError(lang, "code", "max", i18n.MsgFieldMaxLength, "code", strconv.Itoa(maxCodeLength)))
+	case req.Code != "" && !codePattern.MatchString(req.Code):
+		fields = append(fields, response.NewFieldError(lang, "code", "alphanum", i18n.MsgFieldNotValid, "code"))
+	}
+	if !slices.Contains(models.PromoKinds, req.Kind) {
+		fields = append(fields, response.NewFieldError(lang, "kind", "oneof", i18n.MsgFieldOneOf, "kind", strings.Join(models.PromoKinds, ", ")))
+	}
+	switch {
+	case req.Value <= 0:
+		fields = append(fields, response.NewFieldError(lang, "value", "gt", i18n.MsgFieldGreaterThan, "value", "0"))
+	case req.Kind == models.PromoPercent && req.Value > 100:
+		fields = append(fields, response.NewFieldError(lang, "value", "lte", i18n.MsgFieldAtMost, "value", "100"))
+	}
+	if req.EndsAt != nil {
+		switch {
+		case req.StartsAt != nil && !req.EndsAt.After(*req.StartsAt):
+			fields = append(fields, response.NewFieldError(lang, "endsAt", "gtfield", i18n.MsgFieldAfter, "endsAt", "startsAt"))
+		case !req.EndsAt.After(now):
+			fields = append(fields, response.NewFieldError(lang, "endsAt", "future", i18n.MsgFieldFuture, "endsAt"))
+		}
+	}
+	if req.MaxUses != nil && *req.MaxUses <= 0 {
+		fields = append(fields, response.NewFieldError(lang, "maxUses", "gt", i18n.MsgFieldGreaterThan, "maxUses", "0"))
+	}
+	if req.MaxUsesPerUser != nil && *req.MaxUsesPerUser <= 0 {
+		fields = append(fields, response.NewFieldError(lang, "maxUsesPerUser", "gt", i18n.MsgFieldGreaterThan, "maxUsesPerUser", "0"))
+	}
+
+	return fields
+}
@@ -0,0 +1,420 @@
+package scheduled
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/schedule"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const maxNoteLength = 200
+
+var catchUpRules = []string{models.CatchUpOnce, models.CatchUpAll, models.CatchUpSkip}
+
+// CreateRequest задает ровно одно из: RunAt (разовый перевод), Cron или IntervalSeconds.
+// StartAt для повторяющихся переводов - не раньше какого времени начинать, по умолчанию сейчас.
+type CreateRequest struct {
+	ToUser          string     `json:"toUser"`
+	Amount          int        `json:"amount"`
+	Note            string     `json:"note,omitempty"`
+	RunAt           *time.Time `json:"runAt,omitempty"`
+	Cron            string     `json:"cron,omitempty"`
+	IntervalSeconds int64      `json:"intervalSeconds,omitempty"`
+	StartAt         *time.Time `json:"startAt,omitempty"`
+	CatchUp         string     `json:"catchUp,omitempty"`
+}
+
+// UpdateRequest меняет переданные поля. Status: active возобновляет
+// приостановленное расписание, paused - приостанавливает.
+type UpdateRequest struct {
+	Amount  *int    `json:"amount,omitempty"`
+	Note    *string `json:"note,omitempty"`
+	CatchUp *string `json:"catchUp,omitempty"`
+	Status  *string `json:"status,omitempty"`
+}
+
+type ListResponse struct {
+	ScheduledTransfers []models.ScheduledTransfer `json:"scheduledTransfers"`
+}
+
+type ScheduleCreator interface {
+	CreateScheduledTransfer(ctx context.Context, st models.ScheduledTransfer) (models.ScheduledTransfer, error)
+}
+
+type ScheduleLister interface {
+	ListScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error)
+}
+
+type ScheduleGetter interface {
+	GetScheduledTransfer(ctx context.Context, owner string, id int64) (models.ScheduledTransfer, error)
+}
+
+type ScheduleUpdater interface {
+	UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update func(st *models.ScheduledTransfer) error) (models.ScheduledTransfer, error)
+}
+
+type ScheduleDeleter interface {
+	DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error
+}
+
+func Create(log *slog.Logger, scheduleCreator ScheduleCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.scheduled.Create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		now := time.Now()
+		if fields := validateCreate(i18n.FromContext(r.Context()), username, req, now); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		st := models.ScheduledTransfer{
+			FromUser:        username,
+			ToUser:          req.ToUser,
+			Amount:          req.Amount,
+			Note:            req.Note,
+			Cron:            req.Cron,
+			IntervalSeconds: req.IntervalSeconds,
+			StartAt:         now,
+			CatchUp:         req.CatchUp,
+		}
+		switch {
+		case req.RunAt != nil:
+			st.Kind = models.ScheduleOnce
+			st.StartAt = *req.RunAt
+		case req.Cron != "":
+			st.Kind = models.ScheduleCron
+		default:
+			st.Kind = models.ScheduleInterval
+		}
+		if req.StartAt != nil && st.Kind != models.ScheduleOnce {
+			st.StartAt = *req.StartAt
+		}
+		if st.CatchUp == "" {
+			st.CatchUp = models.CatchUpOnce
+		}
+
+		next, ok, err := schedule.First(st, now)
+		if err != nil || !ok {
+			log.Error("failed to compute first run", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgScheduleNoRuns)
+			return
+		}
+		st.NextRunAt = &next
+
+		st, err = scheduleCreator.CreateScheduledTransfer(r.Context(), st)
+		if err != nil {
+			log.Error("failed to create scheduled transfer", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("scheduled transfer created", slog.String("username", username), slog.Int64("schedule_id", st.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, st)
+	}
+}
+
+func List(log *slog.Logger, scheduleLister ScheduleLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.scheduled.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		transfers, err := scheduleLister.ListScheduledTransfers(r.Context(), username)
+		if err != nil {
+			log.Error("failed to list scheduled transfers", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if transfers == nil {
+			transfers = []models.ScheduledTransfer{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{ScheduledTransfers: transfers})
+	}
+}
+
+func Get(log *slog.Logger, scheduleGetter ScheduleGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.scheduled.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidScheduleID)
+			return
+		}
+
+		st, err := scheduleGetter.GetScheduledTransfer(r.Context(), username, id)
+		if err != nil {
+			log.Error("failed to get scheduled transfer", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, st)
+	}
+}
+
+// Update меняет сумму, комментарий, правило догоняния или статус.
+// Возобновленное расписание продолжается со следующего запуска после текущего момента,
+// разовый перевод, время которого прошло, выполняется сразу.
+func Update(log *slog.Logger, scheduleUpdater ScheduleUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.scheduled.Update"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidScheduleID)
+			return
+		}
+
+		var req UpdateRequest
+
+		err = render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if fields := validateUpdate(i18n.FromContext(r.Context()), req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		st, err := scheduleUpdater.UpdateScheduledTransfer(r.Context(), username, id, func(st *models.ScheduledTransfer) error {
+			return apply(st, req, time.Now())
+		})
+		if err != nil {
+			log.Error("failed to update scheduled transfer", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("scheduled transfer updated", slog.String("username", username), slog.Int64("schedule_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, st)
+	}
+}
+
+func Delete(log *slog.Logger, scheduleDeleter ScheduleDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.scheduled.Delete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidScheduleID)
+			return
+		}
+
+		if err := scheduleDeleter.DeleteScheduledTransfer(r.Context(), username, id); err != nil {
+			log.Error("failed to delete scheduled transfer", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("scheduled transfer deleted", slog.String("username", username), slog.Int64("schedule_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.MessageResponse{Message: i18n.FromContext(r.Context()).T(i18n.MsgScheduleDeleted)})
+	}
+}
+
+// apply вносит изменения в расписание под блокировкой строки.
+func apply(st *models.ScheduledTransfer, req UpdateRequest, now time.Time) error {
+	if st.Status == models.ScheduleCompleted {
+		return i18n.NewError(i18n.MsgScheduleCompleted)
+	}
+
+	if req.Amount != nil {
+		st.Amount = *req.Amount
+	}
+	if req.Note != nil {
+		st.Note = *req.Note
+	}
+	if req.CatchUp != nil {
+		st.CatchUp = *req.CatchUp
+	}
+
+	if req.Status == nil || *req.Status == st.Status {
+		return nil
+	}
+
+	st.Status = *req.Status
+	st.PauseReason = ""
+	if st.Status == models.SchedulePaused {
+		return nil
+	}
+
+	next, ok, err := schedule.First(*st, now)
+	if err != nil {
+		return fmt.Errorf("failed to compute next run: %w", err)
+	}
+	if !ok {
+		return i18n.NewError(i18n.MsgScheduleNoRuns)
+	}
+	st.NextRunAt = &next
+
+	return nil
+}
+
+func validateCreate(lang i18n.Lang, sender string, req CreateRequest, now time.Time) []response.FieldError {
+	var fields []response.FieldError
+
+	switch {
+	case !validate.Username(req.ToUser):
+		fields = append(fields, response.NewFieldError(lang, "toUser", "username", i18n.MsgFieldUsername, "toUser", validate.UsernameMinLen, validate.UsernameMaxLen))
+	case req.ToUser == sender:
+		fields = append(fields, response.NewFieldError(lang, "toUser", "nefield", i18n.MsgFieldDiffer, "toUser", "sender"))
+	}
+	if !validate.Amount(req.Amount) {
+		fields = append(fields, response.NewFieldError(lang, "amount", "amount", i18n.MsgFieldAmount, "amount", validate.MaxTransferAmount))
+	}
+	if len(req.Note) > maxNoteLength {
+		fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", strconv.Itoa(maxNoteLength)))
+	}
+
+	kinds := 0
+	for _, set := range []bool{req.RunAt != nil, req.Cron != "", req.IntervalSeconds != 0} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		fields = append(fields, response.NewFieldError(lang, "runAt", "required_without", i18n.MsgFieldScheduleKind, "runAt", "cron", "intervalSeconds"))
+	}
+
+	if req.RunAt != nil && !req.RunAt.After(now) {
+		fields = append(fields, response.NewFieldError(lang, "runAt", "future", i18n.MsgFieldFuture, "runAt"))
+	}
+	if req.Cron != "" {
+		if _, err := schedule.ParseCron(req.Cron); err != nil {
+			fields = append(fields, response.NewFieldError(lang, "cron", "cron", i18n.MsgFieldCron, "cron"))
+		}
+	}
+	if req.IntervalSeconds != 0 && time.Duration(req.IntervalSeconds)*time.Second < schedule.MinInterval {
+		fields = append(fields, response.NewFieldError(lang, "intervalSeconds", "gte", i18n.MsgFieldMinInterval, "intervalSeconds", int(schedule.MinInterval.Seconds())))
+	}
+	if req.CatchUp != "" && !slices.Contains(catchUpRules, req.CatchUp) {
+		fields = append(fields, response.NewFieldError(lang, "catchUp", "oneof", i18n.MsgFieldOneOf, "catchUp", strings.Join(catchUpRules, ", ")))
+	}
+
+	return fields
+}
+
+func validateUpdate(lang i18n.Lang, req UpdateRequest) []response.FieldError {
+	var fields []response.FieldError
+
+	if req.Amount != nil && !validate.Amount(*req.Amount) {
+		fields = append(fields, response.NewFieldError(lang, "amount", "amount", i18n.MsgFieldAmount, "amount", validate.MaxTransferAmount))
+	}
+	if req.Note != nil && len(*req.Note) > maxNoteLength {
+		fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", strconv.Itoa(maxNoteLength)))
+	}
+	if req.CatchUp != nil && !slices.Contains(catchUpRules, *req.CatchUp) {
+		fields = append(fields, response.NewFieldError(lang, "catchUp", "oneof", i18n.MsgFieldOneOf, "catchUp", strings.Join(catchUpRules, ", ")))
+	}
+	statuses := []string{models.ScheduleActive, models.SchedulePaused}
+	if req.Status != nil && !slices.Contains(statuses, *req.Status) {
+		fields = append(fields, response.NewFieldError(lang, "status", "oneof", i18n.MsgFieldOneOf, "status", strings.Join(statuses, ", ")))
+	}
+
+	return fields
+}
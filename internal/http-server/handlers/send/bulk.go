@@ -0,0 +1,188 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	// MaxBulkRecipients - сколько получателей может быть в одном массовом переводе.
+	MaxBulkRecipients = 100
+	maxNoteLength     = 200
+)
+
+// BulkRequest задает либо Transfers с суммой для каждого получателя,
+// либо Recipients и Total, который делится поровну (см. split).
+type BulkRequest struct {
+	Transfers  []BulkTransfer `json:"transfers,omitempty"`
+	Recipients []string       `json:"recipients,omitempty"`
+	Total      int            `json:"total,omitempty"`
+	Note       string         `json:"note,omitempty"`
+}
+
+type BulkTransfer struct {
+	ToUser string `json:"toUser"`
+	Amount int    `json:"amount"`
+}
+
+type BulkSender interface {
+	SendCoinsBulk(ctx context.Context, batch models.TransferBatch) (models.TransferBatch, error)
+}
+
+// Bulk переводит монеты нескольким получателям в одной транзакции:
+// либо все переводы проходят, либо ни один.
+func Bulk(log *slog.Logger, bulkSender BulkSender) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.send.Bulk"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req BulkRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if fields := validateBulk(i18n.FromContext(r.Context()), username, req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		transfers := make([]models.CoinTransaction, 0, len(req.Transfers))
+		for _, t := range req.Transfers {
+			transfers = append(transfers, models.CoinTransaction{ToUser: t.ToUser, Amount: t.Amount})
+		}
+		if len(req.Recipients) > 0 {
+			transfers = split(req.Total, req.Recipients)
+		}
+
+		batch, err := bulkSender.SendCoinsBulk(r.Context(), models.TransferBatch{
+			FromUser:  username,
+			Note:      req.Note,
+			Transfers: transfers,
+		})
+		if err != nil {
+			log.Error("failed to send coins", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Info("bulk transfer completed",
+			slog.String("sender", username),
+			slog.Int64("batch_id", batch.ID),
+			slog.Int("recipients", len(batch.Transfers)),
+			slog.Int("total", batch.Total),
+		)
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, batch)
+	}
+}
+
+// split делит total между получателями поровну. Остаток от деления раздается
+// по одной монете первым получателям в порядке запроса, так что сумма долей
+// всегда равна total: 10 на троих - 4, 3, 3.
+func split(total int, recipients []string) []models.CoinTransaction {
+	share, rest := total/len(recipients), total%len(recipients)
+
+	transfers := make([]models.CoinTransaction, 0, len(recipients))
+	for i, to := range recipients {
+		amount := share
+		if i < rest {
+			amount++
+		}
+		transfers = append(transfers, models.CoinTransaction{ToUser: to, Amount: amount})
+	}
+
+	return transfers
+}
+
+func validateBulk(lang i18n.Lang, sender string, req BulkRequest) []response.FieldError {
+	var fields []response.FieldError
+
+	if (len(req.Transfers) > 0) == (len(req.Recipients) > 0) {
+		return append(fields, response.NewFieldError(lang, "transfers", "required_without", i18n.MsgFieldBulkMode, "transfers", "recipients"))
+	}
+
+	var recipients []string
+	var field string
+	if len(req.Transfers) > 0 {
+		field = "transfers"
+		for i, t := range req.Transfers {
+			recipients = append(recipients, t.ToUser)
+			if !validate.Amount(t.Amount) {
+				name := fmt.Sprintf("transfers[%d].amount", i)
+				fields = append(fields, response.NewFieldError(lang, name, "amount", i18n.MsgFieldAmount, name, validate.MaxTransferAmount))
+			}
+		}
+	} else {
+		field = "recipients"
+		recipients = req.Recipients
+		switch {
+		case !validate.Amount(req.Total):
+			fields = append(fields, response.NewFieldError(lang, "total", "amount", i18n.MsgFieldAmount, "total", validate.MaxTransferAmount))
+		case req.Total < len(req.Recipients):
+			fields = append(fields, response.NewFieldError(lang, "total", "gte", i18n.MsgFieldSplitTotal, "total", len(req.Recipients)))
+		}
+	}
+
+	if len(recipients) > MaxBulkRecipients {
+		fields = append(fields, response.NewFieldError(lang, field, "max", i18n.MsgFieldMaxItems, field, MaxBulkRecipients))
+	}
+
+	seen := make(map[string]bool, len(recipients))
+	for i, to := range recipients {
+		name := fmt.Sprintf("%s[%d]", field, i)
+		if field == "transfers" {
+			name += ".toUser"
+		}
+
+		switch {
+		case !validate.Username(to):
+			fields = append(fields, response.NewFieldError(lang, name, "username", i18n.MsgFieldUsername, name, validate.UsernameMinLen, validate.UsernameMaxLen))
+		case to == sender:
+			fields = append(fields, response.NewFieldError(lang, name, "nefield", i18n.MsgFieldDiffer, name, "sender"))
+		case seen[to]:
+			fields = append(fields, response.NewFieldError(lang, name, "unique", i18n.MsgFieldDuplicate, name, to))
+		}
+		seen[to] = true
+	}
+
+	if utf8.RuneCountInString(req.Note) > maxNoteLength {
+		fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", fmt.Sprint(maxNoteLength)))
+	}
+
+	return fields
+}
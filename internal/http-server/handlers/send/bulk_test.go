@@ -0,0 +1,27 @@
+package send
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+)
+
+// Заметка ограничена символами, а не байтами: колонка note - VARCHAR(200).
+func TestValidateBulkNoteLength(t *testing.T) {
+	tests := []struct {
+		note  string
+		valid bool
+	}{
+		{strings.Repeat("я", maxNoteLength), true},
+		{strings.Repeat("a", maxNoteLength), true},
+		{strings.Repeat("я", maxNoteLength+1), false},
+	}
+	for _, tt := range tests {
+		req := BulkRequest{Recipients: []string{"bob", "carol"}, Total: 10, Note: tt.note}
+		fields := validateBulk(i18n.Default, "anna", req)
+		if valid := len(fields) == 0; valid != tt.valid {
+			t.Errorf("note of %d runes: fields = %v, want valid = %v", len([]rune(tt.note)), fields, tt.valid)
+		}
+	}
+}
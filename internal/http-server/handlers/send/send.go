@@ -1,6 +1,7 @@
 package send
 
 import (
+	"context"
 	"errors"
 	"io"
 	"log/slog"
@@ -8,23 +9,28 @@ import (
 
 	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
 	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/validate"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
 )
 
 type SendCoinRequest struct {
-	ToUser string `json:"toUser"`
-	Amount int    `json:"amount"`
+	// Sender берется из токена, а не из тела запроса.
+	Sender string `json:"-" validate:"required"`
+	ToUser string `json:"toUser" validate:"required,username,nefield=Sender"`
+	Amount int    `json:"amount" validate:"amount"`
 }
 type CoinsSender interface {
-	SendCoins(senderUsername, receiverUsername string, amount int) error
+	SendCoins(ctx context.Context, senderUsername, receiverUsername string, amount int) error
 }
 
 func New(log *slog.Logger, coinsSender CoinsSender) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		const op = "handlers.buy.New"
+		const op = "handlers.send.New"
 
 		log := log.With(
 			slog.String("op", op),
@@ -34,8 +40,7 @@ func New(log *slog.Logger, coinsSender CoinsSender) http.HandlerFunc {
 		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
 		if !ok {
 			log.Error("failed to get username form context")
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.Fail(w, r, response.KindInternal, "")
 			return
 		}
 
@@ -44,31 +49,37 @@ func New(log *slog.Logger, coinsSender CoinsSender) http.HandlerFunc {
 		err := render.DecodeJSON(r.Body, &req)
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ErrorResponse{Error: "empty request"})
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
 			return
 		}
 		if err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
-			render.Status(r, http.StatusInternalServerError)
-			render.JSON(w, r, response.ErrorResponse{Error: "iternal error"})
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
 			return
 		}
 
 		log.Info("request body decoded ", slog.Any("request", slog.String("username", username)))
 
-		err = coinsSender.SendCoins(username, req.ToUser, req.Amount)
+		req.Sender = username
+		if err := validate.Struct(req); err != nil {
+			validateErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", sl.Err(err))
+			response.FailValidation(w, r, response.ValidationFields(i18n.FromContext(r.Context()), validateErr)...)
+			return
+		}
+
+		err = coinsSender.SendCoins(r.Context(), username, req.ToUser, req.Amount)
 		if err != nil {
 			log.Error("failed to send coins", sl.Err(err))
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, response.ErrorResponse{Error: "internal error"})
+			response.FailError(w, r, err)
+			return
 		}
 
 		log.Info("Coins successfuly sent", slog.Any("particapants", map[string]string{
-			"sender": username,
+			"sender":   username,
 			"receiver": req.ToUser,
 		}))
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, response.MessageResponse{Message: "coins successfully sent"})
+		render.JSON(w, r, response.MessageResponse{Message: i18n.FromContext(r.Context()).T(i18n.MsgCoinsSent)})
 	}
 }
@@ -0,0 +1,212 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/magneless/merch-shop/internal/events"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/notify"
+)
+
+const (
+	replayPageSize = 500
+	retryMillis    = 3000
+	// сколько последних отправленных ID помнит поток
+	sentCapacity = 1024
+	// насколько раньше Last-Event-ID досылаются опоздавшие события
+	lateEventWindow = 30 * time.Second
+)
+
+type EventReplayer interface {
+	EventsSince(ctx context.Context, username string, afterID int64, limit int) ([]events.Event, error)
+	LateEvents(ctx context.Context, username string, lastID int64, window time.Duration, limit int) ([]events.Event, error)
+}
+
+// New отдает личный поток уведомлений пользователя в формате Server-Sent Events.
+// Клиент может продолжить поток с заголовком Last-Event-ID (или параметром lastEventId).
+func New(log *slog.Logger, broker notify.Broker, replayer EventReplayer, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.sse.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		lastID, err := lastEventID(r)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidLastEventID)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		// поток живет дольше, чем WriteTimeout сервера
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			log.Warn("failed to reset write deadline", sl.Err(err))
+		}
+
+		topic := notify.UserTopic(username)
+		sub := broker.Subscribe(topic)
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+
+		sent := newSentIDs(sentCapacity)
+		if lastID > 0 {
+			sent.add(lastID)
+			if err := replay(r.Context(), w, replayer, username, topic, lastID, sent); err != nil {
+				log.Error("failed to replay events", sl.Err(err))
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			log.Error("streaming is not supported", sl.Err(err))
+			return
+		}
+
+		log.Info("event stream opened", slog.String("username", username), slog.Int64("last_event_id", lastID))
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case n, ok := <-sub.C():
+				if !ok {
+					// отстали или брокер переподключился: клиент догонит по Last-Event-ID
+					log.Info("event stream closed by broker", slog.String("username", username))
+					return
+				}
+				// ID выдается до коммита, поэтому событие с меньшим ID может прийти позже
+				if sent.has(n.ID) {
+					continue
+				}
+				write(w, n)
+				sent.add(n.ID)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// replay досылает события после lastID и опоздавшие события с меньшим ID,
+// которые закоммитились уже после того, как клиент получил lastID.
+func replay(ctx context.Context, w io.Writer, replayer EventReplayer, username, topic string, lastID int64, sent *sentIDs) error {
+	late, err := replayer.LateEvents(ctx, username, lastID, lateEventWindow, replayPageSize)
+	if err != nil {
+		return err
+	}
+	if err := writeEvents(w, late, topic, sent); err != nil {
+		return err
+	}
+
+	for afterID := lastID; ; {
+		page, err := replayer.EventsSince(ctx, username, afterID, replayPageSize)
+		if err != nil {
+			return err
+		}
+		if err := writeEvents(w, page, topic, sent); err != nil {
+			return err
+		}
+
+		if len(page) < replayPageSize {
+			return nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+func writeEvents(w io.Writer, page []events.Event, topic string, sent *sentIDs) error {
+	for _, e := range page {
+		notifications, err := notify.FromEvent(e)
+		if err != nil {
+			return err
+		}
+		for _, n := range notifications {
+			if n.Topic == topic && !sent.has(n.ID) {
+				write(w, n)
+				sent.add(n.ID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sentIDs - последние отправленные клиенту ID. Старые вытесняются по кругу.
+type sentIDs struct {
+	ids   map[int64]struct{}
+	order []int64
+	next  int
+}
+
+func newSentIDs(capacity int) *sentIDs {
+	return &sentIDs{
+		ids:   make(map[int64]struct{}, capacity),
+		order: make([]int64, 0, capacity),
+	}
+}
+
+func (s *sentIDs) has(id int64) bool {
+	_, ok := s.ids[id]
+	return ok
+}
+
+func (s *sentIDs) add(id int64) {
+	if s.has(id) {
+		return
+	}
+	if len(s.order) < cap(s.order) {
+		s.order = append(s.order, id)
+	} else {
+		delete(s.ids, s.order[s.next])
+		s.order[s.next] = id
+		s.next = (s.next + 1) % len(s.order)
+	}
+	s.ids[id] = struct{}{}
+}
+
+func write(w io.Writer, n notify.Notification) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", n.ID, n.Type, n.Data)
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("lastEventId")
+	}
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(v, 10, 64)
+}
@@ -0,0 +1,171 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/events"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/notify"
+	"github.com/magneless/merch-shop/internal/notify/memory"
+)
+
+type fakeReplayer struct {
+	late   []events.Event
+	since  map[int64][]events.Event
+	window time.Duration
+}
+
+func (f *fakeReplayer) EventsSince(_ context.Context, _ string, afterID int64, _ int) ([]events.Event, error) {
+	return f.since[afterID], nil
+}
+
+func (f *fakeReplayer) LateEvents(_ context.Context, _ string, _ int64, window time.Duration, _ int) ([]events.Event, error) {
+	f.window = window
+	return f.late, nil
+}
+
+func transfer(t *testing.T, id int64) events.Event {
+	t.Helper()
+
+	payload, err := json.Marshal(events.CoinsTransferred{FromUser: "bob", ToUser: "anna", Amount: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events.Event{ID: id, Type: events.TypeCoinsTransferred, Usernames: []string{"bob", "anna"}, Payload: payload}
+}
+
+func received(id int64) notify.Notification {
+	return notify.Notification{ID: id, Topic: notify.UserTopic("anna"), Type: notify.TypeCoinsReceived, Data: json.RawMessage(`{}`)}
+}
+
+// openStream подключается к потоку anna и ждет, пока сервер подпишется на брокер.
+func openStream(t *testing.T, broker notify.Broker, replayer EventReplayer, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	handler := New(slog.New(slog.NewTextHandler(io.Discard, nil)), broker, replayer, time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(w, r.WithContext(context.WithValue(r.Context(), mwAuth.UsernameKey, "anna")))
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readIDs читает n сообщений и возвращает их id.
+func readIDs(t *testing.T, r *bufio.Reader, n int) []int64 {
+	t.Helper()
+
+	var ids []int64
+	for len(ids) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream after %v: %v", ids, err)
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "id: "); ok {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestLiveEventsOutOfOrder: событие с меньшим ID, закоммиченное позже, не теряется,
+// а повтор уже отправленного отбрасывается.
+func TestLiveEventsOutOfOrder(t *testing.T) {
+	broker := memory.New(16)
+	stream := openStream(t, broker, &fakeReplayer{}, "")
+
+	// дожидаемся подписки: первое сообщение потока - retry
+	if _, err := stream.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{5, 4, 5, 6} {
+		if err := broker.Publish(context.Background(), received(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := readIDs(t, stream, 3), []int64{5, 4, 6}; !equalIDs(got, want) {
+		t.Fatalf("ids = %v, want %v", got, want)
+	}
+}
+
+func TestReplayIncludesLateEvents(t *testing.T) {
+	replayer := &fakeReplayer{
+		late: []events.Event{transfer(t, 8)},
+		since: map[int64][]events.Event{
+			10: {transfer(t, 11), transfer(t, 12)},
+		},
+	}
+	broker := memory.New(16)
+	stream := openStream(t, broker, replayer, "10")
+
+	if got, want := readIDs(t, stream, 3), []int64{8, 11, 12}; !equalIDs(got, want) {
+		t.Fatalf("replayed ids = %v, want %v", got, want)
+	}
+	if replayer.window != lateEventWindow {
+		t.Errorf("late event window = %s, want %s", replayer.window, lateEventWindow)
+	}
+
+	// 12 уже отправлен при досылке, 10 клиент видел сам, 9 опоздал
+	for _, id := range []int64{12, 10, 9} {
+		if err := broker.Publish(context.Background(), received(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := readIDs(t, stream, 1), []int64{9}; !equalIDs(got, want) {
+		t.Fatalf("live ids = %v, want %v", got, want)
+	}
+}
+
+func TestSentIDsEvictsOldest(t *testing.T) {
+	s := newSentIDs(2)
+	s.add(1)
+	s.add(2)
+	s.add(2)
+	s.add(3)
+
+	if s.has(1) || !s.has(2) || !s.has(3) {
+		t.Fatalf("has(1, 2, 3) = %v, %v, %v, want false, true, true", s.has(1), s.has(2), s.has(3))
+	}
+}
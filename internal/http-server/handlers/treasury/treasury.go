@@ -0,0 +1,127 @@
+package treasury
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+)
+
+const (
+	maxMintAmount = 10_000_000
+	maxNoteLength = 200
+)
+
+type MintRequest struct {
+	Amount int    `json:"amount"`
+	Note   string `json:"note,omitempty"`
+}
+
+type BalanceResponse struct {
+	Balance int `json:"balance"`
+}
+
+type BalanceGetter interface {
+	TreasuryBalance(ctx context.Context) (int, error)
+}
+
+type Minter interface {
+	MintTreasury(ctx context.Context, actor string, amount int, note string) (int, error)
+}
+
+func Get(log *slog.Logger, balanceGetter BalanceGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.treasury.Get"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		balance, err := balanceGetter.TreasuryBalance(r.Context())
+		if err != nil {
+			log.Error("failed to get treasury balance", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, BalanceResponse{Balance: balance})
+	}
+}
+
+// Mint выпускает новые монеты в казну. Это единственный способ увеличить
+// общее число монет, кроме стартового баланса при регистрации.
+func Mint(log *slog.Logger, minter Minter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.treasury.Mint"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req MintRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if fields := validateMint(i18n.FromContext(r.Context()), req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		balance, err := minter.MintTreasury(r.Context(), username, req.Amount, req.Note)
+		if err != nil {
+			log.Error("failed to mint coins", sl.Err(err))
+			response.FailError(w, r, err)
+			return
+		}
+
+		log.Warn("coins minted", slog.String("username", username), slog.Int("amount", req.Amount))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, BalanceResponse{Balance: balance})
+	}
+}
+
+func validateMint(lang i18n.Lang, req MintRequest) []response.FieldError {
+	var fields []response.FieldError
+
+	switch {
+	case req.Amount <= 0:
+		fields = append(fields, response.NewFieldError(lang, "amount", "gt", i18n.MsgFieldGreaterThan, "amount", "0"))
+	case req.Amount > maxMintAmount:
+		fields = append(fields, response.NewFieldError(lang, "amount", "lte", i18n.MsgFieldAtMost, "amount", strconv.Itoa(maxMintAmount)))
+	}
+	if len(req.Note) > maxNoteLength {
+		fields = append(fields, response.NewFieldError(lang, "note", "max", i18n.MsgFieldMaxLength, "note", strconv.Itoa(maxNoteLength)))
+	}
+
+	return fields
+}
@@ -0,0 +1,332 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/magneless/merch-shop/internal/events"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+	"github.com/magneless/merch-shop/internal/webhook"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type CreateRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	// Employee ограничивает подписку событиями одного сотрудника.
+	// Обычный пользователь может подписаться только на свои события.
+	Employee string `json:"employee,omitempty"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+type ListResponse struct {
+	Webhooks []models.WebhookSubscription `json:"webhooks"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+type WebhookCreator interface {
+	CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+}
+
+type WebhookLister interface {
+	ListWebhooks(ctx context.Context, owner string) ([]models.WebhookSubscription, error)
+}
+
+type WebhookDeleter interface {
+	DeleteWebhook(ctx context.Context, owner string, id int64) error
+}
+
+type DeliveryLister interface {
+	ListWebhookDeliveries(ctx context.Context, owner string, subID int64, status string, limit int) ([]models.WebhookDelivery, error)
+}
+
+type DeliveryRequeuer interface {
+	RedeliverWebhook(ctx context.Context, owner string, subID, deliveryID int64) error
+}
+
+func Create(log *slog.Logger, webhookCreator WebhookCreator, admins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.Create"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgRequestBodyEmpty)
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+			return
+		}
+
+		if fields := validate(i18n.FromContext(r.Context()), req); len(fields) > 0 {
+			log.Error("invalid request", slog.Any("fields", fields))
+			response.FailValidation(w, r, fields...)
+			return
+		}
+
+		if !slices.Contains(admins, username) {
+			if req.Employee != "" && req.Employee != username {
+				log.Warn("subscription to foreign events denied", slog.String("username", username))
+				response.Fail(w, r, response.KindForbidden, i18n.MsgSubscribeOwnEvents)
+				return
+			}
+			req.Employee = username
+		}
+
+		if req.Secret == "" {
+			req.Secret, err = webhook.NewSecret()
+			if err != nil {
+				log.Error("failed to generate secret", sl.Err(err))
+				response.Fail(w, r, response.KindInternal, "")
+				return
+			}
+		}
+
+		sub, err := webhookCreator.CreateWebhook(r.Context(), models.WebhookSubscription{
+			Owner:      username,
+			URL:        req.URL,
+			Secret:     req.Secret,
+			EventTypes: req.EventTypes,
+			Employee:   req.Employee,
+		})
+		if err != nil {
+			log.Error("failed to create webhook", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		log.Info("webhook created", slog.String("username", username), slog.Int64("webhook_id", sub.ID))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, sub)
+	}
+}
+
+func List(log *slog.Logger, webhookLister WebhookLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.List"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		subs, err := webhookLister.ListWebhooks(r.Context(), username)
+		if err != nil {
+			log.Error("failed to list webhooks", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if subs == nil {
+			subs = []models.WebhookSubscription{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, ListResponse{Webhooks: subs})
+	}
+}
+
+func Delete(log *slog.Logger, webhookDeleter WebhookDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.Delete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid webhook id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidWebhookID)
+			return
+		}
+
+		err = webhookDeleter.DeleteWebhook(r.Context(), username, id)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, response.KindWebhookNotFound, "")
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete webhook", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		log.Info("webhook deleted", slog.String("username", username), slog.Int64("webhook_id", id))
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.MessageResponse{Message: i18n.FromContext(r.Context()).T(i18n.MsgWebhookDeleted)})
+	}
+}
+
+// Deliveries отдает историю доставок. Параметры: status (pending, delivered, dead) и limit.
+func Deliveries(log *slog.Logger, deliveryLister DeliveryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.Deliveries"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			log.Error("invalid webhook id", sl.Err(err))
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidWebhookID)
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if status != "" && !slices.Contains([]string{models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead}, status) {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgUnknownDeliveryStatus)
+			return
+		}
+
+		limit := defaultDeliveriesLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit <= 0 || limit > maxDeliveriesLimit {
+				response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidLimit)
+				return
+			}
+		}
+
+		deliveries, err := deliveryLister.ListWebhookDeliveries(r.Context(), username, id, status, limit)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, response.KindWebhookNotFound, "")
+			return
+		}
+		if err != nil {
+			log.Error("failed to list deliveries", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+		if deliveries == nil {
+			deliveries = []models.WebhookDelivery{}
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, DeliveriesResponse{Deliveries: deliveries})
+	}
+}
+
+// Redeliver возвращает доставку из dead-letter в очередь.
+func Redeliver(log *slog.Logger, deliveryRequeuer DeliveryRequeuer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.Redeliver"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidWebhookID)
+			return
+		}
+		deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryID"), 10, 64)
+		if err != nil {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidDeliveryID)
+			return
+		}
+
+		err = deliveryRequeuer.RedeliverWebhook(r.Context(), username, id, deliveryID)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			response.Fail(w, r, response.KindWebhookNotFound, i18n.MsgDeadDeliveryNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("failed to requeue delivery", sl.Err(err))
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.MessageResponse{Message: i18n.FromContext(r.Context()).T(i18n.MsgDeliveryRequeued)})
+	}
+}
+
+func validate(lang i18n.Lang, req CreateRequest) []response.FieldError {
+	var fields []response.FieldError
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fields = append(fields, response.NewFieldError(lang, "url", "url", i18n.MsgFieldURL, "url"))
+	}
+
+	if len(req.EventTypes) == 0 {
+		fields = append(fields, response.NewFieldError(lang, "eventTypes", "required", i18n.MsgFieldRequired, "eventTypes"))
+	}
+	for _, t := range req.EventTypes {
+		if !slices.Contains(events.Types(), t) {
+			fields = append(fields, response.NewFieldError(lang, "eventTypes", "oneof", i18n.MsgFieldUnknownEventType, "eventTypes", t))
+		}
+	}
+
+	return fields
+}
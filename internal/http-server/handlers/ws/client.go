@@ -0,0 +1,227 @@
+package ws
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/notify"
+)
+
+const writeWait = 10 * time.Second
+
+type subscription struct {
+	sub       notify.Subscription
+	cancelled atomic.Bool
+}
+
+// client обслуживает одно соединение. Читает сообщения readLoop,
+// пишет только writeLoop, каждая подписка пересылается своей горутиной.
+type client struct {
+	log      *slog.Logger
+	conn     *websocket.Conn
+	broker   notify.Broker
+	cfg      config.WebSocket
+	username string
+	isAdmin  bool
+	lang     i18n.Lang
+
+	send      chan ServerMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMsg  []byte
+
+	// subs трогает только readLoop
+	subs map[string]*subscription
+	wg   sync.WaitGroup
+}
+
+func newClient(log *slog.Logger, conn *websocket.Conn, broker notify.Broker, cfg config.WebSocket, username string, isAdmin bool, lang i18n.Lang) *client {
+	return &client{
+		log:      log,
+		conn:     conn,
+		broker:   broker,
+		cfg:      cfg,
+		username: username,
+		isAdmin:  isAdmin,
+		lang:     lang,
+		send:     make(chan ServerMessage, cfg.SendBuffer),
+		done:     make(chan struct{}),
+		subs:     make(map[string]*subscription),
+	}
+}
+
+func (c *client) run() {
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.writeLoop()
+	}()
+
+	c.readLoop()
+
+	for channel := range c.subs {
+		c.unsubscribe(channel)
+	}
+	c.close(websocket.CloseNormalClosure, "")
+	c.wg.Wait()
+	<-writerDone
+}
+
+func (c *client) readLoop() {
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+	})
+
+	for {
+		var msg ClientMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				c.log.Warn("websocket read failed", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		switch msg.Type {
+		case MsgSubscribe:
+			c.handleSubscribe(msg)
+		case MsgUnsubscribe:
+			if _, ok := c.subs[msg.Channel]; !ok {
+				c.enqueue(ServerMessage{Type: MsgError, ID: msg.ID, Channel: msg.Channel, Error: c.lang.T(i18n.MsgWSNotSubscribed)})
+				continue
+			}
+			c.unsubscribe(msg.Channel)
+			c.enqueue(ServerMessage{Type: MsgUnsubscribed, ID: msg.ID, Channel: msg.Channel})
+		case MsgPing:
+			c.enqueue(ServerMessage{Type: MsgPong, ID: msg.ID})
+		default:
+			c.enqueue(ServerMessage{Type: MsgError, ID: msg.ID, Error: c.lang.T(i18n.MsgWSUnknownMessageType)})
+		}
+	}
+}
+
+func (c *client) handleSubscribe(msg ClientMessage) {
+	topic, errMsg := c.authorize(msg.Channel)
+	if errMsg != "" {
+		c.log.Warn("subscription denied", slog.String("channel", msg.Channel), slog.String("reason", string(errMsg)))
+		c.enqueue(ServerMessage{Type: MsgError, ID: msg.ID, Channel: msg.Channel, Error: c.lang.T(errMsg)})
+		return
+	}
+
+	if _, ok := c.subs[msg.Channel]; ok {
+		c.enqueue(ServerMessage{Type: MsgSubscribed, ID: msg.ID, Channel: msg.Channel})
+		return
+	}
+	if len(c.subs) >= c.cfg.MaxSubscriptions {
+		c.enqueue(ServerMessage{Type: MsgError, ID: msg.ID, Channel: msg.Channel, Error: c.lang.T(i18n.MsgWSTooManySubscriptions)})
+		return
+	}
+
+	s := &subscription{sub: c.broker.Subscribe(topic)}
+	c.subs[msg.Channel] = s
+	c.enqueue(ServerMessage{Type: MsgSubscribed, ID: msg.ID, Channel: msg.Channel})
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.forward(msg.Channel, s)
+	}()
+}
+
+// authorize переводит канал протокола в канал брокера.
+// Личный баланс доступен только владельцу и администраторам.
+func (c *client) authorize(channel string) (string, i18n.Key) {
+	switch {
+	case channel == ChannelActivity:
+		return notify.TopicActivity, ""
+	case strings.HasPrefix(channel, ChannelBalancePrefix):
+		owner := strings.TrimPrefix(channel, ChannelBalancePrefix)
+		if owner == "" {
+			return "", i18n.MsgWSUnknownChannel
+		}
+		if owner != c.username && !c.isAdmin {
+			return "", i18n.MsgWSAccessDenied
+		}
+		return notify.UserTopic(owner), ""
+	default:
+		return "", i18n.MsgWSUnknownChannel
+	}
+}
+
+func (c *client) unsubscribe(channel string) {
+	s := c.subs[channel]
+	delete(c.subs, channel)
+	s.cancelled.Store(true)
+	s.sub.Close()
+}
+
+func (c *client) forward(channel string, s *subscription) {
+	for n := range s.sub.C() {
+		n = notify.Localize(n, c.lang)
+		c.enqueue(ServerMessage{
+			Type:    MsgEvent,
+			Channel: channel,
+			Event:   n.Type,
+			EventID: n.ID,
+			Data:    n.Data,
+		})
+	}
+
+	if !s.cancelled.Load() {
+		// брокер закрыл подписку: клиент не успевает читать
+		c.close(websocket.CloseTryAgainLater, "slow consumer")
+	}
+}
+
+// enqueue не блокирует: если клиент не вычитывает сообщения и буфер
+// заполнен, соединение закрывается.
+func (c *client) enqueue(msg ServerMessage) {
+	select {
+	case <-c.done:
+	case c.send <- msg:
+	default:
+		c.log.Warn("websocket client is too slow, closing")
+		c.close(websocket.CloseTryAgainLater, "slow consumer")
+	}
+}
+
+func (c *client) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = websocket.FormatCloseMessage(code, text)
+		close(c.done)
+	})
+}
+
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(c.cfg.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.WriteControl(websocket.CloseMessage, c.closeMsg, time.Now().Add(writeWait))
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package ws
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/gorilla/websocket"
+	"github.com/magneless/merch-shop/internal/config"
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/notify"
+)
+
+// Каналы, на которые можно подписаться.
+const (
+	ChannelActivity      = "activity"
+	ChannelBalancePrefix = "balance:"
+)
+
+// Типы сообщений протокола.
+const (
+	MsgSubscribe    = "subscribe"
+	MsgUnsubscribe  = "unsubscribe"
+	MsgPing         = "ping"
+	MsgSubscribed   = "subscribed"
+	MsgUnsubscribed = "unsubscribed"
+	MsgPong         = "pong"
+	MsgEvent        = "event"
+	MsgError        = "error"
+)
+
+const maxMessageSize = 4 << 10
+
+// ClientMessage - сообщение от клиента:
+// {"type": "subscribe", "channel": "balance:anna", "id": "1"}.
+type ClientMessage struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel,omitempty"`
+	ID      string `json:"id,omitempty"`
+}
+
+// ServerMessage - сообщение клиенту. ID повторяет ID запроса клиента,
+// EventID - ID доменного события.
+type ServerMessage struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Event   string `json:"event,omitempty"`
+	EventID int64  `json:"eventId,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// New открывает WebSocket с подписками на общую ленту и личный баланс.
+// Аутентификация та же, что у остального API (mwAuth).
+func New(log *slog.Logger, broker notify.Broker, cfg config.WebSocket, admins []string) http.HandlerFunc {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			return checkOrigin(r, cfg.AllowedOrigins)
+		},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.ws.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+		if !ok {
+			log.Error("failed to get username form context")
+			response.Fail(w, r, response.KindInternal, "")
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade уже ответил клиенту
+			log.Error("failed to upgrade connection", sl.Err(err))
+			return
+		}
+
+		log.Info("websocket connected", slog.String("username", username))
+
+		c := newClient(log.With(slog.String("username", username)), conn, broker, cfg, username, slices.Contains(admins, username), i18n.FromContext(r.Context()))
+		c.run()
+
+		log.Info("websocket disconnected", slog.String("username", username))
+	}
+}
+
+// checkOrigin пропускает запросы без Origin (не браузер), со своего хоста
+// и из списка разрешенных.
+func checkOrigin(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	return slices.Contains(allowed, origin)
+}
@@ -0,0 +1,37 @@
+package mwAdmin
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+)
+
+// New пропускает только пользователей из списка admins.
+// Должен стоять после mwAuth.
+func New(log *slog.Logger, admins []string) func(next http.Handler) http.Handler {
+	log = log.With(slog.String("component", "middleware.admin"))
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			username, ok := r.Context().Value(mwAuth.UsernameKey).(string)
+			if !ok {
+				log.Error("failed to get username form context")
+				response.Fail(w, r, response.KindInternal, "")
+				return
+			}
+
+			if !slices.Contains(admins, username) {
+				log.Warn("access to admin api denied", slog.String("username", username))
+				response.Fail(w, r, response.KindForbidden, "")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,20 @@
+package mwAudit
+
+import (
+	"net/http"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+)
+
+// New кладет в контекст данные запроса для журнала аудита.
+// Должен стоять после middleware.RequestID.
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := audit.WithMeta(r.Context(), audit.MetaFromRequest(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
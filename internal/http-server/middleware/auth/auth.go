@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/go-chi/render"
 	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
 	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
 	"github.com/magneless/merch-shop/internal/lib/logger/sl"
 )
@@ -16,24 +16,31 @@ type ctxKey string
 
 const UsernameKey ctxKey = "username"
 
+// TokenQueryParam - токен в query для WebSocket и EventSource:
+// браузер не дает выставить им заголовок Authorization.
+const TokenQueryParam = "access_token"
+
 func New(log *slog.Logger) func(next http.Handler) http.Handler {
 	log = log.With(slog.String("component", "middleware.authorization"))
 
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" && isStream(r) {
+				if token := r.URL.Query().Get(TokenQueryParam); token != "" {
+					authHeader = "Bearer " + token
+				}
+			}
 			if authHeader == "" {
 				log.Error("authorization header is missed")
-				render.Status(r, http.StatusUnauthorized)
-				render.JSON(w, r, response.ErrorResponse{Error: "authorization header is missed"})
+				response.Fail(w, r, response.KindUnauthorized, i18n.MsgAuthHeaderMissing)
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
 				log.Error("invalid authorization header format")
-				render.Status(r, http.StatusUnauthorized)
-				render.JSON(w, r, response.ErrorResponse{Error: "invalid authorization header format"})
+				response.Fail(w, r, response.KindUnauthorized, i18n.MsgAuthHeaderFormat)
 				return
 			}
 
@@ -42,8 +49,7 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 			username, err := jwt_token.ValidateAccessToken(tokenString)
 			if err != nil {
 				log.Error("error in token validation", sl.Err(err))
-				render.Status(r, http.StatusUnauthorized)
-				render.JSON(w, r, response.ErrorResponse{Error: "invalid or expired access token"})
+				response.Fail(w, r, response.KindUnauthorized, i18n.MsgTokenInvalid)
 				return
 			}
 
@@ -54,3 +60,8 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+func isStream(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
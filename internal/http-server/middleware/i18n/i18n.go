@@ -0,0 +1,23 @@
+package mwI18n
+
+import (
+	"net/http"
+
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+)
+
+// New выбирает язык ответа по Accept-Language и кладет его в контекст.
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+			w.Header().Set("Content-Language", string(lang))
+			w.Header().Add("Vary", "Accept-Language")
+
+			next.ServeHTTP(w, r.WithContext(i18n.WithLang(r.Context(), lang)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
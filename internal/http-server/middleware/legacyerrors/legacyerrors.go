@@ -0,0 +1,19 @@
+package mwLegacyErrors
+
+import (
+	"net/http"
+
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+)
+
+// New переключает ответы с ошибками на старый формат {"error": "..."}
+// для клиентов, которые еще не умеют application/problem+json.
+func New() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(response.WithLegacyErrors(r.Context())))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,164 @@
+package mwOpenAPI
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/magneless/merch-shop/internal/http-server/openapi"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+)
+
+// New проверяет запросы по спецификации. Пути, которых нет в спецификации,
+// пропускаются без проверки. Если validateResponses включен, ответ
+// буферизуется и тоже проверяется: расхождение с документацией
+// превращается в 500, чтобы его было видно в dev-окружении и тестах.
+func New(log *slog.Logger, spec *openapi.Spec, validateResponses bool) func(next http.Handler) http.Handler {
+	log = log.With(slog.String("component", "middleware.openapi"))
+
+	opts := &openapi3filter.Options{
+		// Токен проверяет mwAuth, здесь достаточно описания в спецификации.
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := spec.Router.FindRoute(r)
+			if err != nil {
+				var routeErr *routers.RouteError
+				if !errors.As(err, &routeErr) {
+					log.Error("failed to find route", sl.Err(err))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Обработчики декодируют тело как JSON независимо от заголовка.
+			if r.Header.Get("Content-Type") == "" && r.ContentLength != 0 {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    opts,
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				log.Debug("request does not match spec",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					sl.Err(err),
+				)
+				failRequest(w, r, err)
+				return
+			}
+
+			if !validateResponses || route.Operation.Extensions[openapi.ExtStream] == true {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rec.status,
+				Header:                 w.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+				Options:                opts,
+			})
+			if err != nil {
+				log.Error("response does not match spec",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("path", route.Path),
+					slog.Int("status", rec.status),
+					sl.Err(err),
+				)
+				w.Header().Del("Content-Length")
+				msg, _, _ := strings.Cut(err.Error(), "\n")
+				response.Fail(w, r, response.KindInternal, i18n.MsgResponseSpecMismatch, msg)
+				return
+			}
+
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// failRequest отвечает на ошибку валидатора так же, как обработчики
+// отвечают на ошибки validator, без дампа схемы.
+func failRequest(w http.ResponseWriter, r *http.Request, err error) {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequest)
+		return
+	}
+
+	lang := i18n.FromContext(r.Context())
+
+	var schemaErr *openapi3.SchemaError
+	hasSchemaErr := errors.As(reqErr.Err, &schemaErr)
+
+	if reqErr.Parameter != nil {
+		rule := "invalid"
+		if hasSchemaErr {
+			rule = schemaErr.SchemaField
+		}
+		response.FailValidation(w, r, response.NewFieldError(
+			lang, reqErr.Parameter.Name, rule, i18n.MsgParameterNotValid, reqErr.Parameter.Name,
+		))
+		return
+	}
+
+	if hasSchemaErr {
+		field := strings.Join(schemaErr.JSONPointer(), ".")
+		if field == "" {
+			response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBodyReason, schemaErr.Reason)
+			return
+		}
+
+		key := i18n.MsgFieldNotValid
+		if schemaErr.SchemaField == "required" {
+			key = i18n.MsgFieldRequired
+		}
+		response.FailValidation(w, r, response.NewFieldError(lang, field, schemaErr.SchemaField, key, field))
+		return
+	}
+
+	if reqErr.Reason != "" {
+		response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBodyReason, reqErr.Reason)
+		return
+	}
+
+	response.Fail(w, r, response.KindBadRequest, i18n.MsgInvalidRequestBody)
+}
+
+// recorder придерживает ответ обработчика до окончания проверки.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
@@ -0,0 +1,88 @@
+// Package openapi содержит спецификацию REST API и обработчики для ее публикации.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// ExtStream помечает операции с потоковым ответом (SSE, WebSocket),
+// ответы которых не буферизуются и не проверяются.
+const ExtStream = "x-stream"
+
+// Spec - разобранная спецификация и маршрутизатор по ее путям.
+type Spec struct {
+	Doc    *openapi3.T
+	Router routers.Router
+	JSON   []byte
+}
+
+// Load разбирает встроенную спецификацию и проверяет ее корректность.
+func Load() (*Spec, error) {
+	const op = "openapi.Load"
+
+	doc, err := openapi3.NewLoader().LoadFromData(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("%s: invalid spec: %w", op, err)
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	body, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Spec{Doc: doc, Router: router, JSON: body}, nil
+}
+
+// Handler отдает спецификацию в формате JSON.
+func Handler(spec *Spec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec.JSON)
+	}
+}
+
+// Docs отдает страницу Swagger UI, которая читает specURL.
+func Docs(specURL string) http.HandlerFunc {
+	page := fmt.Sprintf(docsPage, specURL)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>merch-shop API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`
@@ -1,21 +1,50 @@
 package router
 
 import (
+	"context"
 	"log/slog"
+	"net/http"
+	"slices"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/approvals"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/auditlog"
 	auth "github.com/magneless/merch-shop/internal/http-server/handlers/auth"
 	"github.com/magneless/merch-shop/internal/http-server/handlers/buy"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/coinrequests"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/employees"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/fraud"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/grants"
 	"github.com/magneless/merch-shop/internal/http-server/handlers/info"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/loglevel"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/merch"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/orders"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/promotions"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/scheduled"
 	"github.com/magneless/merch-shop/internal/http-server/handlers/send"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/sse"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/treasury"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/webhooks"
+	"github.com/magneless/merch-shop/internal/http-server/handlers/ws"
+	mwAdmin "github.com/magneless/merch-shop/internal/http-server/middleware/admin"
+	mwAudit "github.com/magneless/merch-shop/internal/http-server/middleware/audit"
 	mwAuth "github.com/magneless/merch-shop/internal/http-server/middleware/auth"
+	mwI18n "github.com/magneless/merch-shop/internal/http-server/middleware/i18n"
+	mwLegacyErrors "github.com/magneless/merch-shop/internal/http-server/middleware/legacyerrors"
 	mwLogger "github.com/magneless/merch-shop/internal/http-server/middleware/logger"
+	mwOpenAPI "github.com/magneless/merch-shop/internal/http-server/middleware/openapi"
+	"github.com/magneless/merch-shop/internal/http-server/openapi"
+	"github.com/magneless/merch-shop/internal/lib/api/response"
 	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/notify"
 )
 
 type Auth interface {
-	GetUser(username, passwordHash string) error
+	GetUser(ctx context.Context, username, passwordHash string, canRegister bool) error
 }
 
 type Info interface {
@@ -23,38 +52,273 @@ type Info interface {
 	GetReceivedTransactions(userID int, toUsername string) ([]models.CoinTransaction, error)
 	GetSentTransactions(userID int, fromUsername string) ([]models.CoinTransaction, error)
 	GetBalanceAndId(username string) (int, int, error)
+	GetExpiringCoins(userID int) ([]models.ExpiringCoins, error)
+	GetOpenOrders(userID int) ([]models.Order, error)
 }
 
 type Buy interface {
-	PurchaseMerch(username, merchName string, quantity int) error
+	ListMerch(ctx context.Context) ([]models.MerchItem, error)
+	PurchaseMerch(ctx context.Context, username string, p models.Purchase) (models.Order, error)
+}
+
+type Approvals interface {
+	ListPendingApprovals(ctx context.Context, approver string, admin bool) ([]models.Order, error)
+	ApproveOrder(ctx context.Context, approver string, admin bool, id int64) (models.Order, error)
+	RejectOrder(ctx context.Context, approver string, admin bool, id int64, reason string) (models.Order, error)
 }
 
 type Send interface {
-	SendCoins(senderUsername, receiverUsername string, amount int) error	
+	SendCoins(ctx context.Context, senderUsername, receiverUsername string, amount int) error
+	SendCoinsBulk(ctx context.Context, batch models.TransferBatch) (models.TransferBatch, error)
+}
+
+type Audit interface {
+	WriteAudit(ctx context.Context, entry models.AuditEntry) error
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, owner string) ([]models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, owner string, id int64) error
+	ListWebhookDeliveries(ctx context.Context, owner string, subID int64, status string, limit int) ([]models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, owner string, subID, deliveryID int64) error
+}
+
+type CoinRequests interface {
+	CreateCoinRequest(ctx context.Context, requester, payer string, amount int, note string, ttl time.Duration) (models.CoinRequest, error)
+	ListCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error)
+	AcceptCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error)
+	DeclineCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error)
+}
+
+type ScheduledTransfers interface {
+	CreateScheduledTransfer(ctx context.Context, st models.ScheduledTransfer) (models.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, owner string, id int64) (models.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update func(st *models.ScheduledTransfer) error) (models.ScheduledTransfer, error)
+	DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error
+}
+
+type Treasury interface {
+	UpdateEmployee(ctx context.Context, actor, username string, upd models.EmployeeUpdate) (models.Employee, error)
+	TreasuryBalance(ctx context.Context) (int, error)
+	MintTreasury(ctx context.Context, actor string, amount int, note string) (int, error)
+}
+
+type Limits interface {
+	GetEmployeeLimits(ctx context.Context, username string) (models.EmployeeLimits, error)
+	SetTransferLimits(ctx context.Context, actor, username string, override models.TransferLimitOverride) (models.EmployeeLimits, error)
+}
+
+type Grants interface {
+	CreateGrantCampaign(ctx context.Context, c models.GrantCampaign) (models.GrantCampaign, error)
+	ListGrantCampaigns(ctx context.Context) ([]models.GrantCampaign, error)
+	GetGrantCampaign(ctx context.Context, id int64) (models.GrantCampaign, error)
+	DeleteGrantCampaign(ctx context.Context, actor string, id int64) error
+	PreviewGrantCampaign(ctx context.Context, id int64) (models.GrantPreview, error)
+	ExecuteGrantCampaign(ctx context.Context, actor string, id int64) (models.GrantRun, error)
+	GrantCampaignReport(ctx context.Context, id int64) (models.GrantReport, error)
+}
+
+type Orders interface {
+	ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+	GetOrder(ctx context.Context, id int64) (models.Order, error)
+	AdvanceOrder(ctx context.Context, actor string, id int64, status, note string) (models.Order, error)
+	CancelOrder(ctx context.Context, username string, id int64) (models.Order, error)
+	RequestReturn(ctx context.Context, username string, id int64, reason string) (models.Order, error)
+	ResolveReturn(ctx context.Context, actor string, id int64, accept bool, note string) (models.Order, error)
+}
+
+type Variants interface {
+	ListMerchVariants(ctx context.Context, item string) ([]models.MerchVariant, error)
+	CreateMerchVariant(ctx context.Context, actor, item string, v models.MerchVariant) (models.MerchVariant, error)
+	UpdateMerchVariant(ctx context.Context, actor, item string, id int, stock *int, active bool) (models.MerchVariant, error)
+	SetPurchaseRule(ctx context.Context, actor, item string, rule models.PurchaseRule) (models.PurchaseRule, error)
+}
+
+type Promotions interface {
+	CreatePromotion(ctx context.Context, p models.Promotion) (models.Promotion, error)
+	ListPromotions(ctx context.Context) ([]models.Promotion, error)
+	GetPromotion(ctx context.Context, id int64) (models.Promotion, error)
+	DisablePromotion(ctx context.Context, actor string, id int64) (models.Promotion, error)
+}
+
+type Fraud interface {
+	ListFraudFlags(ctx context.Context, filter models.FraudFlagFilter) ([]models.FraudFlag, error)
+	ResolveFraudFlag(ctx context.Context, actor string, id int64, status string) (models.FraudFlag, error)
+}
+
+type Events interface {
+	EventsSince(ctx context.Context, username string, afterID int64, limit int) ([]events.Event, error)
+	LateEvents(ctx context.Context, username string, lastID int64, window time.Duration, limit int) ([]events.Event, error)
 }
 
 type Repository interface {
 	Auth
 	Info
 	Buy
+	Approvals
+	Orders
+	Variants
 	Send
+	Audit
+	Webhooks
+	CoinRequests
+	ScheduledTransfers
+	Treasury
+	Limits
+	Grants
+	Promotions
+	Fraud
+	Events
 }
 
-func New(log *slog.Logger, repo Repository) *chi.Mux {
+type Options struct {
+	LogLevel *slog.LevelVar
+	Admins   []string
+	// Staff вместе с Admins допускаются к /api/staff.
+	Staff     []string
+	Broker    notify.Broker
+	Heartbeat time.Duration
+	WebSocket config.WebSocket
+	// CoinRequestTTL - срок ответа на просьбу о переводе.
+	CoinRequestTTL time.Duration
+	// FraudScanner запускает полную проверку переводов по запросу администратора.
+	FraudScanner fraud.Scanner
+	OpenAPI      *openapi.Spec
+	// ValidateRequests и ValidateResponses включают проверку по OpenAPI.
+	ValidateRequests  bool
+	ValidateResponses bool
+	LegacyErrors      bool
+}
+
+func New(log *slog.Logger, repo Repository, opts Options) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
+	r.Use(mwI18n.New())
+	if opts.LegacyErrors {
+		r.Use(mwLegacyErrors.New())
+	}
 	r.Use(mwLogger.New(log))
+	r.Use(mwAudit.New())
 	r.Use(middleware.Recoverer)
+	if opts.ValidateRequests {
+		r.Use(mwOpenAPI.New(log, opts.OpenAPI, opts.ValidateResponses))
+	}
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		response.Fail(w, r, response.KindNotFound, "")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		response.Fail(w, r, response.KindMethodNotAllowed, "")
+	})
 
 	r.Post("/api/auth", auth.New(log, repo))
+	r.Get("/api/openapi.json", openapi.Handler(opts.OpenAPI))
+	r.Get("/api/docs", openapi.Docs("/api/openapi.json"))
 
 	r.Route("/api", func(r chi.Router) {
 		r.Use(mwAuth.New(log))
 
 		r.Get("/info", info.New(log, repo))
 		r.Post("/sendCoin", send.New(log, repo))
+		r.Post("/sendCoin/bulk", send.Bulk(log, repo))
+		r.Get("/merch", merch.New(log, repo))
 		r.Get("/buy/{item}", buy.New(log, repo))
+		r.Post("/buy/{item}/gift", buy.Gift(log, repo))
+		r.Get("/events", sse.New(log, opts.Broker, repo, opts.Heartbeat))
+		r.Get("/ws", ws.New(log, opts.Broker, opts.WebSocket, opts.Admins))
+
+		r.Route("/approvals", func(r chi.Router) {
+			r.Get("/", approvals.List(log, repo, opts.Admins))
+			r.Post("/{id}/approve", approvals.Approve(log, repo, opts.Admins))
+			r.Post("/{id}/reject", approvals.Reject(log, repo, opts.Admins))
+		})
+
+		r.Get("/orders", orders.List(log, repo))
+		r.Get("/orders/{id}", orders.Get(log, repo))
+		r.Post("/orders/{id}/cancel", orders.Cancel(log, repo))
+		r.Post("/orders/{id}/return", orders.RequestReturn(log, repo))
+
+		r.Route("/staff", func(r chi.Router) {
+			r.Use(mwAdmin.New(log, slices.Concat(opts.Staff, opts.Admins)))
+
+			r.Get("/orders", orders.StaffList(log, repo))
+			r.Get("/orders/{id}", orders.StaffGet(log, repo))
+			r.Post("/orders/{id}/status", orders.Advance(log, repo))
+			r.Post("/orders/{id}/return/accept", orders.AcceptReturn(log, repo))
+			r.Post("/orders/{id}/return/reject", orders.RejectReturn(log, repo))
+			r.Get("/merch/{item}/variants", merch.Variants(log, repo))
+			r.Post("/merch/{item}/variants", merch.CreateVariant(log, repo))
+			r.Put("/merch/{item}/variants/{id}", merch.UpdateVariant(log, repo))
+			r.Put("/merch/{item}/rule", merch.SetRule(log, repo))
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Post("/", webhooks.Create(log, repo, opts.Admins))
+			r.Get("/", webhooks.List(log, repo))
+			r.Delete("/{id}", webhooks.Delete(log, repo))
+			r.Get("/{id}/deliveries", webhooks.Deliveries(log, repo))
+			r.Post("/{id}/deliveries/{deliveryID}/redeliver", webhooks.Redeliver(log, repo))
+		})
+
+		r.Route("/coin-requests", func(r chi.Router) {
+			r.Post("/", coinrequests.Create(log, repo, opts.CoinRequestTTL))
+			r.Get("/", coinrequests.List(log, repo))
+			r.Post("/{id}/accept", coinrequests.Accept(log, repo))
+			r.Post("/{id}/decline", coinrequests.Decline(log, repo))
+		})
+
+		r.Route("/scheduled-transfers", func(r chi.Router) {
+			r.Post("/", scheduled.Create(log, repo))
+			r.Get("/", scheduled.List(log, repo))
+			r.Get("/{id}", scheduled.Get(log, repo))
+			r.Patch("/{id}", scheduled.Update(log, repo))
+			r.Delete("/{id}", scheduled.Delete(log, repo))
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(mwAdmin.New(log, opts.Admins))
+
+			r.Get("/log-level", loglevel.Get(log, opts.LogLevel))
+			r.Put("/log-level", loglevel.Set(log, opts.LogLevel, repo))
+
+			r.Get("/audit", auditlog.List(log, repo))
+			r.Get("/audit/export", auditlog.Export(log, repo))
+
+			r.Patch("/employees/{username}", employees.Update(log, repo))
+			r.Get("/employees/{username}/limits", employees.Limits(log, repo))
+			r.Put("/employees/{username}/limits", employees.SetLimits(log, repo))
+
+			r.Get("/treasury", treasury.Get(log, repo))
+			r.Post("/treasury/mint", treasury.Mint(log, repo))
+
+			r.Route("/grants", func(r chi.Router) {
+				r.Post("/", grants.Create(log, repo))
+				r.Get("/", grants.List(log, repo))
+				r.Get("/{id}", grants.Get(log, repo))
+				r.Delete("/{id}", grants.Delete(log, repo))
+				r.Get("/{id}/preview", grants.Preview(log, repo))
+				r.Post("/{id}/execute", grants.Execute(log, repo))
+				r.Get("/{id}/report", grants.Report(log, repo))
+			})
+
+			r.Route("/promotions", func(r chi.Router) {
+				r.Post("/", promotions.Create(log, repo))
+				r.Get("/", promotions.List(log, repo))
+				r.Get("/{id}", promotions.Get(log, repo))
+				r.Post("/{id}/disable", promotions.Disable(log, repo))
+			})
+
+			r.Route("/fraud", func(r chi.Router) {
+				r.Get("/flags", fraud.List(log, repo))
+				r.Post("/flags/{id}/dismiss", fraud.Dismiss(log, repo))
+				r.Post("/flags/{id}/confirm", fraud.Confirm(log, repo))
+				r.Post("/scan", fraud.Scan(log, opts.FraudScanner))
+			})
+		})
 	})
 
 	return r
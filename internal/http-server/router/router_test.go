@@ -0,0 +1,289 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/magneless/merch-shop/internal/http-server/openapi"
+	jwt_token "github.com/magneless/merch-shop/internal/lib/jwt"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+func loadSpec(t *testing.T) *openapi.Spec {
+	t.Helper()
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	return spec
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// TestRoutesMatchSpec ловит расхождение маршрутов chi и путей спецификации в обе стороны.
+func TestRoutesMatchSpec(t *testing.T) {
+	spec := loadSpec(t)
+
+	routes := map[string]bool{}
+	err := chi.Walk(New(discardLogger(), stubRepo{}, Options{OpenAPI: spec}),
+		func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			if route != "/" {
+				route = strings.TrimSuffix(route, "/")
+			}
+			routes[method+" "+route] = true
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range spec.Doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for _, route := range sortedKeys(routes) {
+		if !documented[route] {
+			t.Errorf("route %s is not documented in openapi.yaml", route)
+		}
+	}
+	for _, op := range sortedKeys(documented) {
+		if !routes[op] {
+			t.Errorf("openapi.yaml documents %s, but there is no such route", op)
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// stubRepo отвечает правдоподобными данными. Методы, которые тест не вызывает,
+// достаются от nil-интерфейса и паникуют.
+type stubRepo struct {
+	Repository
+}
+
+var (
+	stubTime  = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	stubStock = 5
+)
+
+func stubOrder(id int64, status string) models.Order {
+	o := models.Order{
+		ID:         id,
+		Username:   "anna",
+		Item:       "hoody",
+		Size:       "M",
+		Color:      "black",
+		Quantity:   1,
+		Price:      300,
+		Discount:   30,
+		Total:      270,
+		Status:     status,
+		CreatedAt:  stubTime,
+		UpdatedAt:  stubTime,
+		Promotions: []string{"spring"},
+	}
+	if status == models.OrderPendingApproval {
+		approveBy := stubTime.Add(48 * time.Hour)
+		o.Policy = "expensive"
+		o.ApproveBy = &approveBy
+	}
+	return o
+}
+
+// GetUser: newcomer - нового аккаунта нет, остальные уже зарегистрированы.
+func (stubRepo) GetUser(_ context.Context, username, _ string, canRegister bool) error {
+	if username == "newcomer" && !canRegister {
+		return storage.ErrRegistrationRejected
+	}
+	return nil
+}
+
+func (stubRepo) GetBalanceAndId(string) (int, int, error) { return 1, 1000, nil }
+
+func (stubRepo) GetInventory(int) ([]models.InventoryItem, error) {
+	return []models.InventoryItem{{Type: "cup", Size: "", Color: "", Quantity: 2}}, nil
+}
+
+func (stubRepo) GetReceivedTransactions(int, string) ([]models.CoinTransaction, error) {
+	return []models.CoinTransaction{{FromUser: "bob", ToUser: "anna", Amount: 10}}, nil
+}
+
+func (stubRepo) GetSentTransactions(int, string) ([]models.CoinTransaction, error) {
+	return []models.CoinTransaction{}, nil
+}
+
+func (stubRepo) GetExpiringCoins(int) ([]models.ExpiringCoins, error) {
+	return []models.ExpiringCoins{{Amount: 100, ExpiresOn: "2026-04-01", Message: "100 coins expire on 2026-04-01"}}, nil
+}
+
+func (stubRepo) GetOpenOrders(int) ([]models.Order, error) {
+	return []models.Order{stubOrder(1, models.OrderPlaced)}, nil
+}
+
+func (stubRepo) ListMerch(context.Context) ([]models.MerchItem, error) {
+	limit := 2
+	return []models.MerchItem{{
+		Name:     "hoody",
+		Category: "hoodies",
+		Price:    300,
+		Variants: []models.MerchVariant{{ID: 1, Size: "M", Color: "black", Stock: &stubStock, Active: true}},
+		Rule:     &models.PurchaseRule{MaxQuantity: &limit, Period: models.PeriodMonth},
+	}}, nil
+}
+
+func (stubRepo) PurchaseMerch(_ context.Context, _ string, p models.Purchase) (models.Order, error) {
+	switch p.Item {
+	case "unknown":
+		return models.Order{}, fmt.Errorf("repository.PurchaseMerch: %w", storage.ErrMerchNotFound)
+	case "macbook":
+		return stubOrder(2, models.OrderPendingApproval), nil
+	}
+
+	o := stubOrder(3, models.OrderPlaced)
+	o.Item, o.Recipient, o.Message = p.Item, p.Recipient, p.Message
+	return o, nil
+}
+
+func (stubRepo) SendCoins(context.Context, string, string, int) error { return nil }
+
+func (stubRepo) ListOrders(context.Context, models.OrderFilter) ([]models.Order, error) {
+	return []models.Order{stubOrder(1, models.OrderPlaced), stubOrder(2, models.OrderPendingApproval)}, nil
+}
+
+func (stubRepo) GetOrder(_ context.Context, id int64) (models.Order, error) {
+	if id != 1 {
+		return models.Order{}, fmt.Errorf("repository.GetOrder: %w", storage.ErrOrderNotFound)
+	}
+	o := stubOrder(1, models.OrderPlaced)
+	o.History = []models.OrderTransition{{Status: models.OrderPlaced, ChangedBy: "anna", ChangedAt: stubTime}}
+	return o, nil
+}
+
+func (stubRepo) CancelOrder(context.Context, string, int64) (models.Order, error) {
+	return stubOrder(1, models.OrderCancelled), nil
+}
+
+func (stubRepo) ListPendingApprovals(context.Context, string, bool) ([]models.Order, error) {
+	return []models.Order{stubOrder(2, models.OrderPendingApproval)}, nil
+}
+
+func (stubRepo) ListPromotions(context.Context) ([]models.Promotion, error) {
+	return []models.Promotion{{ID: 1, Name: "spring", Kind: models.PromoPercent, Value: 10, Active: true, CreatedBy: "admin", CreatedAt: stubTime}}, nil
+}
+
+func (stubRepo) CreatePromotion(_ context.Context, p models.Promotion) (models.Promotion, error) {
+	p.ID, p.Active, p.CreatedAt = 2, true, stubTime
+	return p, nil
+}
+
+func (stubRepo) ListCoinRequests(context.Context, models.CoinRequestFilter) ([]models.CoinRequest, error) {
+	return []models.CoinRequest{{
+		ID:        1,
+		Requester: "bob",
+		Payer:     "anna",
+		Amount:    50,
+		Status:    models.CoinRequestPending,
+		CreatedAt: stubTime,
+		ExpiresAt: stubTime.Add(72 * time.Hour),
+	}}, nil
+}
+
+func (stubRepo) TreasuryBalance(context.Context) (int, error) { return 100000, nil }
+
+func (stubRepo) ListMerchVariants(context.Context, string) ([]models.MerchVariant, error) {
+	return []models.MerchVariant{{ID: 1, Size: "M", Color: "black", Stock: &stubStock, Active: true}}, nil
+}
+
+// TestResponsesMatchSpec прогоняет настоящие обработчики через проверку ответов
+// по спецификации: расхождение превращается в 500.
+func TestResponsesMatchSpec(t *testing.T) {
+	spec := loadSpec(t)
+	handler := New(discardLogger(), stubRepo{}, Options{
+		Admins:            []string{"admin"},
+		OpenAPI:           spec,
+		ValidateRequests:  true,
+		ValidateResponses: true,
+	})
+
+	tests := []struct {
+		method, path, body string
+		user               string
+		want               int
+	}{
+		{method: http.MethodPost, path: "/api/auth", body: `{"username":"anna","password":"secret123"}`, want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/auth", body: `{"username":"Bo Li","password":"weak"}`, want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/auth", body: `{"username":"newcomer","password":"weak"}`, want: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/api/auth", body: `{"username":"newcomer","password":""}`, want: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/api/info", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/info", want: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/api/merch", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/buy/cup", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/buy/macbook?size=M&promo=spring", user: "anna", want: http.StatusAccepted},
+		{method: http.MethodGet, path: "/api/buy/unknown", user: "anna", want: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/buy/cup/gift", body: `{"toUser":"bob","message":"С днем рождения!"}`, user: "anna", want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/buy/cup/gift", body: `{"toUser":"anna"}`, user: "anna", want: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/api/sendCoin", body: `{"toUser":"bob","amount":10}`, user: "anna", want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/sendCoin", body: `{"toUser":"anna","amount":10}`, user: "anna", want: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/api/orders", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/orders/1", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/orders/9", user: "anna", want: http.StatusNotFound},
+		{method: http.MethodPost, path: "/api/orders/1/cancel", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/approvals", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/coin-requests", user: "anna", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/staff/orders", user: "admin", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/staff/orders", user: "anna", want: http.StatusForbidden},
+		{method: http.MethodGet, path: "/api/staff/merch/hoody/variants", user: "admin", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/admin/treasury", user: "admin", want: http.StatusOK},
+		{method: http.MethodGet, path: "/api/admin/promotions", user: "admin", want: http.StatusOK},
+		{method: http.MethodPost, path: "/api/admin/promotions", body: `{"name":"welcome","code":"WELCOME","kind":"fixed","value":50}`, user: "admin", want: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.user != "" {
+				token, err := jwt_token.GenerateAccessToken(tt.user)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
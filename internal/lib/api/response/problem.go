@@ -0,0 +1,305 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// ContentTypeProblem - тип ответа с ошибкой по RFC 7807.
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemTypeBase - префикс URI типов ошибок. Типы описаны в README.
+const ProblemTypeBase = "/problems/"
+
+// Problem - тело ошибки в формате RFC 7807.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Limit, Max и ResetsAt заполняются для transfer-limit и purchase-limit: какой лимит
+	// (для покупок - период) превышен, его значение и когда он сбросится.
+	Limit    string     `json:"limit,omitempty"`
+	Max      int        `json:"max,omitempty"`
+	ResetsAt *time.Time `json:"resetsAt,omitempty"`
+	// ReleaseAt - когда товар поступит в продажу, для not-released.
+	ReleaseAt *time.Time `json:"releaseAt,omitempty"`
+}
+
+// Kind - класс ошибки: тип, ключ заголовка и HTTP-статус.
+type Kind struct {
+	Type   string
+	Title  i18n.Key
+	Status int
+}
+
+func newKind(slug string, title i18n.Key, status int) Kind {
+	return Kind{Type: ProblemTypeBase + slug, Title: title, Status: status}
+}
+
+var (
+	KindBadRequest          = newKind("bad-request", i18n.MsgTitleBadRequest, http.StatusBadRequest)
+	KindValidation          = newKind("validation-error", i18n.MsgTitleValidation, http.StatusBadRequest)
+	KindUnauthorized        = newKind("unauthorized", i18n.MsgTitleUnauthorized, http.StatusUnauthorized)
+	KindInvalidCredentials  = newKind("invalid-credentials", i18n.MsgTitleInvalidCredentials, http.StatusUnauthorized)
+	KindForbidden           = newKind("forbidden", i18n.MsgTitleForbidden, http.StatusForbidden)
+	KindNotFound            = newKind("not-found", i18n.MsgTitleNotFound, http.StatusNotFound)
+	KindMethodNotAllowed    = newKind("method-not-allowed", i18n.MsgTitleMethodNotAllowed, http.StatusMethodNotAllowed)
+	KindUserNotFound        = newKind("user-not-found", i18n.MsgTitleUserNotFound, http.StatusNotFound)
+	KindMerchNotFound       = newKind("merch-not-found", i18n.MsgTitleMerchNotFound, http.StatusNotFound)
+	KindWebhookNotFound     = newKind("webhook-not-found", i18n.MsgTitleWebhookNotFound, http.StatusNotFound)
+	KindCoinRequestNotFound = newKind("coin-request-not-found", i18n.MsgTitleCoinRequestNotFound, http.StatusNotFound)
+	KindCoinRequestResolved = newKind("coin-request-resolved", i18n.MsgTitleCoinRequestResolved, http.StatusConflict)
+	KindCoinRequestExpired  = newKind("coin-request-expired", i18n.MsgTitleCoinRequestExpired, http.StatusConflict)
+	KindScheduleNotFound    = newKind("schedule-not-found", i18n.MsgTitleScheduleNotFound, http.StatusNotFound)
+	KindGrantNotFound       = newKind("grant-not-found", i18n.MsgTitleGrantNotFound, http.StatusNotFound)
+	KindGrantExecuted       = newKind("grant-executed", i18n.MsgTitleGrantExecuted, http.StatusConflict)
+	KindTransferLimit       = newKind("transfer-limit", i18n.MsgTitleTransferLimit, http.StatusConflict)
+	KindTransfersHeld       = newKind("transfers-held", i18n.MsgTitleTransfersHeld, http.StatusForbidden)
+	KindFraudFlagNotFound   = newKind("fraud-flag-not-found", i18n.MsgTitleFraudFlagNotFound, http.StatusNotFound)
+	KindFraudFlagResolved   = newKind("fraud-flag-resolved", i18n.MsgTitleFraudFlagResolved, http.StatusConflict)
+	KindOrderNotFound       = newKind("order-not-found", i18n.MsgTitleOrderNotFound, http.StatusNotFound)
+	KindOrderResolved       = newKind("order-resolved", i18n.MsgTitleOrderResolved, http.StatusConflict)
+	KindOrderExpired        = newKind("order-expired", i18n.MsgTitleOrderExpired, http.StatusConflict)
+	KindOrderTransition     = newKind("order-transition", i18n.MsgTitleOrderTransition, http.StatusConflict)
+	KindCancelWindowClosed  = newKind("cancel-window-closed", i18n.MsgTitleCancelWindowClosed, http.StatusConflict)
+	KindVariantNotFound     = newKind("variant-not-found", i18n.MsgTitleVariantNotFound, http.StatusNotFound)
+	KindVariantRequired     = newKind("variant-required", i18n.MsgTitleVariantRequired, http.StatusBadRequest)
+	KindVariantExists       = newKind("variant-exists", i18n.MsgTitleVariantExists, http.StatusConflict)
+	KindPromoNotFound       = newKind("promo-not-found", i18n.MsgTitlePromoNotFound, http.StatusNotFound)
+	KindPromoNotApplicable  = newKind("promo-not-applicable", i18n.MsgTitlePromoNotApplicable, http.StatusConflict)
+	KindPromoExhausted      = newKind("promo-exhausted", i18n.MsgTitlePromoExhausted, http.StatusConflict)
+	KindPromoExists         = newKind("promo-exists", i18n.MsgTitlePromoExists, http.StatusConflict)
+	KindPurchaseLimit       = newKind("purchase-limit", i18n.MsgTitlePurchaseLimit, http.StatusConflict)
+	KindNotReleased         = newKind("not-released", i18n.MsgTitleNotReleased, http.StatusConflict)
+	KindOutOfStock          = newKind("out-of-stock", i18n.MsgTitleOutOfStock, http.StatusConflict)
+	KindUserExists          = newKind("user-exists", i18n.MsgTitleUserExists, http.StatusConflict)
+	KindInsufficientBalance = newKind("insufficient-balance", i18n.MsgTitleInsufficientBalance, http.StatusConflict)
+	KindInternal            = newKind("internal", i18n.MsgTitleInternal, http.StatusInternalServerError)
+)
+
+// KindOf сопоставляет ошибку хранилища с классом ответа.
+// Неизвестные ошибки считаются внутренними.
+func KindOf(err error) Kind {
+	switch {
+	case errors.Is(err, storage.ErrWrongPassword):
+		return KindInvalidCredentials
+	case errors.Is(err, storage.ErrRegistrationRejected):
+		return KindBadRequest
+	case errors.Is(err, storage.ErrUserNotFound):
+		return KindUserNotFound
+	case errors.Is(err, storage.ErrMerchNotFound):
+		return KindMerchNotFound
+	case errors.Is(err, storage.ErrWebhookNotFound):
+		return KindWebhookNotFound
+	case errors.Is(err, storage.ErrCoinRequestNotFound):
+		return KindCoinRequestNotFound
+	case errors.Is(err, storage.ErrCoinRequestResolved):
+		return KindCoinRequestResolved
+	case errors.Is(err, storage.ErrCoinRequestExpired):
+		return KindCoinRequestExpired
+	case errors.Is(err, storage.ErrScheduleNotFound):
+		return KindScheduleNotFound
+	case errors.Is(err, storage.ErrGrantNotFound):
+		return KindGrantNotFound
+	case errors.Is(err, storage.ErrGrantExecuted):
+		return KindGrantExecuted
+	case errors.Is(err, storage.ErrLimitExceeded):
+		return KindTransferLimit
+	case errors.Is(err, storage.ErrTransfersHeld):
+		return KindTransfersHeld
+	case errors.Is(err, storage.ErrFraudFlagNotFound):
+		return KindFraudFlagNotFound
+	case errors.Is(err, storage.ErrFraudFlagResolved):
+		return KindFraudFlagResolved
+	case errors.Is(err, storage.ErrOrderNotFound):
+		return KindOrderNotFound
+	case errors.Is(err, storage.ErrOrderResolved):
+		return KindOrderResolved
+	case errors.Is(err, storage.ErrOrderExpired):
+		return KindOrderExpired
+	case errors.Is(err, storage.ErrOrderTransition):
+		return KindOrderTransition
+	case errors.Is(err, storage.ErrCancelWindowClosed):
+		return KindCancelWindowClosed
+	case errors.Is(err, storage.ErrVariantNotFound):
+		return KindVariantNotFound
+	case errors.Is(err, storage.ErrVariantRequired):
+		return KindVariantRequired
+	case errors.Is(err, storage.ErrVariantExists):
+		return KindVariantExists
+	case errors.Is(err, storage.ErrPromoNotFound):
+		return KindPromoNotFound
+	case errors.Is(err, storage.ErrPromoNotApplicable):
+		return KindPromoNotApplicable
+	case errors.Is(err, storage.ErrPromoExhausted):
+		return KindPromoExhausted
+	case errors.Is(err, storage.ErrPromoExists):
+		return KindPromoExists
+	case errors.Is(err, storage.ErrPurchaseLimit):
+		return KindPurchaseLimit
+	case errors.Is(err, storage.ErrNotReleased):
+		return KindNotReleased
+	case errors.Is(err, storage.ErrOutOfStock):
+		return KindOutOfStock
+	case errors.Is(err, storage.ErrNotApprover):
+		return KindForbidden
+	case errors.Is(err, storage.ErrUserExists):
+		return KindUserExists
+	case errors.Is(err, storage.ErrInsufficientBalance):
+		return KindInsufficientBalance
+	default:
+		return KindInternal
+	}
+}
+
+// Fail отвечает ошибкой класса kind. detail - ключ сообщения, может быть пустым.
+func Fail(w http.ResponseWriter, r *http.Request, kind Kind, detail i18n.Key, args ...any) {
+	msg := ""
+	if detail != "" {
+		msg = i18n.FromContext(r.Context()).T(detail, args...)
+	}
+	write(w, r, kind, msg, nil)
+}
+
+// FailError отвечает ошибкой по ее типу (см. KindOf). *i18n.Error считается
+// ошибкой ввода и отдается клиенту, текст внутренних ошибок не отдается.
+func FailError(w http.ResponseWriter, r *http.Request, err error) {
+	var msgErr *i18n.Error
+	if errors.As(err, &msgErr) {
+		Fail(w, r, KindBadRequest, msgErr.Key, msgErr.Args...)
+		return
+	}
+
+	var limitErr *storage.LimitError
+	if errors.As(err, &limitErr) {
+		failLimit(w, r, limitErr)
+		return
+	}
+
+	var purchaseErr *storage.PurchaseLimitError
+	if errors.As(err, &purchaseErr) {
+		failPurchaseLimit(w, r, purchaseErr)
+		return
+	}
+
+	var releaseErr *storage.NotReleasedError
+	if errors.As(err, &releaseErr) {
+		detail := i18n.FromContext(r.Context()).T(i18n.MsgNotReleased, releaseErr.ReleaseAt.UTC().Format(time.RFC3339))
+		write(w, r, KindNotReleased, detail, nil, func(p *Problem) {
+			p.ReleaseAt = &releaseErr.ReleaseAt
+		})
+		return
+	}
+
+	write(w, r, KindOf(err), "", nil)
+}
+
+var limitMessages = map[string]i18n.Key{
+	models.LimitPerTransfer:     i18n.MsgLimitPerTransfer,
+	models.LimitDaily:           i18n.MsgLimitDaily,
+	models.LimitWeekly:          i18n.MsgLimitWeekly,
+	models.LimitDailyRecipients: i18n.MsgLimitDailyRecipients,
+}
+
+// failLimit объясняет, какой лимит переводов превышен и когда он сбросится.
+func failLimit(w http.ResponseWriter, r *http.Request, err *storage.LimitError) {
+	lang := i18n.FromContext(r.Context())
+
+	detail := lang.T(limitMessages[err.Limit], err.Max)
+	if err.ResetsAt != nil {
+		detail = lang.T(limitMessages[err.Limit], err.Max, err.ResetsAt.UTC().Format(time.RFC3339))
+	}
+
+	write(w, r, KindTransferLimit, detail, nil, func(p *Problem) {
+		p.Limit = err.Limit
+		p.Max = err.Max
+		p.ResetsAt = err.ResetsAt
+	})
+}
+
+var purchaseLimitMessages = map[string]i18n.Key{
+	models.PeriodTotal: i18n.MsgPurchaseLimitTotal,
+	models.PeriodDay:   i18n.MsgPurchaseLimitDay,
+	models.PeriodWeek:  i18n.MsgPurchaseLimitWeek,
+	models.PeriodMonth: i18n.MsgPurchaseLimitMonth,
+}
+
+// failPurchaseLimit объясняет, сколько штук товара можно купить и когда лимит сбросится.
+func failPurchaseLimit(w http.ResponseWriter, r *http.Request, err *storage.PurchaseLimitError) {
+	lang := i18n.FromContext(r.Context())
+
+	detail := lang.T(i18n.MsgPurchaseLimitTotal, err.Max)
+	if err.ResetsAt != nil {
+		detail = lang.T(purchaseLimitMessages[err.Period], err.Max, err.ResetsAt.UTC().Format(time.RFC3339))
+	}
+
+	write(w, r, KindPurchaseLimit, detail, nil, func(p *Problem) {
+		p.Limit = err.Period
+		p.Max = err.Max
+		p.ResetsAt = err.ResetsAt
+	})
+}
+
+// FailValidation отвечает 400 со списком нарушенных правил.
+func FailValidation(w http.ResponseWriter, r *http.Request, fields ...FieldError) {
+	write(w, r, KindValidation, i18n.FromContext(r.Context()).T(i18n.MsgFieldsNotValid), fields)
+}
+
+// write отвечает ошибкой. extend дополняет problem+json полями конкретной ошибки.
+func write(w http.ResponseWriter, r *http.Request, kind Kind, detail string, fields []FieldError, extend ...func(p *Problem)) {
+	title := i18n.FromContext(r.Context()).T(kind.Title)
+
+	if legacyErrors(r) {
+		msg := detail
+		if msg == "" || len(fields) > 0 {
+			msg = strings.ToLower(title)
+		}
+		render.Status(r, kind.Status)
+		render.JSON(w, r, ErrorResponse{Error: msg, Fields: fields})
+		return
+	}
+
+	p := Problem{
+		Type:      kind.Type,
+		Title:     title,
+		Status:    kind.Status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    fields,
+	}
+	for _, fn := range extend {
+		fn(&p)
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(kind.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+type legacyKey struct{}
+
+// WithLegacyErrors включает старый формат {"error": "..."} для запроса.
+func WithLegacyErrors(ctx context.Context) context.Context {
+	return context.WithValue(ctx, legacyKey{}, true)
+}
+
+// Клиент может явно попросить problem+json даже при включенном старом формате.
+func legacyErrors(r *http.Request) bool {
+	legacy, _ := r.Context().Value(legacyKey{}).(bool)
+	return legacy && !strings.Contains(r.Header.Get("Accept"), ContentTypeProblem)
+}
@@ -1,17 +1,25 @@
 package response
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+	"github.com/magneless/merch-shop/internal/lib/validate"
 	"github.com/magneless/merch-shop/internal/models"
 )
 
 type InfoResponse struct {
-	Coins       int             `json:"coins"`
+	Coins       int                    `json:"coins"`
 	Inventory   []models.InventoryItem `json:"inventory"`
 	CoinHistory models.CoinHistory     `json:"coinHistory"`
+	Expiring    []models.ExpiringCoins `json:"expiring"`
+	// Orders - невыданные заказы, в Inventory они уже учтены.
+	Orders []models.Order `json:"orders"`
+}
+
+type MerchResponse struct {
+	Items []models.MerchItem `json:"items"`
 }
 
 type AuthResponse struct {
@@ -19,26 +27,60 @@ type AuthResponse struct {
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError описывает нарушенное правило для одного поля запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 type MessageResponse struct {
 	Message interface{} `json:"message"`
 }
 
-func ValidationError(errs validator.ValidationErrors) ErrorResponse {
-	var errMsgs []string
+// NewFieldError собирает ошибку поля с сообщением на языке l.
+func NewFieldError(l i18n.Lang, field, rule string, key i18n.Key, args ...any) FieldError {
+	return FieldError{Field: field, Rule: rule, Message: l.T(key, args...)}
+}
+
+// ValidationFields переводит ошибки validator в ошибки полей для ответа.
+func ValidationFields(l i18n.Lang, errs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(errs))
 
 	for _, err := range errs {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field % s is not valid", err.Field()))
-		}
+		fields = append(fields, FieldError{
+			Field:   err.Field(),
+			Rule:    err.Tag(),
+			Message: fieldMessage(l, err),
+		})
 	}
 
-	return ErrorResponse{
-		Error: strings.Join(errMsgs, ", "),
+	return fields
+}
+
+func fieldMessage(l i18n.Lang, err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return l.T(i18n.MsgFieldRequired, err.Field())
+	case "username":
+		return l.T(i18n.MsgFieldUsername, err.Field(), validate.UsernameMinLen, validate.UsernameMaxLen)
+	case "password":
+		return l.T(i18n.MsgFieldPassword, err.Field(), validate.PasswordMinLen, validate.PasswordMaxLen)
+	case "amount":
+		return l.T(i18n.MsgFieldAmount, err.Field(), validate.MaxTransferAmount)
+	case "gt":
+		return l.T(i18n.MsgFieldGreaterThan, err.Field(), err.Param())
+	case "lte":
+		return l.T(i18n.MsgFieldAtMost, err.Field(), err.Param())
+	case "max":
+		return l.T(i18n.MsgFieldMaxLength, err.Field(), err.Param())
+	case "nefield":
+		return l.T(i18n.MsgFieldDiffer, err.Field(), strings.ToLower(err.Param()))
+	default:
+		return l.T(i18n.MsgFieldNotValid, err.Field())
 	}
 }
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	ActionLogin    = "login"
+	ActionRegister = "register"
+	ActionTransfer = "transfer"
+	// ActionTransferBulk пишется только при неудаче, успешный массовый
+	// перевод попадает в журнал отдельными transfer.
+	ActionTransferBulk       = "transfer.bulk"
+	ActionPurchase           = "purchase"
+	ActionOrderApprove       = "order.approve"
+	ActionOrderReject        = "order.reject"
+	ActionOrderExpire        = "order.expire"
+	ActionOrderStatus        = "order.status"
+	ActionOrderCancel        = "order.cancel"
+	ActionOrderReturnRequest = "order.return_request"
+	ActionOrderReturn        = "order.return"
+	ActionOrderReturnReject  = "order.return_reject"
+	ActionCoinsExpire        = "coins.expire"
+
+	ActionCoinRequestCreate  = "coin_request.create"
+	ActionCoinRequestDecline = "coin_request.decline"
+
+	ActionAdminLogLevel     = "admin.log_level"
+	ActionAdminEmployee     = "admin.employee"
+	ActionAdminLimits       = "admin.limits"
+	ActionAdminVariant      = "admin.variant"
+	ActionAdminPurchaseRule = "admin.purchase_rule"
+	ActionTreasuryMint      = "treasury.mint"
+	ActionGrantCreate       = "grant.create"
+	ActionGrantExecute      = "grant.execute"
+	ActionGrantDelete       = "grant.delete"
+	ActionPromoCreate       = "promo.create"
+	ActionPromoDisable      = "promo.disable"
+
+	ActionFraudFlag    = "fraud.flag"
+	ActionFraudDismiss = "fraud.dismiss"
+	ActionFraudConfirm = "fraud.confirm"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Meta - данные запроса, которые попадают в каждую запись журнала.
+type Meta struct {
+	RequestID string
+	ClientIP  string
+}
+
+type ctxKey struct{}
+
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, ctxKey{}, meta)
+}
+
+func MetaFromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(ctxKey{}).(Meta)
+	return meta
+}
+
+// MetaFromRequest берет request id из middleware.RequestID и адрес клиента из RemoteAddr.
+func MetaFromRequest(r *http.Request) Meta {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return Meta{
+		RequestID: middleware.GetReqID(r.Context()),
+		ClientIP:  ip,
+	}
+}
@@ -0,0 +1,127 @@
+package i18n
+
+var en = map[Key]string{
+	MsgTitleBadRequest:          "Bad request",
+	MsgTitleValidation:          "Validation failed",
+	MsgTitleUnauthorized:        "Unauthorized",
+	MsgTitleInvalidCredentials:  "Wrong password or login",
+	MsgTitleForbidden:           "Access denied",
+	MsgTitleNotFound:            "Not found",
+	MsgTitleMethodNotAllowed:    "Method not allowed",
+	MsgTitleUserNotFound:        "User not found",
+	MsgTitleMerchNotFound:       "Merch not found",
+	MsgTitleWebhookNotFound:     "Webhook not found",
+	MsgTitleCoinRequestNotFound: "Coin request not found",
+	MsgTitleCoinRequestResolved: "Coin request already answered",
+	MsgTitleCoinRequestExpired:  "Coin request expired",
+	MsgTitleScheduleNotFound:    "Scheduled transfer not found",
+	MsgTitleGrantNotFound:       "Grant campaign not found",
+	MsgTitleGrantExecuted:       "Grant campaign already paid out",
+	MsgTitleTransferLimit:       "Transfer limit exceeded",
+	MsgTitleTransfersHeld:       "Transfers are held for review",
+	MsgTitleFraudFlagNotFound:   "Fraud flag not found",
+	MsgTitleFraudFlagResolved:   "Fraud flag already resolved",
+	MsgTitleOrderNotFound:       "Order not found",
+	MsgTitleOrderResolved:       "Order is not awaiting approval",
+	MsgTitleOrderExpired:        "Order approval expired",
+	MsgTitleOrderTransition:     "Order cannot move to this status",
+	MsgTitleCancelWindowClosed:  "Order can no longer be cancelled, request a return",
+	MsgTitleVariantNotFound:     "Merch variant not found",
+	MsgTitleVariantRequired:     "Merch has several variants, choose size or color",
+	MsgTitleVariantExists:       "Merch variant already exists",
+	MsgTitlePromoNotFound:       "Promo code not found",
+	MsgTitlePromoNotApplicable:  "Promo code does not apply to this purchase",
+	MsgTitlePromoExhausted:      "Promo code usage limit reached",
+	MsgTitlePromoExists:         "Promo code already exists",
+	MsgTitlePurchaseLimit:       "Purchase limit for this merch reached",
+	MsgTitleNotReleased:         "Merch is not on sale yet",
+	MsgTitleOutOfStock:          "Merch is out of stock",
+	MsgTitleUserExists:          "User already exists",
+	MsgTitleInsufficientBalance: "Not enough coins",
+	MsgTitleInternal:            "Internal error",
+
+	MsgRequestBodyEmpty:         "request body is empty",
+	MsgInvalidRequest:           "invalid request",
+	MsgInvalidRequestBody:       "invalid request body",
+	MsgInvalidRequestBodyReason: "invalid request body: %s",
+	MsgFieldsNotValid:           "one or more fields are not valid",
+	MsgParameterNotValid:        "parameter %s is not valid",
+	MsgResponseSpecMismatch:     "response does not match api spec: %s",
+	MsgItemRequired:             "item is required",
+	MsgInvalidLastEventID:       "invalid Last-Event-ID",
+	MsgUnknownLogLevel:          "unknown log level",
+	MsgAuthHeaderMissing:        "authorization header is missed",
+	MsgAuthHeaderFormat:         "invalid authorization header format",
+	MsgTokenInvalid:             "invalid or expired access token",
+	MsgSubscribeOwnEvents:       "you can subscribe only to your own events",
+	MsgInvalidWebhookID:         "invalid webhook id",
+	MsgInvalidDeliveryID:        "invalid delivery id",
+	MsgUnknownDeliveryStatus:    "unknown delivery status",
+	MsgInvalidLimit:             "invalid limit",
+	MsgDeadDeliveryNotFound:     "dead delivery not found",
+	MsgParamTime:                "parameter %s must be an RFC3339 time",
+	MsgParamNonNegative:         "parameter %s must be a non-negative integer",
+	MsgInvalidCoinRequestID:     "invalid coin request id",
+	MsgUnknownCoinRequestStatus: "unknown coin request status",
+	MsgUnknownCoinRequestRole:   "role must be incoming or outgoing",
+	MsgInvalidScheduleID:        "invalid scheduled transfer id",
+	MsgScheduleNoRuns:           "schedule has no future runs",
+	MsgScheduleCompleted:        "scheduled transfer is already completed",
+	MsgInvalidGrantID:           "invalid grant campaign id",
+	MsgLimitPerTransfer:         "a single transfer cannot exceed %d coins",
+	MsgLimitDaily:               "daily limit of %d coins reached, resets at %s",
+	MsgLimitWeekly:              "weekly limit of %d coins reached, resets at %s",
+	MsgLimitDailyRecipients:     "daily limit of %d recipients reached, resets at %s",
+	MsgPurchaseLimitTotal:       "you can buy at most %d of this merch",
+	MsgPurchaseLimitDay:         "you can buy at most %d of this merch per day, resets at %s",
+	MsgPurchaseLimitWeek:        "you can buy at most %d of this merch per week, resets at %s",
+	MsgPurchaseLimitMonth:       "you can buy at most %d of this merch per month, resets at %s",
+	MsgNotReleased:              "merch goes on sale at %s",
+	MsgInvalidFraudFlagID:       "invalid fraud flag id",
+	MsgUnknownFraudFlagStatus:   "unknown fraud flag status",
+	MsgUnknownFraudRule:         "unknown fraud rule",
+	MsgInvalidOrderID:           "invalid order id",
+	MsgInvalidVariantID:         "invalid variant id",
+	MsgInvalidPromoID:           "invalid promotion id",
+	MsgUnknownOrderStatus:       "unknown order status",
+
+	MsgFieldRequired:         "field %s is a required field",
+	MsgFieldUsername:         "field %s must be %d-%d characters long and contain only latin letters, digits, '_', '-' or '.'",
+	MsgFieldPassword:         "field %s must be %d-%d characters long and contain at least one letter and one digit",
+	MsgFieldAmount:           "field %s must be between 1 and %d",
+	MsgFieldGreaterThan:      "field %s must be greater than %s",
+	MsgFieldAtMost:           "field %s must be at most %s",
+	MsgFieldNotNegative:      "field %s must not be negative",
+	MsgFieldMaxLength:        "field %s must be at most %s characters long",
+	MsgFieldMaxItems:         "field %s must contain at most %d items",
+	MsgFieldDuplicate:        "field %s contains duplicate %s",
+	MsgFieldRequiredWithout:  "field %s or %s is required",
+	MsgFieldBulkMode:         "field %s or %s is required, but not both",
+	MsgFieldSplitTotal:       "field %s must be at least the number of recipients (%d)",
+	MsgFieldScheduleKind:     "exactly one of %s, %s or %s is required",
+	MsgFieldAfter:            "field %s must be after %s",
+	MsgFieldFuture:           "field %s must be in the future",
+	MsgFieldCron:             "field %s is not a valid cron expression",
+	MsgFieldMinInterval:      "field %s must be at least %d seconds",
+	MsgFieldOneOf:            "field %s must be one of: %s",
+	MsgFieldDiffer:           "field %s must differ from %s",
+	MsgFieldNotValid:         "field %s is not valid",
+	MsgFieldURL:              "field %s must be an absolute http(s) url",
+	MsgFieldUnknownEventType: "field %s contains unknown event type %s",
+
+	MsgCoinsSent:        "coins successfully sent",
+	MsgWebhookDeleted:   "webhook deleted",
+	MsgDeliveryRequeued: "delivery requeued",
+	MsgScheduleDeleted:  "scheduled transfer deleted",
+	MsgGrantDeleted:     "grant campaign deleted",
+	MsgCoinsExpiring:    "%d coins expiring on %s",
+
+	MsgWSNotSubscribed:        "not subscribed",
+	MsgWSUnknownMessageType:   "unknown message type",
+	MsgWSUnknownChannel:       "unknown channel",
+	MsgWSAccessDenied:         "access denied",
+	MsgWSTooManySubscriptions: "too many subscriptions",
+
+	MsgActivityPurchased: "%s bought %s",
+	MsgActivityJoined:    "%s joined the shop",
+}
@@ -0,0 +1,154 @@
+// Package i18n - каталог пользовательских сообщений на русском и английском
+// и выбор языка по заголовку Accept-Language.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type Lang string
+
+const (
+	EN Lang = "en"
+	RU Lang = "ru"
+)
+
+// Default используется, когда клиент не прислал Accept-Language
+// или ни один из его языков не поддерживается.
+const Default = EN
+
+// Key - ключ сообщения в каталоге.
+type Key string
+
+var catalog = map[Lang]map[Key]string{
+	EN: en,
+	RU: ru,
+}
+
+// Langs - поддерживаемые языки, первый - язык по умолчанию.
+var Langs = []Lang{EN, RU}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+// Negotiate выбирает язык по значению Accept-Language.
+func Negotiate(acceptLanguage string) Lang {
+	if acceptLanguage == "" {
+		return Default
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, idx, conf := matcher.Match(tags...)
+	if conf == language.No {
+		return Default
+	}
+
+	return Langs[idx]
+}
+
+// T возвращает сообщение на языке l. Аргументы подставляются через fmt.
+// Если перевода нет, используется язык по умолчанию, затем сам ключ.
+func (l Lang) T(key Key, args ...any) string {
+	msg, ok := catalog[l][key]
+	if !ok {
+		msg, ok = catalog[Default][key]
+	}
+	if !ok {
+		msg = string(key)
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+type ctxKey struct{}
+
+func WithLang(ctx context.Context, l Lang) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext возвращает язык запроса или язык по умолчанию.
+func FromContext(ctx context.Context) Lang {
+	if l, ok := ctx.Value(ctxKey{}).(Lang); ok {
+		return l
+	}
+	return Default
+}
+
+var verbRe = regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+
+// Check проверяет, что каждый ключ переведен на все языки и что переводы
+// ожидают одинаковые аргументы. Вызывается при старте сервиса.
+func Check() error {
+	var problems []string
+
+	for key, base := range catalog[Default] {
+		for _, l := range Langs[1:] {
+			msg, ok := catalog[l][key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing in %s", key, l))
+				continue
+			}
+			if !maps.Equal(verbs(base), verbs(msg)) {
+				problems = append(problems, fmt.Sprintf("%s: arguments differ in %s", key, l))
+			}
+		}
+	}
+	for _, l := range Langs[1:] {
+		for key := range catalog[l] {
+			if _, ok := catalog[Default][key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing in %s", key, Default))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return fmt.Errorf("i18n catalog is incomplete: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// verbs возвращает глаголы форматирования по номеру аргумента,
+// с учетом явных индексов вида %[2]s.
+func verbs(msg string) map[int]byte {
+	out := make(map[int]byte)
+	next := 1
+	for _, m := range verbRe.FindAllStringSubmatch(msg, -1) {
+		idx := next
+		if m[1] != "" {
+			idx, _ = strconv.Atoi(m[1][1 : len(m[1])-1])
+		}
+		out[idx] = m[0][len(m[0])-1]
+		next = idx + 1
+	}
+	return out
+}
+
+// Error - ошибка ввода, текст которой показывается клиенту на его языке.
+type Error struct {
+	Key  Key
+	Args []any
+}
+
+func NewError(key Key, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return Default.T(e.Key, e.Args...)
+}
@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// declaredKeys собирает константы типа Key из исходников пакета: так в проверку
+// попадает и ключ, который забыли добавить в оба каталога сразу.
+func declaredKeys(t *testing.T) map[Key]string {
+	t.Helper()
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to parse package: %v", err)
+	}
+
+	keys := map[Key]string{}
+	for _, file := range pkgs["i18n"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if typ, ok := vs.Type.(*ast.Ident); !ok || typ.Name != "Key" {
+					continue
+				}
+				for i, name := range vs.Names {
+					lit, ok := vs.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						t.Fatalf("%s: key value is not a string literal", name.Name)
+					}
+					value, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatalf("%s: %v", name.Name, err)
+					}
+					keys[Key(value)] = name.Name
+				}
+			}
+		}
+	}
+	if len(keys) == 0 {
+		t.Fatal("no Key constants found")
+	}
+
+	return keys
+}
+
+func TestEveryKeyTranslated(t *testing.T) {
+	keys := declaredKeys(t)
+
+	for key, name := range keys {
+		base, ok := catalog[Default][key]
+		if !ok {
+			t.Errorf("%s (%s): missing in %s", name, key, Default)
+		}
+		for _, l := range Langs[1:] {
+			msg, ok := catalog[l][key]
+			if !ok {
+				t.Errorf("%s (%s): missing in %s", name, key, l)
+				continue
+			}
+			if base != "" && !maps.Equal(verbs(base), verbs(msg)) {
+				t.Errorf("%s (%s): format verbs differ: %s %q, %s %q", name, key, Default, base, l, msg)
+			}
+		}
+	}
+
+	for _, l := range Langs {
+		for key := range catalog[l] {
+			if _, ok := keys[key]; !ok {
+				t.Errorf("%s catalog has %q without a Key constant", l, key)
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerbs(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want map[int]byte
+	}{
+		{"no arguments", map[int]byte{}},
+		{"%s sent %d coins", map[int]byte{1: 's', 2: 'd'}},
+		{"%[2]d монет от %[1]s", map[int]byte{1: 's', 2: 'd'}},
+		{"100%% done, %s", map[int]byte{1: 's'}},
+	}
+	for _, tt := range tests {
+		if got := verbs(tt.msg); !maps.Equal(got, tt.want) {
+			t.Errorf("verbs(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package i18n
+
+const (
+	MsgTitleBadRequest          Key = "title.bad_request"
+	MsgTitleValidation          Key = "title.validation"
+	MsgTitleUnauthorized        Key = "title.unauthorized"
+	MsgTitleInvalidCredentials  Key = "title.invalid_credentials"
+	MsgTitleForbidden           Key = "title.forbidden"
+	MsgTitleNotFound            Key = "title.not_found"
+	MsgTitleMethodNotAllowed    Key = "title.method_not_allowed"
+	MsgTitleUserNotFound        Key = "title.user_not_found"
+	MsgTitleMerchNotFound       Key = "title.merch_not_found"
+	MsgTitleWebhookNotFound     Key = "title.webhook_not_found"
+	MsgTitleCoinRequestNotFound Key = "title.coin_request_not_found"
+	MsgTitleCoinRequestResolved Key = "title.coin_request_resolved"
+	MsgTitleCoinRequestExpired  Key = "title.coin_request_expired"
+	MsgTitleScheduleNotFound    Key = "title.schedule_not_found"
+	MsgTitleGrantNotFound       Key = "title.grant_not_found"
+	MsgTitleGrantExecuted       Key = "title.grant_executed"
+	MsgTitleTransferLimit       Key = "title.transfer_limit"
+	MsgTitleTransfersHeld       Key = "title.transfers_held"
+	MsgTitleFraudFlagNotFound   Key = "title.fraud_flag_not_found"
+	MsgTitleFraudFlagResolved   Key = "title.fraud_flag_resolved"
+	MsgTitleOrderNotFound       Key = "title.order_not_found"
+	MsgTitleOrderResolved       Key = "title.order_resolved"
+	MsgTitleOrderExpired        Key = "title.order_expired"
+	MsgTitleOrderTransition     Key = "title.order_transition"
+	MsgTitleCancelWindowClosed  Key = "title.cancel_window_closed"
+	MsgTitleVariantNotFound     Key = "title.variant_not_found"
+	MsgTitleVariantRequired     Key = "title.variant_required"
+	MsgTitleVariantExists       Key = "title.variant_exists"
+	MsgTitlePromoNotFound       Key = "title.promo_not_found"
+	MsgTitlePromoNotApplicable  Key = "title.promo_not_applicable"
+	MsgTitlePromoExhausted      Key = "title.promo_exhausted"
+	MsgTitlePromoExists         Key = "title.promo_exists"
+	MsgTitlePurchaseLimit       Key = "title.purchase_limit"
+	MsgTitleNotReleased         Key = "title.not_released"
+	MsgTitleOutOfStock          Key = "title.out_of_stock"
+	MsgTitleUserExists          Key = "title.user_exists"
+	MsgTitleInsufficientBalance Key = "title.insufficient_balance"
+	MsgTitleInternal            Key = "title.internal"
+
+	MsgRequestBodyEmpty         Key = "error.request_body_empty"
+	MsgInvalidRequest           Key = "error.invalid_request"
+	MsgInvalidRequestBody       Key = "error.invalid_request_body"
+	MsgInvalidRequestBodyReason Key = "error.invalid_request_body_reason"
+	MsgFieldsNotValid           Key = "error.fields_not_valid"
+	MsgParameterNotValid        Key = "error.parameter_not_valid"
+	MsgResponseSpecMismatch     Key = "error.response_spec_mismatch"
+	MsgItemRequired             Key = "error.item_required"
+	MsgInvalidLastEventID       Key = "error.invalid_last_event_id"
+	MsgUnknownLogLevel          Key = "error.unknown_log_level"
+	MsgAuthHeaderMissing        Key = "error.auth_header_missing"
+	MsgAuthHeaderFormat         Key = "error.auth_header_format"
+	MsgTokenInvalid             Key = "error.token_invalid"
+	MsgSubscribeOwnEvents       Key = "error.subscribe_own_events"
+	MsgInvalidWebhookID         Key = "error.invalid_webhook_id"
+	MsgInvalidDeliveryID        Key = "error.invalid_delivery_id"
+	MsgUnknownDeliveryStatus    Key = "error.unknown_delivery_status"
+	MsgInvalidLimit             Key = "error.invalid_limit"
+	MsgDeadDeliveryNotFound     Key = "error.dead_delivery_not_found"
+	MsgParamTime                Key = "error.param_time"
+	MsgParamNonNegative         Key = "error.param_non_negative"
+	MsgInvalidCoinRequestID     Key = "error.invalid_coin_request_id"
+	MsgUnknownCoinRequestStatus Key = "error.unknown_coin_request_status"
+	MsgUnknownCoinRequestRole   Key = "error.unknown_coin_request_role"
+	MsgInvalidScheduleID        Key = "error.invalid_schedule_id"
+	MsgScheduleNoRuns           Key = "error.schedule_no_runs"
+	MsgScheduleCompleted        Key = "error.schedule_completed"
+	MsgInvalidGrantID           Key = "error.invalid_grant_id"
+	MsgLimitPerTransfer         Key = "error.limit_per_transfer"
+	MsgLimitDaily               Key = "error.limit_daily"
+	MsgLimitWeekly              Key = "error.limit_weekly"
+	MsgLimitDailyRecipients     Key = "error.limit_daily_recipients"
+	MsgPurchaseLimitTotal       Key = "error.purchase_limit_total"
+	MsgPurchaseLimitDay         Key = "error.purchase_limit_day"
+	MsgPurchaseLimitWeek        Key = "error.purchase_limit_week"
+	MsgPurchaseLimitMonth       Key = "error.purchase_limit_month"
+	MsgNotReleased              Key = "error.not_released"
+	MsgInvalidFraudFlagID       Key = "error.invalid_fraud_flag_id"
+	MsgUnknownFraudFlagStatus   Key = "error.unknown_fraud_flag_status"
+	MsgUnknownFraudRule         Key = "error.unknown_fraud_rule"
+	MsgInvalidOrderID           Key = "error.invalid_order_id"
+	MsgInvalidVariantID         Key = "error.invalid_variant_id"
+	MsgInvalidPromoID           Key = "error.invalid_promo_id"
+	MsgUnknownOrderStatus       Key = "error.unknown_order_status"
+
+	MsgFieldRequired         Key = "field.required"
+	MsgFieldUsername         Key = "field.username"
+	MsgFieldPassword         Key = "field.password"
+	MsgFieldAmount           Key = "field.amount"
+	MsgFieldGreaterThan      Key = "field.gt"
+	MsgFieldAtMost           Key = "field.lte"
+	MsgFieldNotNegative      Key = "field.not_negative"
+	MsgFieldMaxLength        Key = "field.max"
+	MsgFieldMaxItems         Key = "field.max_items"
+	MsgFieldDuplicate        Key = "field.duplicate"
+	MsgFieldRequiredWithout  Key = "field.required_without"
+	MsgFieldBulkMode         Key = "field.bulk_mode"
+	MsgFieldSplitTotal       Key = "field.split_total"
+	MsgFieldScheduleKind     Key = "field.schedule_kind"
+	MsgFieldAfter            Key = "field.gtfield"
+	MsgFieldFuture           Key = "field.future"
+	MsgFieldCron             Key = "field.cron"
+	MsgFieldMinInterval      Key = "field.min_interval"
+	MsgFieldOneOf            Key = "field.oneof"
+	MsgFieldDiffer           Key = "field.nefield"
+	MsgFieldNotValid         Key = "field.not_valid"
+	MsgFieldURL              Key = "field.url"
+	MsgFieldUnknownEventType Key = "field.unknown_event_type"
+
+	MsgCoinsSent        Key = "message.coins_sent"
+	MsgWebhookDeleted   Key = "message.webhook_deleted"
+	MsgDeliveryRequeued Key = "message.delivery_requeued"
+	MsgScheduleDeleted  Key = "message.schedule_deleted"
+	MsgGrantDeleted     Key = "message.grant_deleted"
+	MsgCoinsExpiring    Key = "message.coins_expiring"
+
+	MsgWSNotSubscribed        Key = "ws.not_subscribed"
+	MsgWSUnknownMessageType   Key = "ws.unknown_message_type"
+	MsgWSUnknownChannel       Key = "ws.unknown_channel"
+	MsgWSAccessDenied         Key = "ws.access_denied"
+	MsgWSTooManySubscriptions Key = "ws.too_many_subscriptions"
+
+	MsgActivityPurchased Key = "activity.merch_purchased"
+	MsgActivityJoined    Key = "activity.user_joined"
+)
@@ -0,0 +1,127 @@
+package i18n
+
+var ru = map[Key]string{
+	MsgTitleBadRequest:          "Некорректный запрос",
+	MsgTitleValidation:          "Ошибка валидации",
+	MsgTitleUnauthorized:        "Требуется авторизация",
+	MsgTitleInvalidCredentials:  "Неверный логин или пароль",
+	MsgTitleForbidden:           "Доступ запрещен",
+	MsgTitleNotFound:            "Не найдено",
+	MsgTitleMethodNotAllowed:    "Метод не поддерживается",
+	MsgTitleUserNotFound:        "Пользователь не найден",
+	MsgTitleMerchNotFound:       "Товар не найден",
+	MsgTitleWebhookNotFound:     "Подписка не найдена",
+	MsgTitleCoinRequestNotFound: "Просьба о переводе не найдена",
+	MsgTitleCoinRequestResolved: "На просьбу уже ответили",
+	MsgTitleCoinRequestExpired:  "Срок просьбы истек",
+	MsgTitleScheduleNotFound:    "Запланированный перевод не найден",
+	MsgTitleGrantNotFound:       "Кампания грантов не найдена",
+	MsgTitleGrantExecuted:       "По кампании уже были выплаты",
+	MsgTitleTransferLimit:       "Превышен лимит переводов",
+	MsgTitleTransfersHeld:       "Переводы приостановлены до проверки",
+	MsgTitleFraudFlagNotFound:   "Отметка не найдена",
+	MsgTitleFraudFlagResolved:   "Отметка уже разобрана",
+	MsgTitleOrderNotFound:       "Заказ не найден",
+	MsgTitleOrderResolved:       "Заказ не ждет согласования",
+	MsgTitleOrderExpired:        "Срок согласования заказа истек",
+	MsgTitleOrderTransition:     "Заказ нельзя перевести в этот статус",
+	MsgTitleCancelWindowClosed:  "Заказ уже нельзя отменить, оформите возврат",
+	MsgTitleVariantNotFound:     "Вариант товара не найден",
+	MsgTitleVariantRequired:     "У товара несколько вариантов, выберите размер или цвет",
+	MsgTitleVariantExists:       "Такой вариант товара уже есть",
+	MsgTitlePromoNotFound:       "Промокод не найден",
+	MsgTitlePromoNotApplicable:  "Промокод не действует на эту покупку",
+	MsgTitlePromoExhausted:      "Промокод больше нельзя использовать",
+	MsgTitlePromoExists:         "Такой промокод уже есть",
+	MsgTitlePurchaseLimit:       "Лимит покупок этого товара исчерпан",
+	MsgTitleNotReleased:         "Товар еще не поступил в продажу",
+	MsgTitleOutOfStock:          "Товар закончился",
+	MsgTitleUserExists:          "Пользователь уже существует",
+	MsgTitleInsufficientBalance: "Недостаточно монет",
+	MsgTitleInternal:            "Внутренняя ошибка",
+
+	MsgRequestBodyEmpty:         "тело запроса пустое",
+	MsgInvalidRequest:           "некорректный запрос",
+	MsgInvalidRequestBody:       "некорректное тело запроса",
+	MsgInvalidRequestBodyReason: "некорректное тело запроса: %s",
+	MsgFieldsNotValid:           "одно или несколько полей заполнены неверно",
+	MsgParameterNotValid:        "параметр %s указан неверно",
+	MsgResponseSpecMismatch:     "ответ не соответствует спецификации API: %s",
+	MsgItemRequired:             "не указан товар",
+	MsgInvalidLastEventID:       "некорректный Last-Event-ID",
+	MsgUnknownLogLevel:          "неизвестный уровень логирования",
+	MsgAuthHeaderMissing:        "нет заголовка Authorization",
+	MsgAuthHeaderFormat:         "неверный формат заголовка Authorization",
+	MsgTokenInvalid:             "токен недействителен или истек",
+	MsgSubscribeOwnEvents:       "можно подписаться только на свои события",
+	MsgInvalidWebhookID:         "некорректный id подписки",
+	MsgInvalidDeliveryID:        "некорректный id доставки",
+	MsgUnknownDeliveryStatus:    "неизвестный статус доставки",
+	MsgInvalidLimit:             "некорректный limit",
+	MsgDeadDeliveryNotFound:     "доставка в dead-letter не найдена",
+	MsgParamTime:                "параметр %s должен быть временем в формате RFC3339",
+	MsgParamNonNegative:         "параметр %s должен быть неотрицательным целым числом",
+	MsgInvalidCoinRequestID:     "некорректный id просьбы",
+	MsgUnknownCoinRequestStatus: "неизвестный статус просьбы",
+	MsgUnknownCoinRequestRole:   "role должен быть incoming или outgoing",
+	MsgInvalidScheduleID:        "некорректный id запланированного перевода",
+	MsgScheduleNoRuns:           "у расписания нет будущих запусков",
+	MsgScheduleCompleted:        "запланированный перевод уже завершен",
+	MsgInvalidGrantID:           "некорректный id кампании грантов",
+	MsgLimitPerTransfer:         "за один перевод можно отправить не больше %d монет",
+	MsgLimitDaily:               "исчерпан дневной лимит в %d монет, он сбросится %s",
+	MsgLimitWeekly:              "исчерпан недельный лимит в %d монет, он сбросится %s",
+	MsgLimitDailyRecipients:     "исчерпан дневной лимит в %d получателей, он сбросится %s",
+	MsgPurchaseLimitTotal:       "этого товара можно купить не больше %d шт.",
+	MsgPurchaseLimitDay:         "этого товара можно купить не больше %d шт. в день, лимит сбросится %s",
+	MsgPurchaseLimitWeek:        "этого товара можно купить не больше %d шт. в неделю, лимит сбросится %s",
+	MsgPurchaseLimitMonth:       "этого товара можно купить не больше %d шт. в месяц, лимит сбросится %s",
+	MsgNotReleased:              "товар поступит в продажу %s",
+	MsgInvalidFraudFlagID:       "некорректный id отметки",
+	MsgUnknownFraudFlagStatus:   "неизвестный статус отметки",
+	MsgUnknownFraudRule:         "неизвестное правило",
+	MsgInvalidOrderID:           "некорректный id заказа",
+	MsgInvalidVariantID:         "некорректный id варианта",
+	MsgInvalidPromoID:           "некорректный id промоакции",
+	MsgUnknownOrderStatus:       "неизвестный статус заказа",
+
+	MsgFieldRequired:         "поле %s обязательно",
+	MsgFieldUsername:         "поле %s должно содержать от %d до %d символов: латинские буквы, цифры, '_', '-' или '.'",
+	MsgFieldPassword:         "поле %s должно содержать от %d до %d символов, хотя бы одну букву и одну цифру",
+	MsgFieldAmount:           "поле %s должно быть от 1 до %d",
+	MsgFieldGreaterThan:      "поле %s должно быть больше %s",
+	MsgFieldAtMost:           "поле %s должно быть не больше %s",
+	MsgFieldNotNegative:      "поле %s не может быть отрицательным",
+	MsgFieldMaxLength:        "поле %s должно быть не длиннее %s символов",
+	MsgFieldMaxItems:         "поле %s должно содержать не больше %d элементов",
+	MsgFieldDuplicate:        "поле %s содержит повтор %s",
+	MsgFieldRequiredWithout:  "нужно указать поле %s или %s",
+	MsgFieldBulkMode:         "нужно указать поле %s или %s, но не оба",
+	MsgFieldSplitTotal:       "поле %s должно быть не меньше числа получателей (%d)",
+	MsgFieldScheduleKind:     "нужно указать ровно одно из полей %s, %s или %s",
+	MsgFieldAfter:            "поле %s должно быть позже %s",
+	MsgFieldFuture:           "поле %s должно быть в будущем",
+	MsgFieldCron:             "поле %s не является cron-выражением",
+	MsgFieldMinInterval:      "поле %s должно быть не меньше %d секунд",
+	MsgFieldOneOf:            "поле %s должно быть одним из: %s",
+	MsgFieldDiffer:           "поле %s должно отличаться от %s",
+	MsgFieldNotValid:         "поле %s заполнено неверно",
+	MsgFieldURL:              "поле %s должно быть абсолютным http(s) адресом",
+	MsgFieldUnknownEventType: "поле %s содержит неизвестный тип события %s",
+
+	MsgCoinsSent:        "монеты отправлены",
+	MsgWebhookDeleted:   "подписка удалена",
+	MsgDeliveryRequeued: "доставка поставлена в очередь",
+	MsgScheduleDeleted:  "запланированный перевод удален",
+	MsgGrantDeleted:     "кампания грантов удалена",
+	MsgCoinsExpiring:    "сгорит монет: %d (%s)",
+
+	MsgWSNotSubscribed:        "подписки нет",
+	MsgWSUnknownMessageType:   "неизвестный тип сообщения",
+	MsgWSUnknownChannel:       "неизвестный канал",
+	MsgWSAccessDenied:         "доступ запрещен",
+	MsgWSTooManySubscriptions: "слишком много подписок",
+
+	MsgActivityPurchased: "%s купил(а) %s",
+	MsgActivityJoined:    "%s присоединился(-ась) к магазину",
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/lib/logger/redact"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	envLocal = "local"
+	envDev   = "dev"
+
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// New собирает логгер по конфигу. Возвращаемый LevelVar позволяет
+// менять уровень логирования во время работы сервиса.
+func New(env string, cfg config.Logger) (*slog.Logger, *slog.LevelVar, error) {
+	const op = "lib.logger.New"
+
+	level := new(slog.LevelVar)
+	if err := level.UnmarshalText([]byte(levelOrDefault(env, cfg.Level))); err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid level %q: %w", op, cfg.Level, err)
+	}
+
+	out, err := output(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact.ReplaceAttr,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, opts)
+	case FormatText, "":
+		handler = slog.NewTextHandler(out, opts)
+	default:
+		return nil, nil, fmt.Errorf("%s: unknown format %q", op, cfg.Format)
+	}
+
+	return slog.New(handler), level, nil
+}
+
+func levelOrDefault(env, level string) string {
+	if level != "" {
+		return level
+	}
+
+	switch env {
+	case envLocal, envDev:
+		return slog.LevelDebug.String()
+	default:
+		return slog.LevelInfo.String()
+	}
+}
+
+func output(cfg config.Logger) (io.Writer, error) {
+	switch strings.ToLower(cfg.Output) {
+	case OutputStdout, "":
+		return os.Stdout, nil
+	case OutputStderr:
+		return os.Stderr, nil
+	case OutputFile:
+		if cfg.File.Path == "" {
+			return nil, fmt.Errorf("log file path is not set")
+		}
+		return &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSizeMB,
+			MaxBackups: cfg.File.MaxBackups,
+			MaxAge:     cfg.File.MaxAgeDays,
+			Compress:   cfg.File.Compress,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output %q", cfg.Output)
+	}
+}
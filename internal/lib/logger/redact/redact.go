@@ -0,0 +1,144 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const Mask = "[REDACTED]"
+
+// ключи сравниваются без учета регистра и разделителей: "Password", "password_hash" и т.п.
+var sensitiveKeys = []string{
+	"password",
+	"passwordhash",
+	"token",
+	"accesstoken",
+	"refreshtoken",
+	"authorization",
+	"cookie",
+	"secret",
+}
+
+var (
+	bearerRe = regexp.MustCompile(`(?i)bearer\s+\S+`)
+	jwtRe    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+)
+
+// ReplaceAttr подходит для slog.HandlerOptions и скрывает значения
+// чувствительных полей, а также bearer- и jwt-токены внутри строк.
+// Поля внутри групп, структур, map и срезов проверяются так же, как верхнего уровня.
+func ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	for _, g := range groups {
+		if IsSensitiveKey(g) {
+			return slog.String(a.Key, Mask)
+		}
+	}
+
+	return Attr(a)
+}
+
+// Attr скрывает чувствительные данные в атрибуте и во всем, что в нем вложено.
+func Attr(a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Mask)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if s := String(v.String()); s != v.String() {
+			return slog.String(a.Key, s)
+		}
+	case slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = Attr(attr)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if value, ok := anyValue(v.Any()); ok {
+			return slog.Any(a.Key, value)
+		}
+	}
+
+	return a
+}
+
+// anyValue заменяет составное значение его JSON-представлением с уже скрытыми
+// полями: обработчик иначе выведет структуру целиком, вместе с паролями.
+// ok = false - значение можно выводить как есть.
+func anyValue(v any) (any, bool) {
+	if err, isErr := v.(error); isErr {
+		if s := String(err.Error()); s != err.Error() {
+			return s, true
+		}
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Pointer, reflect.Interface:
+	default:
+		return nil, false
+	}
+	if b, isBytes := v.([]byte); isBytes {
+		return String(string(b)), true
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		// не знаем, что внутри, и не выводим
+		return Mask, true
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return Mask, true
+	}
+
+	return redactJSON(decoded), true
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if IsSensitiveKey(k) {
+				v[k] = Mask
+				continue
+			}
+			v[k] = redactJSON(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+		return v
+	case string:
+		return String(v)
+	default:
+		return v
+	}
+}
+
+func IsSensitiveKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func String(s string) string {
+	s = bearerRe.ReplaceAllString(s, "Bearer "+Mask)
+	return jwtRe.ReplaceAllString(s, Mask)
+}
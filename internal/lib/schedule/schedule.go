@@ -0,0 +1,79 @@
+// Package schedule считает время запусков запланированных переводов.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/robfig/cron/v3"
+)
+
+// MinInterval - минимальный период для расписания с интервалом.
+const MinInterval = time.Minute
+
+// Стандартный cron из пяти полей и дескрипторы вроде @weekly.
+// Часовой пояс задается префиксом CRON_TZ=Europe/Moscow, по умолчанию UTC.
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseCron проверяет cron-выражение.
+func ParseCron(expr string) (cron.Schedule, error) {
+	s, err := parser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return s, nil
+}
+
+// Next возвращает первый запуск строго после after, не раньше st.StartAt.
+// ok = false, если запусков больше не будет.
+func Next(st models.ScheduledTransfer, after time.Time) (next time.Time, ok bool, err error) {
+	switch st.Kind {
+	case models.ScheduleOnce:
+		if st.StartAt.After(after) {
+			return st.StartAt, true, nil
+		}
+		return time.Time{}, false, nil
+
+	case models.ScheduleInterval:
+		interval := time.Duration(st.IntervalSeconds) * time.Second
+		if interval < MinInterval {
+			return time.Time{}, false, fmt.Errorf("interval %s is shorter than %s", interval, MinInterval)
+		}
+		if st.StartAt.After(after) {
+			return st.StartAt, true, nil
+		}
+		n := after.Sub(st.StartAt)/interval + 1
+		return st.StartAt.Add(n * interval), true, nil
+
+	case models.ScheduleCron:
+		s, err := ParseCron(st.Cron)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if st.StartAt.After(after) {
+			// запуск ровно в StartAt тоже подходит
+			after = st.StartAt.Add(-time.Nanosecond)
+		}
+		next := s.Next(after.UTC())
+		if next.IsZero() {
+			return time.Time{}, false, nil
+		}
+		return next, true, nil
+
+	default:
+		return time.Time{}, false, fmt.Errorf("unknown schedule kind %q", st.Kind)
+	}
+}
+
+// First возвращает первый запуск после now: для нового расписания
+// или для возобновленного после паузы.
+func First(st models.ScheduledTransfer, now time.Time) (time.Time, bool, error) {
+	next, ok, err := Next(st, now.Add(-time.Nanosecond))
+	if err != nil || ok || st.Kind != models.ScheduleOnce || st.Runs > 0 {
+		return next, ok, err
+	}
+
+	// разовый перевод, который не успел выполниться, выполняется сразу
+	return now, true, nil
+}
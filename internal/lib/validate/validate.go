@@ -0,0 +1,93 @@
+// Package validate - общий экземпляр validator с правилами для логинов,
+// паролей и сумм переводов.
+package validate
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	UsernameMinLen = 3
+	UsernameMaxLen = 32
+
+	PasswordMinLen = 8
+	// PasswordMaxLen - верхняя граница длины пароля нового аккаунта.
+	PasswordMaxLen = 72
+
+	// MaxTransferAmount - верхняя граница суммы одного перевода.
+	MaxTransferAmount = 100000
+)
+
+var usernameRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+var v = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// В ошибках поля называются так же, как в JSON.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return f.Name
+		}
+		return name
+	})
+
+	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return Username(fl.Field().String())
+	})
+	v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return Password(fl.Field().String())
+	})
+	v.RegisterValidation("amount", func(fl validator.FieldLevel) bool {
+		return Amount(int(fl.Field().Int()))
+	})
+
+	return v
+}
+
+// Struct проверяет структуру по тегам validate.
+// Ошибки правил возвращаются как validator.ValidationErrors.
+func Struct(s any) error {
+	return v.Struct(s)
+}
+
+// Var проверяет одно значение по тегу, например "required,username".
+func Var(field any, tag string) error {
+	return v.Var(field, tag)
+}
+
+// Username: 3-32 символа, латинские буквы, цифры, '_', '-' и '.'.
+func Username(s string) bool {
+	return len(s) >= UsernameMinLen && len(s) <= UsernameMaxLen && usernameRe.MatchString(s)
+}
+
+// Password: 8-72 байта, хотя бы одна буква и одна цифра.
+func Password(s string) bool {
+	if len(s) < PasswordMinLen || len(s) > PasswordMaxLen {
+		return false
+	}
+
+	var letter, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+
+	return letter && digit
+}
+
+// Amount: положительная сумма не больше MaxTransferAmount.
+func Amount(n int) bool {
+	return n > 0 && n <= MaxTransferAmount
+}
@@ -1,10 +1,74 @@
 package models
 
+import "time"
+
 type InventoryItem struct {
 	Type     string `json:"type"`
+	Size     string `json:"size,omitempty"`
+	Color    string `json:"color,omitempty"`
 	Quantity int    `json:"quantity"`
 }
 
+type MerchItem struct {
+	Name     string         `json:"name"`
+	Category string         `json:"category,omitempty"`
+	Price    int            `json:"price"`
+	Rule     *PurchaseRule  `json:"rule,omitempty"`
+	Variants []MerchVariant `json:"variants"`
+}
+
+// Периоды правила покупки.
+const (
+	PeriodTotal = "total"
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+var PurchasePeriods = []string{PeriodTotal, PeriodDay, PeriodWeek, PeriodMonth}
+
+// PurchaseRule - ограничения покупки товара: сотрудник покупает не больше MaxQuantity
+// штук за период Period (PeriodTotal - за все время), купить товар можно с ReleaseAt.
+// nil - без ограничения.
+type PurchaseRule struct {
+	MaxQuantity *int       `json:"maxQuantity,omitempty"`
+	Period      string     `json:"period"`
+	ReleaseAt   *time.Time `json:"releaseAt,omitempty"`
+}
+
+// Empty - правило ничего не ограничивает.
+func (r PurchaseRule) Empty() bool {
+	return r.MaxQuantity == nil && r.ReleaseAt == nil
+}
+
+// MerchVariant - вариант товара. У варианта по умолчанию размер и цвет пустые.
+type MerchVariant struct {
+	ID    int    `json:"id"`
+	Size  string `json:"size,omitempty"`
+	Color string `json:"color,omitempty"`
+	// Stock - остаток на складе, nil - без ограничений.
+	Stock  *int `json:"stock,omitempty"`
+	Active bool `json:"active"`
+}
+
+// VariantChoice - какой вариант выбрал покупатель. Пустое поле - любое значение.
+type VariantChoice struct {
+	Size  string
+	Color string
+}
+
+// Purchase - покупка Quantity штук варианта Variant товара Item.
+// PromoCode - введенный покупателем промокод, может быть пустым.
+// С Recipient это подарок: покупатель платит, товар получает Recipient.
+type Purchase struct {
+	Item      string
+	Variant   VariantChoice
+	Quantity  int
+	PromoCode string
+	Recipient string
+	Message   string
+}
+
 type CoinHistory struct {
 	Received []CoinTransaction `json:"received"`
 	Sent     []CoinTransaction `json:"sent"`
@@ -14,4 +78,466 @@ type CoinTransaction struct {
 	FromUser string `json:"fromUser"`
 	ToUser   string `json:"toUser"`
 	Amount   int    `json:"amount"`
-}
\ No newline at end of file
+	// BatchID связывает переводы одной массовой отправки.
+	BatchID *int64 `json:"batchId,omitempty"`
+}
+
+// TransferBatch - массовый перевод, выполненный одной транзакцией.
+type TransferBatch struct {
+	ID        int64             `json:"id"`
+	FromUser  string            `json:"fromUser"`
+	Total     int               `json:"total"`
+	Note      string            `json:"note,omitempty"`
+	Transfers []CoinTransaction `json:"transfers"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+type AuditEntry struct {
+	ID                  int64     `json:"id"`
+	CreatedAt           time.Time `json:"createdAt"`
+	Actor               string    `json:"actor"`
+	Action              string    `json:"action"`
+	Target              string    `json:"target,omitempty"`
+	Amount              *int      `json:"amount,omitempty"`
+	ActorBalanceBefore  *int      `json:"actorBalanceBefore,omitempty"`
+	ActorBalanceAfter   *int      `json:"actorBalanceAfter,omitempty"`
+	TargetBalanceBefore *int      `json:"targetBalanceBefore,omitempty"`
+	TargetBalanceAfter  *int      `json:"targetBalanceAfter,omitempty"`
+	RequestID           string    `json:"requestId,omitempty"`
+	ClientIP            string    `json:"clientIp,omitempty"`
+	Outcome             string    `json:"outcome"`
+	Details             string    `json:"details,omitempty"`
+}
+
+type AuditFilter struct {
+	Actor     string
+	Target    string
+	Action    string
+	Outcome   string
+	RequestID string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"eventTypes"`
+	Employee   string    `json:"employee,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscriptionId"`
+	EventID        int64      `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"nextAttemptAt"`
+	LastStatusCode *int       `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// PendingDelivery - доставка, взятая воркером в работу, вместе с адресом и секретом подписки.
+type PendingDelivery struct {
+	ID        int64
+	EventID   int64
+	EventType string
+	Attempts  int
+	URL       string
+	Secret    string
+	Body      []byte
+}
+
+const (
+	CoinRequestPending  = "pending"
+	CoinRequestAccepted = "accepted"
+	CoinRequestDeclined = "declined"
+	CoinRequestExpired  = "expired"
+
+	CoinRequestIncoming = "incoming"
+	CoinRequestOutgoing = "outgoing"
+)
+
+// CoinRequest - просьба Requester перевести ему Amount монет от Payer.
+type CoinRequest struct {
+	ID         int64      `json:"id"`
+	Requester  string     `json:"requester"`
+	Payer      string     `json:"payer"`
+	Amount     int        `json:"amount"`
+	Note       string     `json:"note"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// CoinRequestFilter - выборка просьб сотрудника. Role: CoinRequestIncoming (платит он),
+// CoinRequestOutgoing (просит он) или пусто - обе стороны.
+type CoinRequestFilter struct {
+	Username string
+	Role     string
+	Status   string
+	Limit    int
+}
+
+const (
+	ScheduleOnce     = "once"
+	ScheduleCron     = "cron"
+	ScheduleInterval = "interval"
+
+	ScheduleActive    = "active"
+	SchedulePaused    = "paused"
+	ScheduleCompleted = "completed"
+
+	// CatchUpOnce выполняет пропущенные за время простоя запуски один раз,
+	// CatchUpAll - каждый, CatchUpSkip пропускает опоздавшие дольше scheduler.grace.
+	CatchUpOnce = "once"
+	CatchUpAll  = "all"
+	CatchUpSkip = "skip"
+
+	PauseInsufficientBalance = "insufficient_balance"
+	PauseReceiverNotFound    = "receiver_not_found"
+	PauseTransferLimit       = "transfer_limit"
+	PauseTransfersHeld       = "transfers_held"
+)
+
+// ScheduledTransfer - разовый или повторяющийся перевод от FromUser к ToUser.
+// StartAt - время разового перевода, для остальных - не раньше какого времени начинать.
+type ScheduledTransfer struct {
+	ID              int64      `json:"id"`
+	FromUser        string     `json:"fromUser"`
+	ToUser          string     `json:"toUser"`
+	Amount          int        `json:"amount"`
+	Note            string     `json:"note,omitempty"`
+	Kind            string     `json:"kind"`
+	Cron            string     `json:"cron,omitempty"`
+	IntervalSeconds int64      `json:"intervalSeconds,omitempty"`
+	StartAt         time.Time  `json:"startAt"`
+	CatchUp         string     `json:"catchUp"`
+	Status          string     `json:"status"`
+	PauseReason     string     `json:"pauseReason,omitempty"`
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt       *time.Time `json:"lastRunAt,omitempty"`
+	Runs            int        `json:"runs"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// TreasuryUsername - системный сотрудник, из баланса которого выплачиваются гранты.
+const TreasuryUsername = "treasury"
+
+// EmployeeUpdate - изменения сотрудника администратором, nil - не менять.
+type EmployeeUpdate struct {
+	Department *string `json:"department,omitempty"`
+	Active     *bool   `json:"active,omitempty"`
+	// TransfersHeld - запрет исходящих переводов до проверки (см. FraudFlag).
+	TransfersHeld *bool `json:"transfersHeld,omitempty"`
+	// Manager - согласует покупки сотрудников своего отдела.
+	Manager *bool `json:"manager,omitempty"`
+}
+
+type Employee struct {
+	Username      string `json:"username"`
+	Department    string `json:"department"`
+	Active        bool   `json:"active"`
+	Manager       bool   `json:"manager"`
+	Balance       int    `json:"balance"`
+	TransfersHeld bool   `json:"transfersHeld"`
+}
+
+const (
+	GrantDraft     = "draft"
+	GrantScheduled = "scheduled"
+	GrantCompleted = "completed"
+	GrantFailed    = "failed"
+)
+
+// GrantCampaign - начисление Amount монет каждому активному сотруднику
+// (или сотрудникам отдела Department) из казны.
+// Recipients и Total - сколько сотрудников и монет уже выплачено.
+type GrantCampaign struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Amount      int        `json:"amount"`
+	Department  string     `json:"department,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	Status      string     `json:"status"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
+	CreatedBy   string     `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExecutedAt  *time.Time `json:"executedAt,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	Recipients  int        `json:"recipients"`
+	Total       int        `json:"total"`
+}
+
+// GrantPreview - кому и сколько будет выплачено при следующем запуске кампании.
+type GrantPreview struct {
+	CampaignID      int64    `json:"campaignId"`
+	Recipients      []string `json:"recipients"`
+	Total           int      `json:"total"`
+	TreasuryBalance int      `json:"treasuryBalance"`
+	Sufficient      bool     `json:"sufficient"`
+}
+
+// GrantRun - результат запуска кампании. Если платить было некому, BatchID = nil.
+type GrantRun struct {
+	CampaignID int64  `json:"campaignId"`
+	BatchID    *int64 `json:"batchId,omitempty"`
+	Recipients int    `json:"recipients"`
+	Total      int    `json:"total"`
+}
+
+type GrantPayout struct {
+	Username string    `json:"username"`
+	Amount   int       `json:"amount"`
+	BatchID  int64     `json:"batchId"`
+	PaidAt   time.Time `json:"paidAt"`
+}
+
+type GrantReport struct {
+	Campaign GrantCampaign `json:"campaign"`
+	Payouts  []GrantPayout `json:"payouts"`
+}
+
+// Источники лотов монет.
+const (
+	LotInitial  = "initial"
+	LotMint     = "mint"
+	LotTransfer = "transfer"
+)
+
+// Виды записей журнала движения монет (ledger_entries).
+const (
+	LedgerIssue       = "issue"
+	LedgerTransferIn  = "transfer_in"
+	LedgerTransferOut = "transfer_out"
+	LedgerPurchase    = "purchase"
+	LedgerExpire      = "expire"
+	LedgerRefund      = "refund"
+)
+
+// ExpiringCoins - сколько монет сгорит в день ExpiresOn (YYYY-MM-DD, UTC).
+// Message - то же текстом на языке клиента.
+type ExpiringCoins struct {
+	Amount    int    `json:"amount"`
+	ExpiresOn string `json:"expiresOn"`
+	Message   string `json:"message"`
+}
+
+// CoinLot - лот монет сотрудника Username, в котором осталось Amount монет.
+type CoinLot struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Amount    int        `json:"amount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Лимиты исходящих переводов.
+const (
+	LimitPerTransfer     = "per_transfer"
+	LimitDaily           = "daily"
+	LimitWeekly          = "weekly"
+	LimitDailyRecipients = "daily_recipients"
+)
+
+// TransferLimits - лимиты исходящих переводов, 0 - без ограничения.
+type TransferLimits struct {
+	MaxTransfer     int `json:"maxTransfer"`
+	Daily           int `json:"daily"`
+	Weekly          int `json:"weekly"`
+	DailyRecipients int `json:"dailyRecipients"`
+}
+
+// TransferLimitOverride - лимиты сотрудника, заданные администратором.
+// nil - как в конфиге, 0 - без ограничения.
+type TransferLimitOverride struct {
+	MaxTransfer     *int `json:"maxTransfer"`
+	Daily           *int `json:"daily"`
+	Weekly          *int `json:"weekly"`
+	DailyRecipients *int `json:"dailyRecipients"`
+}
+
+// TransferUsage - сколько сотрудник уже отправил в текущих окнах лимитов.
+type TransferUsage struct {
+	Daily           int       `json:"daily"`
+	Weekly          int       `json:"weekly"`
+	DailyRecipients int       `json:"dailyRecipients"`
+	DayResetsAt     time.Time `json:"dayResetsAt"`
+	WeekResetsAt    time.Time `json:"weekResetsAt"`
+}
+
+// EmployeeLimits - действующие лимиты сотрудника, переопределения и расход.
+type EmployeeLimits struct {
+	Username string                `json:"username"`
+	Limits   TransferLimits        `json:"limits"`
+	Override TransferLimitOverride `json:"override"`
+	Usage    TransferUsage         `json:"usage"`
+}
+
+// Правила поиска подозрительных переводов.
+const (
+	FraudCycle      = "cycle"
+	FraudPingPong   = "ping_pong"
+	FraudFreshFanIn = "fresh_fan_in"
+)
+
+const (
+	FraudFlagOpen      = "open"
+	FraudFlagDismissed = "dismissed"
+	FraudFlagConfirmed = "confirmed"
+)
+
+// TransferRecord - перевод для анализа. FromCreatedAt - когда зарегистрировался отправитель.
+type TransferRecord struct {
+	ID            int64
+	FromUser      string
+	ToUser        string
+	Amount        int
+	CreatedAt     time.Time
+	FromCreatedAt time.Time
+}
+
+// FraudFinding - подозрительная схема переводов. Key задает схему внутри правила
+// (участники круга, пара, получатель), чтобы одна схема не отмечалась дважды.
+type FraudFinding struct {
+	Rule           string
+	Key            string
+	Usernames      []string
+	TransactionIDs []int64
+	Details        string
+}
+
+// FraudFlag - отметка сотрудника Username, участвующего в подозрительной схеме.
+type FraudFlag struct {
+	ID             int64      `json:"id"`
+	Username       string     `json:"username"`
+	Rule           string     `json:"rule"`
+	Details        string     `json:"details"`
+	TransactionIDs []int64    `json:"transactionIds"`
+	Status         string     `json:"status"`
+	TransfersHeld  bool       `json:"transfersHeld"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ResolvedBy     string     `json:"resolvedBy,omitempty"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+}
+
+type FraudFlagFilter struct {
+	Username string
+	Status   string
+	Rule     string
+	Limit    int
+}
+
+const (
+	OrderPendingApproval = "pending_approval"
+	OrderRejected        = "rejected"
+	OrderExpired         = "expired"
+	OrderPlaced          = "placed"
+	OrderReadyForPickup  = "ready_for_pickup"
+	OrderShipped         = "shipped"
+	OrderDelivered       = "delivered"
+	OrderCancelled       = "cancelled"
+	OrderReturnRequested = "return_requested"
+	OrderReturned        = "returned"
+)
+
+// OrderOpenStatuses - заказ еще не выдан и не отменен или ждет решения по возврату.
+var OrderOpenStatuses = []string{OrderPendingApproval, OrderPlaced, OrderReadyForPickup, OrderShipped, OrderReturnRequested}
+
+// OrderStatuses - все статусы заказа.
+var OrderStatuses = []string{
+	OrderPendingApproval, OrderRejected, OrderExpired,
+	OrderPlaced, OrderReadyForPickup, OrderShipped, OrderDelivered, OrderCancelled,
+	OrderReturnRequested, OrderReturned,
+}
+
+// Order - покупка Quantity штук Item по цене Price со скидкой Discount. Покупка, попавшая под правило
+// согласования Policy, ждет решения руководителя до ApproveBy, монеты на это время
+// списаны с баланса и возвращаются при отказе. Оплаченный заказ склад выдает
+// сотруднику, History - все смены статуса. Заказ с Recipient - подарок: платит Username,
+// товар получает Recipient.
+type Order struct {
+	ID         int64      `json:"id"`
+	Username   string     `json:"username"`
+	Recipient  string     `json:"recipient,omitempty"`
+	Message    string     `json:"message,omitempty"`
+	Item       string     `json:"item"`
+	Size       string     `json:"size,omitempty"`
+	Color      string     `json:"color,omitempty"`
+	Quantity   int        `json:"quantity"`
+	Price      int        `json:"price"`
+	Discount   int        `json:"discount,omitempty"`
+	Total      int        `json:"total"`
+	Status     string     `json:"status"`
+	Policy     string     `json:"policy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ApproveBy  *time.Time `json:"approveBy,omitempty"`
+	ResolvedBy string     `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	// Promotions - названия промоакций, давших скидку Discount.
+	Promotions []string `json:"promotions,omitempty"`
+
+	History []OrderTransition `json:"history,omitempty"`
+}
+
+// OrderTransition - заказ переведен в Status сотрудником ChangedBy.
+type OrderTransition struct {
+	Status    string    `json:"status"`
+	ChangedBy string    `json:"changedBy"`
+	Note      string    `json:"note,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type OrderFilter struct {
+	Username string
+	Status   string
+	Limit    int
+}
+
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+var PromoKinds = []string{PromoPercent, PromoFixed}
+
+// Promotion - скидка Value процентов (PromoPercent) или Value монет на заказ (PromoFixed)
+// на товары Items из категорий Categories для сотрудников Usernames; пустой список -
+// без ограничения. Промоакция без Code применяется к покупке сама, с кодом - только
+// если покупатель его ввел. Stackable - скидка складывается с другими такими же.
+// Uses - сколько раз промоакция уже применена (отмененные заказы не считаются).
+type Promotion struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Code           string     `json:"code,omitempty"`
+	Kind           string     `json:"kind"`
+	Value          int        `json:"value"`
+	Items          []string   `json:"items,omitempty"`
+	Categories     []string   `json:"categories,omitempty"`
+	Usernames      []string   `json:"usernames,omitempty"`
+	StartsAt       *time.Time `json:"startsAt,omitempty"`
+	EndsAt         *time.Time `json:"endsAt,omitempty"`
+	MaxUses        *int       `json:"maxUses,omitempty"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser,omitempty"`
+	Stackable      bool       `json:"stackable"`
+	Active         bool       `json:"active"`
+	Uses           int        `json:"uses"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/magneless/merch-shop/internal/notify"
+)
+
+// Broker раздает уведомления подписчикам внутри процесса.
+// Если подписчик не успевает читать и его буфер заполнен, подписка закрывается:
+// клиент переподключится и догонит пропущенное по Last-Event-ID.
+type Broker struct {
+	mu     sync.Mutex
+	buffer int
+	topics map[string]map[*subscription]struct{}
+}
+
+func New(buffer int) *Broker {
+	return &Broker{
+		buffer: buffer,
+		topics: make(map[string]map[*subscription]struct{}),
+	}
+}
+
+func (b *Broker) Publish(_ context.Context, n notify.Notification) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.topics[n.Topic] {
+		select {
+		case sub.ch <- n:
+		default:
+			b.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+func (b *Broker) Subscribe(topic string) notify.Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &subscription{
+		broker: b,
+		topic:  topic,
+		ch:     make(chan notify.Notification, b.buffer),
+	}
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*subscription]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+
+	return sub
+}
+
+// CloseAll закрывает все подписки. Используется, когда уведомления могли потеряться.
+func (b *Broker) CloseAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subs := range b.topics {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+// remove вызывается под b.mu.
+func (b *Broker) remove(sub *subscription) {
+	subs, ok := b.topics[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.topics, sub.topic)
+	}
+	close(sub.ch)
+}
+
+type subscription struct {
+	broker *Broker
+	topic  string
+	ch     chan notify.Notification
+}
+
+func (s *subscription) C() <-chan notify.Notification {
+	return s.ch
+}
+
+func (s *subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
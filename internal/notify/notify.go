@@ -0,0 +1,304 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/i18n"
+)
+
+const (
+	TypeCoinsReceived  = "coins_received"
+	TypeCoinsSent      = "coins_sent"
+	TypeMerchPurchased = "merch_purchased"
+	TypeGiftReceived   = "gift_received"
+	TypeCoinsExpired   = "coins_expired"
+	TypeSchedulePaused = "scheduled_transfer_paused"
+	TypeOrderStatus    = "order_status"
+	TypeApprovalNeeded = "approval_requested"
+	TypeActivity       = "activity"
+)
+
+// KindUserJoined - запись ленты о новом сотруднике.
+const KindUserJoined = "user_joined"
+
+// TopicActivity - общая лента событий компании.
+const TopicActivity = "activity"
+
+// Notification - сообщение для клиента. ID совпадает с ID доменного события,
+// поэтому по нему можно продолжить поток после переподключения.
+type Notification struct {
+	ID    int64           `json:"id"`
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// UserTopic - личный канал сотрудника.
+func UserTopic(username string) string {
+	return "user:" + username
+}
+
+// Subscription - подписка на один канал. C закрывается, когда подписка
+// закрыта или подписчик не успевает читать сообщения.
+type Subscription interface {
+	C() <-chan Notification
+	Close()
+}
+
+type Broker interface {
+	Publish(ctx context.Context, n Notification) error
+	Subscribe(topic string) Subscription
+}
+
+type CoinsReceived struct {
+	FromUser string `json:"fromUser"`
+	Amount   int    `json:"amount"`
+	Balance  int    `json:"balance"`
+}
+
+type CoinsSent struct {
+	ToUser  string `json:"toUser"`
+	Amount  int    `json:"amount"`
+	Balance int    `json:"balance"`
+}
+
+// MerchPurchased - покупка сотрудника. Recipient заполнен, если товар куплен в подарок.
+type MerchPurchased struct {
+	Item      string `json:"item"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Total     int    `json:"total"`
+	Balance   int    `json:"balance"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// GiftReceived - FromUser подарил сотруднику товар, он уже в инвентаре.
+type GiftReceived struct {
+	FromUser string `json:"fromUser"`
+	Item     string `json:"item"`
+	Size     string `json:"size,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Quantity int    `json:"quantity"`
+	Message  string `json:"message,omitempty"`
+}
+
+type CoinsExpired struct {
+	Amount  int `json:"amount"`
+	Balance int `json:"balance"`
+}
+
+type SchedulePaused struct {
+	ScheduleID int64  `json:"scheduleId"`
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Reason     string `json:"reason"`
+}
+
+type OrderStatus struct {
+	OrderID  int64  `json:"orderId"`
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+	Total    int    `json:"total"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Balance  int    `json:"balance"`
+}
+
+// ApprovalNeeded - руководителю нужно согласовать заказ сотрудника.
+type ApprovalNeeded struct {
+	OrderID  int64  `json:"orderId"`
+	Username string `json:"username"`
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+	Total    int    `json:"total"`
+}
+
+// Activity - запись общей ленты, например "anna bought hoody".
+type Activity struct {
+	Kind     string `json:"kind"`
+	Username string `json:"username"`
+	Item     string `json:"item,omitempty"`
+	Quantity int    `json:"quantity,omitempty"`
+	Message  string `json:"message"`
+}
+
+// FromEvent превращает доменное событие в уведомления для затронутых сотрудников.
+// Личные уведомления несут новый баланс получателя, в общую ленту баланс не попадает.
+func FromEvent(e events.Event) ([]Notification, error) {
+	payload, err := events.Decode(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Notification
+	add := func(topic, typ string, data any) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s notification: %w", typ, err)
+		}
+		out = append(out, Notification{ID: e.ID, Topic: topic, Type: typ, Data: raw})
+		return nil
+	}
+
+	switch p := payload.(type) {
+	case *events.CoinsTransferred:
+		if err := add(UserTopic(p.ToUser), TypeCoinsReceived, CoinsReceived{
+			FromUser: p.FromUser,
+			Amount:   p.Amount,
+			Balance:  p.ToBalance,
+		}); err != nil {
+			return nil, err
+		}
+		if err := add(UserTopic(p.FromUser), TypeCoinsSent, CoinsSent{
+			ToUser:  p.ToUser,
+			Amount:  p.Amount,
+			Balance: p.FromBalance,
+		}); err != nil {
+			return nil, err
+		}
+	case *events.MerchPurchased:
+		if err := add(UserTopic(p.Username), TypeMerchPurchased, MerchPurchased{
+			Item:      p.Item,
+			Size:      p.Size,
+			Color:     p.Color,
+			Quantity:  p.Quantity,
+			Total:     p.Total,
+			Balance:   p.Balance,
+			Recipient: p.Recipient,
+		}); err != nil {
+			return nil, err
+		}
+		if p.Recipient != "" {
+			if err := add(UserTopic(p.Recipient), TypeGiftReceived, GiftReceived{
+				FromUser: p.Username,
+				Item:     p.Item,
+				Size:     p.Size,
+				Color:    p.Color,
+				Quantity: p.Quantity,
+				Message:  p.Message,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		if err := add(TopicActivity, TypeActivity, Activity{
+			Kind:     TypeMerchPurchased,
+			Username: p.Username,
+			Item:     p.Item,
+			Quantity: p.Quantity,
+			Message:  i18n.Default.T(i18n.MsgActivityPurchased, p.Username, p.Item),
+		}); err != nil {
+			return nil, err
+		}
+	case *events.CoinsExpired:
+		if err := add(UserTopic(p.Username), TypeCoinsExpired, CoinsExpired{
+			Amount:  p.Amount,
+			Balance: p.Balance,
+		}); err != nil {
+			return nil, err
+		}
+	case *events.ScheduledTransferPaused:
+		if err := add(UserTopic(p.Username), TypeSchedulePaused, SchedulePaused{
+			ScheduleID: p.ScheduleID,
+			ToUser:     p.ToUser,
+			Amount:     p.Amount,
+			Reason:     p.Reason,
+		}); err != nil {
+			return nil, err
+		}
+	case *events.OrderStatusChanged:
+		if err := add(UserTopic(p.Username), TypeOrderStatus, OrderStatus{
+			OrderID:  p.OrderID,
+			Item:     p.Item,
+			Quantity: p.Quantity,
+			Total:    p.Total,
+			Status:   p.Status,
+			Reason:   p.Reason,
+			Balance:  p.Balance,
+		}); err != nil {
+			return nil, err
+		}
+		for _, approver := range p.Approvers {
+			if err := add(UserTopic(approver), TypeApprovalNeeded, ApprovalNeeded{
+				OrderID:  p.OrderID,
+				Username: p.Username,
+				Item:     p.Item,
+				Quantity: p.Quantity,
+				Total:    p.Total,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	case *events.UserRegistered:
+		if err := add(TopicActivity, TypeActivity, Activity{
+			Kind:     KindUserJoined,
+			Username: p.Username,
+			Message:  i18n.Default.T(i18n.MsgActivityJoined, p.Username),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// Localize переводит текст уведомления на язык подписчика.
+// Через брокер уведомления идут на языке по умолчанию.
+func Localize(n Notification, lang i18n.Lang) Notification {
+	if n.Type != TypeActivity || lang == i18n.Default {
+		return n
+	}
+
+	var a Activity
+	if err := json.Unmarshal(n.Data, &a); err != nil {
+		return n
+	}
+
+	switch a.Kind {
+	case TypeMerchPurchased:
+		a.Message = lang.T(i18n.MsgActivityPurchased, a.Username, a.Item)
+	case KindUserJoined:
+		a.Message = lang.T(i18n.MsgActivityJoined, a.Username)
+	default:
+		return n
+	}
+
+	raw, err := json.Marshal(a)
+	if err != nil {
+		return n
+	}
+	n.Data = raw
+
+	return n
+}
+
+// Sink - получатель outbox, который рассылает уведомления через брокер.
+type Sink struct {
+	broker Broker
+}
+
+func NewSink(broker Broker) *Sink {
+	return &Sink{broker: broker}
+}
+
+func (s *Sink) Name() string {
+	return "notifications"
+}
+
+func (s *Sink) Publish(ctx context.Context, e events.Event) error {
+	notifications, err := FromEvent(e)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range notifications {
+		if err := s.broker.Publish(ctx, n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package pgbroker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/notify"
+	"github.com/magneless/merch-shop/internal/notify/memory"
+)
+
+const channel = "merch_notifications"
+
+// Broker рассылает уведомления всем репликам через Postgres LISTEN/NOTIFY.
+// Каждая реплика слушает канал и раздает сообщения своим подписчикам.
+type Broker struct {
+	log      *slog.Logger
+	db       *sql.DB
+	listener *pq.Listener
+	local    *memory.Broker
+}
+
+func New(log *slog.Logger, db *sql.DB, dsn string, buffer int) *Broker {
+	log = log.With(slog.String("component", "notify.pgbroker"))
+
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error("listener connection problem", sl.Err(err))
+		}
+	})
+
+	return &Broker{
+		log:      log,
+		db:       db,
+		listener: listener,
+		local:    memory.New(buffer),
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context, n notify.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	if _, err := b.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, string(data)); err != nil {
+		return fmt.Errorf("failed to notify: %w", err)
+	}
+
+	return nil
+}
+
+func (b *Broker) Subscribe(topic string) notify.Subscription {
+	return b.local.Subscribe(topic)
+}
+
+// Run слушает канал, пока не отменен ctx.
+func (b *Broker) Run(ctx context.Context) error {
+	if err := b.listener.Listen(channel); err != nil {
+		return fmt.Errorf("failed to listen %s: %w", channel, err)
+	}
+	defer b.listener.Close()
+
+	b.log.Info("listening for notifications", slog.String("channel", channel))
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.local.CloseAll()
+			return nil
+		case pn := <-b.listener.Notify:
+			if pn == nil {
+				// соединение переподключилось, часть сообщений могла потеряться
+				b.log.Warn("listener reconnected, closing subscriptions")
+				b.local.CloseAll()
+				continue
+			}
+
+			var n notify.Notification
+			if err := json.Unmarshal([]byte(pn.Extra), &n); err != nil {
+				b.log.Error("failed to decode notification", sl.Err(err))
+				continue
+			}
+			b.local.Publish(ctx, n)
+		case <-time.After(90 * time.Second):
+			go b.listener.Ping()
+		}
+	}
+}
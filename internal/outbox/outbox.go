@@ -0,0 +1,145 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+)
+
+// Sink получает события из outbox. Доставка at-least-once:
+// одно и то же событие может прийти повторно, дедуплицировать нужно по Event.ID.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event events.Event) error
+}
+
+type EventStore interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]events.Pending, error)
+	CompleteEvent(ctx context.Context, id int64, delivered []string, dispatchErr error, nextAttempt *time.Time) error
+}
+
+type Dispatcher struct {
+	log   *slog.Logger
+	store EventStore
+	sinks []Sink
+	cfg   config.Outbox
+}
+
+func NewDispatcher(log *slog.Logger, store EventStore, cfg config.Outbox, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		log:   log.With(slog.String("component", "outbox.dispatcher")),
+		store: store,
+		sinks: sinks,
+		cfg:   cfg,
+	}
+}
+
+// AddSink подключает еще одного получателя. Вызывать до Run.
+func (d *Dispatcher) AddSink(sink Sink) {
+	d.sinks = append(d.sinks, sink)
+}
+
+// Run опрашивает outbox, пока не отменен ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.log.Info("outbox dispatcher started", slog.Int("sinks", len(d.sinks)))
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			d.log.Info("outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := d.store.ClaimEvents(ctx, d.cfg.BatchSize, d.cfg.Lease)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				d.log.Error("failed to claim events", sl.Err(err))
+			}
+			return
+		}
+
+		for _, p := range batch {
+			d.dispatch(ctx, p)
+		}
+		if len(batch) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, p events.Pending) {
+	log := d.log.With(
+		slog.Int64("event_id", p.ID),
+		slog.String("event_type", p.Type),
+	)
+
+	delivered, err := d.publish(ctx, p.Event, p.DeliveredSinks)
+
+	var nextAttempt *time.Time
+	if err != nil {
+		attempts := p.Attempts + 1
+		if attempts < d.cfg.MaxAttempts {
+			at := time.Now().Add(d.retryDelay(attempts))
+			nextAttempt = &at
+		} else {
+			log.Error("event moved to dead letter", slog.Int("attempts", attempts), sl.Err(err))
+		}
+	}
+
+	if err := d.store.CompleteEvent(ctx, p.ID, delivered, err, nextAttempt); err != nil {
+		log.Error("failed to save event dispatch result", sl.Err(err))
+	}
+}
+
+// publish отдает событие получателям, которые еще не приняли его,
+// и возвращает всех принявших вместе с уже записанными в delivered.
+func (d *Dispatcher) publish(ctx context.Context, event events.Event, delivered []string) ([]string, error) {
+	delivered = slices.Clone(delivered)
+
+	var errs []error
+	for _, sink := range d.sinks {
+		if slices.Contains(delivered, sink.Name()) {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			d.log.Warn("sink failed to publish event",
+				slog.String("sink", sink.Name()),
+				slog.Int64("event_id", event.ID),
+				slog.String("event_type", event.Type),
+				sl.Err(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// retryDelay - экспоненциальная задержка от RetryBase до RetryMax.
+func (d *Dispatcher) retryDelay(attempts int) time.Duration {
+	delay := float64(d.cfg.RetryBase) * math.Pow(2, float64(attempts-1))
+	if delay > float64(d.cfg.RetryMax) {
+		return d.cfg.RetryMax
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+)
+
+// fakeStore хранит одно событие и ведет его так же, как репозиторий:
+// неудача с nextAttempt - новая попытка, без nextAttempt - dead letter.
+type fakeStore struct {
+	event  events.Pending
+	status string
+	lease  time.Duration
+	delays []time.Duration
+}
+
+func (s *fakeStore) ClaimEvents(_ context.Context, _ int, lease time.Duration) ([]events.Pending, error) {
+	s.lease = lease
+	if s.status != "pending" {
+		return nil, nil
+	}
+	return []events.Pending{s.event}, nil
+}
+
+func (s *fakeStore) CompleteEvent(_ context.Context, _ int64, delivered []string, dispatchErr error, nextAttempt *time.Time) error {
+	s.event.Attempts++
+	s.event.DeliveredSinks = delivered
+	switch {
+	case dispatchErr == nil:
+		s.status = "published"
+	case nextAttempt != nil:
+		s.delays = append(s.delays, time.Until(*nextAttempt))
+	default:
+		s.status = "dead"
+	}
+	return nil
+}
+
+// fakeSink не принимает первые failures событий.
+type fakeSink struct {
+	name      string
+	failures  int
+	published int
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) Publish(context.Context, events.Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	s.published++
+	return nil
+}
+
+func newTestDispatcher(store EventStore, cfg config.Outbox, sinks ...Sink) *Dispatcher {
+	return NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), store, cfg, sinks...)
+}
+
+func newPending() events.Pending {
+	return events.Pending{Event: events.Event{ID: 42, Type: events.TypeCoinsTransferred}}
+}
+
+func TestRetryOnlyFailedSinks(t *testing.T) {
+	store := &fakeStore{event: newPending(), status: "pending"}
+	ok := &fakeSink{name: "notifications"}
+	flaky := &fakeSink{name: "webhooks", failures: 2}
+	d := newTestDispatcher(store, config.Outbox{
+		BatchSize:   10,
+		MaxAttempts: 5,
+		RetryBase:   time.Minute,
+		RetryMax:    time.Hour,
+		Lease:       30 * time.Second,
+	}, ok, flaky)
+
+	for i := 0; i < 10 && store.status == "pending"; i++ {
+		d.drain(context.Background())
+	}
+
+	if store.status != "published" || store.event.Attempts != 3 {
+		t.Fatalf("status = %s after %d attempts, want published after 3", store.status, store.event.Attempts)
+	}
+	if ok.published != 1 || flaky.published != 1 {
+		t.Errorf("published = %d, %d, want 1, 1", ok.published, flaky.published)
+	}
+	if !slices.Equal(store.event.DeliveredSinks, []string{"notifications", "webhooks"}) {
+		t.Errorf("delivered sinks = %v", store.event.DeliveredSinks)
+	}
+	if store.lease != 30*time.Second {
+		t.Errorf("lease = %s, want 30s", store.lease)
+	}
+
+	// 1, 2 минуты
+	for i, delay := range store.delays {
+		want := time.Minute << i
+		if delay < want-time.Second || delay > want {
+			t.Errorf("retry %d delay = %s, want %s", i+1, delay, want)
+		}
+	}
+}
+
+func TestDeadAfterMaxAttempts(t *testing.T) {
+	store := &fakeStore{event: newPending(), status: "pending"}
+	sink := &fakeSink{name: "webhooks", failures: 100}
+	d := newTestDispatcher(store, config.Outbox{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		RetryBase:   time.Second,
+		RetryMax:    time.Minute,
+	}, sink)
+
+	for i := 0; i < 10 && store.status == "pending"; i++ {
+		d.drain(context.Background())
+	}
+
+	if store.status != "dead" || store.event.Attempts != 3 {
+		t.Fatalf("status = %s after %d attempts, want dead after 3", store.status, store.event.Attempts)
+	}
+	if len(store.delays) != 2 || len(store.event.DeliveredSinks) != 0 {
+		t.Errorf("retries = %d, delivered = %v, want 2 retries and no sinks", len(store.delays), store.event.DeliveredSinks)
+	}
+}
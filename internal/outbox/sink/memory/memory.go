@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/magneless/merch-shop/internal/events"
+)
+
+// Handler обрабатывает событие внутри процесса. Ошибка приводит к повторной доставке.
+type Handler func(ctx context.Context, event events.Event) error
+
+// Sink раздает события подписчикам внутри процесса.
+// Подходит для локального запуска и для потребителей в этом же сервисе.
+type Sink struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]Handler
+}
+
+func New() *Sink {
+	return &Sink{handlers: make(map[int]Handler)}
+}
+
+func (s *Sink) Name() string {
+	return "memory"
+}
+
+// Subscribe регистрирует обработчик и возвращает функцию отписки.
+func (s *Sink) Subscribe(h Handler) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.handlers[id] = h
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers, id)
+	}
+}
+
+func (s *Sink) Publish(ctx context.Context, event events.Event) error {
+	s.mu.RLock()
+	handlers := make([]Handler, 0, len(s.handlers))
+	for _, h := range s.handlers {
+		handlers = append(handlers, h)
+	}
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
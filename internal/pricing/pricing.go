@@ -0,0 +1,108 @@
+// Package pricing считает цену покупки со скидками промоакций.
+package pricing
+
+import (
+	"slices"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+// Line - покупка Quantity штук Item категории Category по цене Price сотрудником Username.
+type Line struct {
+	Username string
+	Item     string
+	Category string
+	Price    int
+	Quantity int
+}
+
+// Quote - цена покупки: Total = ListTotal - Discount.
+// Applied - промоакции, давшие скидку, с их долей в Discount.
+type Quote struct {
+	ListTotal int
+	Discount  int
+	Total     int
+	Applied   []Applied
+}
+
+type Applied struct {
+	Promotion models.Promotion
+	Amount    int
+}
+
+// Applies проверяет, действует ли промоакция на покупку в момент now.
+// Лимиты использования проверяет вызывающий: для них нужны прошлые заказы.
+func Applies(p models.Promotion, l Line, now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+
+	return inScope(p.Items, l.Item) && inScope(p.Categories, l.Category) && inScope(p.Usernames, l.Username)
+}
+
+func inScope(scope []string, v string) bool {
+	return len(scope) == 0 || slices.Contains(scope, v)
+}
+
+// Price выбирает самую выгодную для покупателя скидку из подходящих промоакций promos:
+// одну любую промоакцию или все суммируемые (Stackable) вместе. Каждая скидка считается
+// от цены по прайсу, вместе они не больше нее. При равной скидке берется вариант
+// с меньшим числом промоакций, чтобы не расходовать их лимиты зря.
+func Price(l Line, promos []models.Promotion) Quote {
+	q := Quote{ListTotal: l.Price * l.Quantity}
+
+	var stack []models.Promotion
+	for _, p := range promos {
+		q = better(q, apply(q.ListTotal, p))
+		if p.Stackable {
+			stack = append(stack, p)
+		}
+	}
+	if len(stack) > 1 {
+		q = better(q, apply(q.ListTotal, stack...))
+	}
+
+	q.Total = q.ListTotal - q.Discount
+	return q
+}
+
+func apply(listTotal int, promos ...models.Promotion) Quote {
+	q := Quote{ListTotal: listTotal}
+	for _, p := range promos {
+		amount := min(discount(p, listTotal), listTotal-q.Discount)
+		if amount <= 0 {
+			continue
+		}
+		q.Discount += amount
+		q.Applied = append(q.Applied, Applied{Promotion: p, Amount: amount})
+	}
+
+	return q
+}
+
+func better(cur, next Quote) Quote {
+	if next.Discount > cur.Discount {
+		return next
+	}
+	return cur
+}
+
+// discount - скидка одной промоакции на сумму total: процент округляется вниз,
+// фиксированная скидка дается на весь заказ.
+func discount(p models.Promotion, total int) int {
+	switch p.Kind {
+	case models.PromoPercent:
+		return total * min(p.Value, 100) / 100
+	case models.PromoFixed:
+		return min(p.Value, total)
+	}
+
+	return 0
+}
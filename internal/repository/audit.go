@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// WriteAudit добавляет запись в журнал аудита вне транзакции.
+// Request id и адрес клиента берутся из контекста, если не заданы явно.
+func (r *Repository) WriteAudit(ctx context.Context, entry models.AuditEntry) error {
+	const op = "repository.WriteAudit"
+
+	if err := writeAudit(ctx, r.db, entry); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// auditFailure пишет неудачную попытку и возвращает исходную ошибку.
+// Вызывается уже после отката транзакции, поэтому запись не теряется.
+func (r *Repository) auditFailure(ctx context.Context, entry models.AuditEntry, cause error) error {
+	entry.Outcome = audit.OutcomeFailure
+	entry.Details = cause.Error()
+
+	if err := writeAudit(ctx, r.db, entry); err != nil {
+		return errors.Join(cause, fmt.Errorf("could not write audit entry: %w", err))
+	}
+
+	return cause
+}
+
+func writeAudit(ctx context.Context, db execer, entry models.AuditEntry) error {
+	meta := audit.MetaFromContext(ctx)
+	if entry.RequestID == "" {
+		entry.RequestID = meta.RequestID
+	}
+	if entry.ClientIP == "" {
+		entry.ClientIP = meta.ClientIP
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = audit.OutcomeSuccess
+	}
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO audit_log (
+			actor, action, target, amount,
+			actor_balance_before, actor_balance_after,
+			target_balance_before, target_balance_after,
+			request_id, client_ip, outcome, details
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		entry.Actor, entry.Action, entry.Target, entry.Amount,
+		entry.ActorBalanceBefore, entry.ActorBalanceAfter,
+		entry.TargetBalanceBefore, entry.TargetBalanceAfter,
+		entry.RequestID, entry.ClientIP, entry.Outcome, entry.Details,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListAudit возвращает записи журнала от новых к старым.
+// Limit = 0 снимает ограничение на количество записей.
+func (r *Repository) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	const op = "repository.ListAudit"
+
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Target != "" {
+		where("target = $%d", filter.Target)
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.Outcome != "" {
+		where("outcome = $%d", filter.Outcome)
+	}
+	if filter.RequestID != "" {
+		where("request_id = $%d", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	query := `
+		SELECT id, created_at, actor, action, target, amount,
+			actor_balance_before, actor_balance_after,
+			target_balance_before, target_balance_after,
+			request_id, client_ip, outcome, details
+		FROM audit_log`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching audit entries: %w", op, err)
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		err := rows.Scan(
+			&e.ID, &e.CreatedAt, &e.Actor, &e.Action, &e.Target, &e.Amount,
+			&e.ActorBalanceBefore, &e.ActorBalanceAfter,
+			&e.TargetBalanceBefore, &e.TargetBalanceAfter,
+			&e.RequestID, &e.ClientIP, &e.Outcome, &e.Details,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning audit entry: %w", op, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating audit entries: %w", op, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// SendCoinsBulk выполняет переводы batch.Transfers от batch.FromUser по принципу
+// все или ничего. Каждый перевод пишется отдельной записью с общим batch_id.
+func (r *Repository) SendCoinsBulk(ctx context.Context, batch models.TransferBatch) (models.TransferBatch, error) {
+	const op = "repository.SendCoinsBulk"
+
+	batch.Total = 0
+	usernames := make([]string, 0, len(batch.Transfers)+1)
+	usernames = append(usernames, batch.FromUser)
+	for i := range batch.Transfers {
+		batch.Transfers[i].FromUser = batch.FromUser
+		batch.Total += batch.Transfers[i].Amount
+		usernames = append(usernames, batch.Transfers[i].ToUser)
+	}
+
+	failure := models.AuditEntry{
+		Actor:  batch.FromUser,
+		Action: audit.ActionTransferBulk,
+		Amount: &batch.Total,
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		// все участники блокируются сразу в порядке id, переводы ниже их уже не ждут
+		employees, err := lockEmployees(ctx, tx, usernames...)
+		if err != nil {
+			return fmt.Errorf("could not fetch participants data: %w", err)
+		}
+
+		balance := employees[batch.FromUser].balance
+		failure.ActorBalanceBefore = &balance
+		failure.ActorBalanceAfter = &balance
+		if balance < batch.Total {
+			return storage.ErrInsufficientBalance
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO transfer_batches (sender_id, total, note)
+			VALUES ($1, $2, $3)
+			RETURNING id, created_at
+		`, employees[batch.FromUser].id, batch.Total, batch.Note).Scan(&batch.ID, &batch.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("could not insert transfer batch: %w", err)
+		}
+
+		for i, t := range batch.Transfers {
+			entry := models.AuditEntry{
+				Actor:   batch.FromUser,
+				Action:  audit.ActionTransfer,
+				Target:  t.ToUser,
+				Amount:  &t.Amount,
+				Details: fmt.Sprintf("batch %d", batch.ID),
+			}
+			if err := r.transfer(ctx, tx, batch.FromUser, t.ToUser, t.Amount, &batch.ID, &entry); err != nil {
+				return fmt.Errorf("transfer to %s: %w", t.ToUser, err)
+			}
+			batch.Transfers[i].BatchID = &batch.ID
+		}
+
+		return nil
+	})
+	if err != nil {
+		return batch, r.auditFailure(ctx, failure, fmt.Errorf("%s: %w", op, err))
+	}
+
+	return batch, nil
+}
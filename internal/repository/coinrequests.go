@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// Просьба, срок которой прошел, считается истекшей сразу, даже если статус
+// в таблице еще pending: он обновляется при первой попытке ответить на нее.
+const coinRequestSelect = `
+	SELECT c.id, req.username, pay.username, c.amount, c.note,
+		CASE WHEN c.status = 'pending' AND c.expires_at <= now() THEN 'expired' ELSE c.status END,
+		c.created_at, c.expires_at, c.resolved_at
+	FROM coin_requests c
+	JOIN employees req ON req.id = c.requester_id
+	JOIN employees pay ON pay.id = c.payer_id
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCoinRequest(row rowScanner) (models.CoinRequest, error) {
+	var cr models.CoinRequest
+	err := row.Scan(&cr.ID, &cr.Requester, &cr.Payer, &cr.Amount, &cr.Note,
+		&cr.Status, &cr.CreatedAt, &cr.ExpiresAt, &cr.ResolvedAt)
+	return cr, err
+}
+
+// CreateCoinRequest создает просьбу requester к payer перевести amount монет.
+// Просьба истекает через ttl.
+func (r *Repository) CreateCoinRequest(ctx context.Context, requester, payer string, amount int, note string, ttl time.Duration) (models.CoinRequest, error) {
+	const op = "repository.CreateCoinRequest"
+
+	cr := models.CoinRequest{
+		Requester: requester,
+		Payer:     payer,
+		Amount:    amount,
+		Note:      note,
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO coin_requests (requester_id, payer_id, amount, note, expires_at)
+			SELECT req.id, pay.id, $3, $4, now() + make_interval(secs => $5)
+			FROM employees req, employees pay
+			WHERE req.username = $1 AND pay.username = $2
+			RETURNING id, status, created_at, expires_at
+		`, requester, payer, amount, note, ttl.Seconds()).Scan(&cr.ID, &cr.Status, &cr.CreatedAt, &cr.ExpiresAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not insert coin request: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   requester,
+			Action:  audit.ActionCoinRequestCreate,
+			Target:  payer,
+			Amount:  &amount,
+			Details: fmt.Sprintf("coin request %d", cr.ID),
+		})
+	})
+	if err != nil {
+		return cr, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cr, nil
+}
+
+// ListCoinRequests возвращает просьбы сотрудника от новых к старым.
+func (r *Repository) ListCoinRequests(ctx context.Context, filter models.CoinRequestFilter) ([]models.CoinRequest, error) {
+	const op = "repository.ListCoinRequests"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT * FROM (`+coinRequestSelect+`
+			WHERE ($2::text <> 'incoming' AND req.username = $1)
+				OR ($2::text <> 'outgoing' AND pay.username = $1)
+		) c (id, requester, payer, amount, note, status, created_at, expires_at, resolved_at)
+		WHERE $3::text = '' OR status = $3::text
+		ORDER BY id DESC
+		LIMIT $4
+	`, filter.Username, filter.Role, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching coin requests: %w", op, err)
+	}
+	defer rows.Close()
+
+	requests := []models.CoinRequest{}
+	for rows.Next() {
+		cr, err := scanCoinRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning coin request: %w", op, err)
+		}
+		requests = append(requests, cr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating coin requests: %w", op, err)
+	}
+
+	return requests, nil
+}
+
+// AcceptCoinRequest переводит монеты по просьбе в одной транзакции с
+// изменением ее статуса. Ответить может только плательщик.
+func (r *Repository) AcceptCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error) {
+	const op = "repository.AcceptCoinRequest"
+
+	var cr models.CoinRequest
+	entry := models.AuditEntry{
+		Actor:   payer,
+		Action:  audit.ActionTransfer,
+		Details: fmt.Sprintf("coin request %d", id),
+	}
+
+	expired := false
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		cr, err = lockCoinRequest(ctx, tx, payer, id)
+		if err != nil {
+			return err
+		}
+		entry.Target = cr.Requester
+		entry.Amount = &cr.Amount
+
+		if cr.Status == models.CoinRequestExpired {
+			expired = true
+			return expireCoinRequest(ctx, tx, cr.ID)
+		}
+		if cr.Status != models.CoinRequestPending {
+			return storage.ErrCoinRequestResolved
+		}
+
+		if err := r.transfer(ctx, tx, payer, cr.Requester, cr.Amount, nil, &entry); err != nil {
+			return err
+		}
+
+		return resolveCoinRequest(ctx, tx, &cr, models.CoinRequestAccepted)
+	})
+	if err == nil && expired {
+		err = storage.ErrCoinRequestExpired
+	}
+	if err != nil {
+		err = fmt.Errorf("%s: %w", op, err)
+		if entry.Target == "" {
+			return cr, err
+		}
+		entry.ActorBalanceAfter = entry.ActorBalanceBefore
+		entry.TargetBalanceAfter = entry.TargetBalanceBefore
+		return cr, r.auditFailure(ctx, entry, err)
+	}
+
+	return cr, nil
+}
+
+// DeclineCoinRequest отклоняет просьбу. Ответить может только плательщик.
+func (r *Repository) DeclineCoinRequest(ctx context.Context, payer string, id int64) (models.CoinRequest, error) {
+	const op = "repository.DeclineCoinRequest"
+
+	var cr models.CoinRequest
+
+	expired := false
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		cr, err = lockCoinRequest(ctx, tx, payer, id)
+		if err != nil {
+			return err
+		}
+
+		if cr.Status == models.CoinRequestExpired {
+			expired = true
+			return expireCoinRequest(ctx, tx, cr.ID)
+		}
+		if cr.Status != models.CoinRequestPending {
+			return storage.ErrCoinRequestResolved
+		}
+
+		if err := resolveCoinRequest(ctx, tx, &cr, models.CoinRequestDeclined); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   payer,
+			Action:  audit.ActionCoinRequestDecline,
+			Target:  cr.Requester,
+			Amount:  &cr.Amount,
+			Details: fmt.Sprintf("coin request %d", cr.ID),
+		})
+	})
+	if err == nil && expired {
+		err = storage.ErrCoinRequestExpired
+	}
+	if err != nil {
+		return cr, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cr, nil
+}
+
+// lockCoinRequest блокирует просьбу, адресованную payer.
+func lockCoinRequest(ctx context.Context, tx *sql.Tx, payer string, id int64) (models.CoinRequest, error) {
+	cr, err := scanCoinRequest(tx.QueryRowContext(ctx, coinRequestSelect+`
+		WHERE c.id = $1 AND pay.username = $2
+		FOR UPDATE OF c
+	`, id, payer))
+	if errors.Is(err, sql.ErrNoRows) {
+		return cr, storage.ErrCoinRequestNotFound
+	}
+	if err != nil {
+		return cr, fmt.Errorf("could not fetch coin request: %w", err)
+	}
+
+	return cr, nil
+}
+
+func expireCoinRequest(ctx context.Context, tx *sql.Tx, id int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE coin_requests
+		SET status = 'expired', resolved_at = expires_at
+		WHERE id = $1 AND status = 'pending'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("could not expire coin request: %w", err)
+	}
+
+	return nil
+}
+
+func resolveCoinRequest(ctx context.Context, tx *sql.Tx, cr *models.CoinRequest, status string) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE coin_requests
+		SET status = $2, resolved_at = now()
+		WHERE id = $1
+		RETURNING status, resolved_at
+	`, cr.ID, status).Scan(&cr.Status, &cr.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("could not update coin request status: %w", err)
+	}
+
+	return nil
+}
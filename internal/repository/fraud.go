@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+const fraudFlagSelect = `
+	SELECT f.id, e.username, f.rule, f.details, f.transaction_ids, f.status,
+		e.transfers_held, f.created_at, COALESCE(f.resolved_by, ''), f.resolved_at
+	FROM fraud_flags f
+	JOIN employees e ON e.id = f.employee_id
+`
+
+func scanFraudFlag(row rowScanner) (models.FraudFlag, error) {
+	var f models.FraudFlag
+	var ids pq.Int64Array
+	err := row.Scan(&f.ID, &f.Username, &f.Rule, &f.Details, &ids, &f.Status,
+		&f.TransfersHeld, &f.CreatedAt, &f.ResolvedBy, &f.ResolvedAt)
+	f.TransactionIDs = ids
+	return f, err
+}
+
+// FraudCursor возвращает последний перевод, который уже проверил инкрементальный анализ.
+func (r *Repository) FraudCursor(ctx context.Context) (int64, error) {
+	const op = "repository.FraudCursor"
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, "SELECT last_transaction_id FROM fraud_cursor").Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// TransfersSince возвращает переводы сотрудников после since по возрастанию id.
+// Переводы системных сотрудников (гранты из казны) не анализируются.
+func (r *Repository) TransfersSince(ctx context.Context, since time.Time) ([]models.TransferRecord, error) {
+	const op = "repository.TransfersSince"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, s.username, rc.username, t.amount, t.created_at, s.created_at
+		FROM transactions t
+		JOIN employees s ON s.id = t.sender_id
+		JOIN employees rc ON rc.id = t.receiver_id
+		WHERE t.created_at >= $1 AND NOT s.is_system
+		ORDER BY t.id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var txs []models.TransferRecord
+	for rows.Next() {
+		var t models.TransferRecord
+		if err := rows.Scan(&t.ID, &t.FromUser, &t.ToUser, &t.Amount, &t.CreatedAt, &t.FromCreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		txs = append(txs, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return txs, nil
+}
+
+// SaveFraudFindings отмечает участников найденных схем и сдвигает курсор
+// анализа на lastID. Открытая отметка той же схемы дополняется новыми переводами,
+// а схема, которую администратор уже разобрал, отмечается снова, только если
+// в ней появились новые переводы. hold запрещает отмеченным исходящие переводы.
+// Возвращает число новых отметок.
+func (r *Repository) SaveFraudFindings(ctx context.Context, actor string, findings []models.FraudFinding, lastID int64, hold bool) (int, error) {
+	const op = "repository.SaveFraudFindings"
+
+	created := 0
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for _, f := range findings {
+			fingerprint := f.Rule + ":" + f.Key
+
+			for _, username := range f.Usernames {
+				var id int64
+				var inserted bool
+				err := tx.QueryRowContext(ctx, `
+					INSERT INTO fraud_flags (employee_id, rule, fingerprint, details, transaction_ids)
+					SELECT e.id, $2, $3, $4, $5
+					FROM employees e
+					WHERE e.username = $1 AND NOT e.is_system
+						AND NOT EXISTS (
+							SELECT 1 FROM fraud_flags old
+							WHERE old.employee_id = e.id AND old.fingerprint = $3
+								AND old.status <> 'open' AND old.transaction_ids @> $5
+						)
+					ON CONFLICT (employee_id, fingerprint) WHERE status = 'open' DO UPDATE
+					SET details = EXCLUDED.details,
+						transaction_ids = ARRAY(
+							SELECT DISTINCT unnest(fraud_flags.transaction_ids || EXCLUDED.transaction_ids) ORDER BY 1
+						)
+					RETURNING id, xmax = 0
+				`, username, f.Rule, fingerprint, f.Details, pq.Array(f.TransactionIDs)).Scan(&id, &inserted)
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("could not save fraud flag: %w", err)
+				}
+				if !inserted {
+					continue
+				}
+				created++
+
+				if hold {
+					_, err := tx.ExecContext(ctx, "UPDATE employees SET transfers_held = true WHERE username = $1", username)
+					if err != nil {
+						return fmt.Errorf("could not hold transfers: %w", err)
+					}
+				}
+
+				err = writeAudit(ctx, tx, models.AuditEntry{
+					Actor:   actor,
+					Action:  audit.ActionFraudFlag,
+					Target:  username,
+					Details: fmt.Sprintf("flag %d %s: %s", id, f.Rule, f.Details),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE fraud_cursor SET last_transaction_id = GREATEST(last_transaction_id, $1)
+		`, lastID)
+		if err != nil {
+			return fmt.Errorf("could not move fraud cursor: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+// ListFraudFlags возвращает отметки от новых к старым.
+func (r *Repository) ListFraudFlags(ctx context.Context, filter models.FraudFlagFilter) ([]models.FraudFlag, error) {
+	const op = "repository.ListFraudFlags"
+
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Username != "" {
+		where("e.username = $%d", filter.Username)
+	}
+	if filter.Status != "" {
+		where("f.status = $%d", filter.Status)
+	}
+	if filter.Rule != "" {
+		where("f.rule = $%d", filter.Rule)
+	}
+
+	query := fraudFlagSelect
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY f.id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	flags := []models.FraudFlag{}
+	for rows.Next() {
+		f, err := scanFraudFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		flags = append(flags, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return flags, nil
+}
+
+// ResolveFraudFlag закрывает открытую отметку: FraudFlagDismissed - ложная тревога,
+// FraudFlagConfirmed - мошенничество подтверждено. После отклонения последней
+// открытой отметки сотруднику снова разрешаются переводы; подтверждение запрет
+// не снимает, его снимает администратор через PATCH сотрудника.
+func (r *Repository) ResolveFraudFlag(ctx context.Context, actor string, id int64, status string) (models.FraudFlag, error) {
+	const op = "repository.ResolveFraudFlag"
+
+	var f models.FraudFlag
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var employeeID int
+		err := tx.QueryRowContext(ctx, `
+			SELECT employee_id, status FROM fraud_flags WHERE id = $1 FOR UPDATE
+		`, id).Scan(&employeeID, &f.Status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrFraudFlagNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch fraud flag: %w", err)
+		}
+		if f.Status != models.FraudFlagOpen {
+			return storage.ErrFraudFlagResolved
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE fraud_flags SET status = $2, resolved_by = $3, resolved_at = now() WHERE id = $1
+		`, id, status, actor)
+		if err != nil {
+			return fmt.Errorf("could not resolve fraud flag: %w", err)
+		}
+
+		if status == models.FraudFlagDismissed {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE employees SET transfers_held = false
+				WHERE id = $1 AND NOT EXISTS (
+					SELECT 1 FROM fraud_flags WHERE employee_id = $1 AND status = 'open'
+				)
+			`, employeeID)
+			if err != nil {
+				return fmt.Errorf("could not release transfers: %w", err)
+			}
+		}
+
+		f, err = scanFraudFlag(tx.QueryRowContext(ctx, fraudFlagSelect+" WHERE f.id = $1", id))
+		if err != nil {
+			return fmt.Errorf("could not fetch fraud flag: %w", err)
+		}
+
+		action := audit.ActionFraudDismiss
+		if status == models.FraudFlagConfirmed {
+			action = audit.ActionFraudConfirm
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  action,
+			Target:  f.Username,
+			Details: fmt.Sprintf("flag %d %s", f.ID, f.Rule),
+		})
+	})
+	if err != nil {
+		return f, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// Recipients и Total считаются по уже сделанным выплатам.
+const grantCampaignSelect = `
+	SELECT g.id, g.name, g.amount, g.department, g.note, g.status, g.scheduled_at,
+		g.created_by, g.created_at, g.executed_at, g.last_error,
+		COUNT(p.employee_id), COALESCE(SUM(p.amount), 0)
+	FROM grant_campaigns g
+	LEFT JOIN grant_payouts p ON p.campaign_id = g.id
+`
+
+func scanGrantCampaign(row rowScanner) (models.GrantCampaign, error) {
+	var c models.GrantCampaign
+	err := row.Scan(&c.ID, &c.Name, &c.Amount, &c.Department, &c.Note, &c.Status, &c.ScheduledAt,
+		&c.CreatedBy, &c.CreatedAt, &c.ExecutedAt, &c.LastError, &c.Recipients, &c.Total)
+	return c, err
+}
+
+func (r *Repository) CreateGrantCampaign(ctx context.Context, c models.GrantCampaign) (models.GrantCampaign, error) {
+	const op = "repository.CreateGrantCampaign"
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO grant_campaigns (name, amount, department, note, status, scheduled_at, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at
+		`, c.Name, c.Amount, c.Department, c.Note, c.Status, c.ScheduledAt, c.CreatedBy).Scan(&c.ID, &c.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("could not insert grant campaign: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   c.CreatedBy,
+			Action:  audit.ActionGrantCreate,
+			Target:  c.Name,
+			Amount:  &c.Amount,
+			Details: fmt.Sprintf("grant campaign %d", c.ID),
+		})
+	})
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) ListGrantCampaigns(ctx context.Context) ([]models.GrantCampaign, error) {
+	const op = "repository.ListGrantCampaigns"
+
+	return r.queryGrantCampaigns(ctx, op, `
+		GROUP BY g.id
+		ORDER BY g.id DESC
+	`)
+}
+
+func (r *Repository) GetGrantCampaign(ctx context.Context, id int64) (models.GrantCampaign, error) {
+	const op = "repository.GetGrantCampaign"
+
+	c, err := scanGrantCampaign(r.db.QueryRowContext(ctx, grantCampaignSelect+`
+		WHERE g.id = $1
+		GROUP BY g.id
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, fmt.Errorf("%s: %w", op, storage.ErrGrantNotFound)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%s: error fetching grant campaign: %w", op, err)
+	}
+
+	return c, nil
+}
+
+// DeleteGrantCampaign удаляет кампанию, по которой еще ничего не выплачено.
+func (r *Repository) DeleteGrantCampaign(ctx context.Context, actor string, id int64) error {
+	const op = "repository.DeleteGrantCampaign"
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var (
+			name string
+			paid bool
+		)
+		err := tx.QueryRowContext(ctx, `
+			SELECT g.name, EXISTS (SELECT 1 FROM grant_payouts p WHERE p.campaign_id = g.id)
+			FROM grant_campaigns g
+			WHERE g.id = $1
+			FOR UPDATE
+		`, id).Scan(&name, &paid)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrGrantNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch grant campaign: %w", err)
+		}
+		// выплаты удалились бы вместе с кампанией и отчет бы потерялся
+		if paid {
+			return storage.ErrGrantExecuted
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM grant_campaigns WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("could not delete grant campaign: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionGrantDelete,
+			Target:  name,
+			Details: fmt.Sprintf("grant campaign %d", id),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// PreviewGrantCampaign показывает, кому заплатит следующий запуск кампании.
+func (r *Repository) PreviewGrantCampaign(ctx context.Context, id int64) (models.GrantPreview, error) {
+	const op = "repository.PreviewGrantCampaign"
+
+	preview := models.GrantPreview{CampaignID: id, Recipients: []string{}}
+
+	c, err := r.GetGrantCampaign(ctx, id)
+	if err != nil {
+		return preview, fmt.Errorf("%s: %w", op, err)
+	}
+
+	recipients, err := grantRecipients(ctx, r.db, c)
+	if err != nil {
+		return preview, fmt.Errorf("%s: %w", op, err)
+	}
+	preview.Recipients = append(preview.Recipients, recipients...)
+	preview.Total = len(recipients) * c.Amount
+
+	preview.TreasuryBalance, err = r.TreasuryBalance(ctx)
+	if err != nil {
+		return preview, fmt.Errorf("%s: %w", op, err)
+	}
+	preview.Sufficient = preview.TreasuryBalance >= preview.Total
+
+	return preview, nil
+}
+
+// ExecuteGrantCampaign выплачивает грант из казны всем подходящим сотрудникам,
+// которые его еще не получили, одной транзакцией и одним массовым переводом.
+// Повторный запуск безопасен: уже получившим сотрудникам он не платит.
+// При ошибке кампания запоминает ее, а запланированная кампания получает статус failed.
+func (r *Repository) ExecuteGrantCampaign(ctx context.Context, actor string, id int64) (models.GrantRun, error) {
+	const op = "repository.ExecuteGrantCampaign"
+
+	run := models.GrantRun{CampaignID: id}
+	entry := models.AuditEntry{
+		Actor:   actor,
+		Action:  audit.ActionGrantExecute,
+		Details: fmt.Sprintf("grant campaign %d", id),
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		// блокировка кампании не дает двум запускам выплатить одно и то же
+		c, err := scanGrantCampaign(tx.QueryRowContext(ctx, grantCampaignSelect+`
+			WHERE g.id = $1
+			GROUP BY g.id
+			FOR UPDATE OF g
+		`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrGrantNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch grant campaign: %w", err)
+		}
+		entry.Target = c.Name
+
+		recipients, err := grantRecipients(ctx, tx, c)
+		if err != nil {
+			return err
+		}
+		run.Recipients = len(recipients)
+		run.Total = len(recipients) * c.Amount
+		entry.Amount = &run.Total
+
+		if len(recipients) > 0 {
+			batchID, err := r.payGrant(ctx, tx, c, recipients, run.Total)
+			if err != nil {
+				return err
+			}
+			run.BatchID = &batchID
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE grant_campaigns
+			SET status = $2, executed_at = now(), last_error = ''
+			WHERE id = $1
+		`, id, models.GrantCompleted)
+		if err != nil {
+			return fmt.Errorf("could not update grant campaign: %w", err)
+		}
+
+		entry.Details = fmt.Sprintf("grant campaign %d: %d recipients", id, run.Recipients)
+		return writeAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		err = fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, storage.ErrGrantNotFound) {
+			return run, err
+		}
+		if _, markErr := r.db.ExecContext(ctx, `
+			UPDATE grant_campaigns
+			SET last_error = $2, status = CASE WHEN status = $3 THEN $4 ELSE status END
+			WHERE id = $1
+		`, id, err.Error(), models.GrantScheduled, models.GrantFailed); markErr != nil {
+			err = errors.Join(err, fmt.Errorf("could not save grant campaign error: %w", markErr))
+		}
+		return run, r.auditFailure(ctx, entry, err)
+	}
+
+	return run, nil
+}
+
+// GrantCampaignReport возвращает кампанию и все выплаты по ней.
+func (r *Repository) GrantCampaignReport(ctx context.Context, id int64) (models.GrantReport, error) {
+	const op = "repository.GrantCampaignReport"
+
+	report := models.GrantReport{Payouts: []models.GrantPayout{}}
+
+	var err error
+	report.Campaign, err = r.GetGrantCampaign(ctx, id)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.username, p.amount, p.batch_id, p.paid_at
+		FROM grant_payouts p
+		JOIN employees e ON e.id = p.employee_id
+		WHERE p.campaign_id = $1
+		ORDER BY p.paid_at, e.username
+	`, id)
+	if err != nil {
+		return report, fmt.Errorf("%s: error fetching grant payouts: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.GrantPayout
+		if err := rows.Scan(&p.Username, &p.Amount, &p.BatchID, &p.PaidAt); err != nil {
+			return report, fmt.Errorf("%s: error scanning grant payout: %w", op, err)
+		}
+		report.Payouts = append(report.Payouts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return report, fmt.Errorf("%s: error iterating grant payouts: %w", op, err)
+	}
+
+	return report, nil
+}
+
+// DueGrantCampaigns возвращает запланированные кампании, время которых наступило.
+func (r *Repository) DueGrantCampaigns(ctx context.Context, limit int) ([]models.GrantCampaign, error) {
+	const op = "repository.DueGrantCampaigns"
+
+	return r.queryGrantCampaigns(ctx, op, `
+		WHERE g.status = $1 AND g.scheduled_at <= $2
+		GROUP BY g.id
+		ORDER BY g.scheduled_at
+		LIMIT $3
+	`, models.GrantScheduled, time.Now(), limit)
+}
+
+func (r *Repository) queryGrantCampaigns(ctx context.Context, op, tail string, args ...any) ([]models.GrantCampaign, error) {
+	rows, err := r.db.QueryContext(ctx, grantCampaignSelect+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching grant campaigns: %w", op, err)
+	}
+	defer rows.Close()
+
+	campaigns := []models.GrantCampaign{}
+	for rows.Next() {
+		c, err := scanGrantCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning grant campaign: %w", op, err)
+		}
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating grant campaigns: %w", op, err)
+	}
+
+	return campaigns, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// grantRecipients - активные несистемные сотрудники (из отдела кампании),
+// которые еще не получили грант.
+func grantRecipients(ctx context.Context, db queryer, c models.GrantCampaign) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT e.username
+		FROM employees e
+		WHERE e.active AND NOT e.is_system
+			AND ($2 = '' OR e.department = $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM grant_payouts p
+				WHERE p.campaign_id = $1 AND p.employee_id = e.id
+			)
+		ORDER BY e.id
+	`, c.ID, c.Department)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch grant recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("could not scan grant recipient: %w", err)
+		}
+		usernames = append(usernames, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate grant recipients: %w", err)
+	}
+
+	return usernames, nil
+}
+
+// payGrant переводит грант из казны каждому получателю в рамках одного массового перевода.
+func (r *Repository) payGrant(ctx context.Context, tx *sql.Tx, c models.GrantCampaign, recipients []string, total int) (int64, error) {
+	// казна и все получатели блокируются сразу в порядке id
+	employees, err := lockEmployees(ctx, tx, append([]string{models.TreasuryUsername}, recipients...)...)
+	if err != nil {
+		return 0, fmt.Errorf("could not fetch participants data: %w", err)
+	}
+	treasury := employees[models.TreasuryUsername]
+	if !treasury.system {
+		return 0, fmt.Errorf("%s is not a system account: %w", models.TreasuryUsername, storage.ErrUserNotFound)
+	}
+	if treasury.balance < total {
+		return 0, fmt.Errorf("treasury has %d of %d coins: %w", treasury.balance, total, storage.ErrInsufficientBalance)
+	}
+
+	var batchID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transfer_batches (sender_id, total, note)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, treasury.id, total, c.Name).Scan(&batchID)
+	if err != nil {
+		return 0, fmt.Errorf("could not insert transfer batch: %w", err)
+	}
+
+	for _, username := range recipients {
+		entry := models.AuditEntry{
+			Actor:   models.TreasuryUsername,
+			Action:  audit.ActionTransfer,
+			Target:  username,
+			Amount:  &c.Amount,
+			Details: fmt.Sprintf("grant campaign %d", c.ID),
+		}
+		if err := r.transfer(ctx, tx, models.TreasuryUsername, username, c.Amount, &batchID, &entry); err != nil {
+			return 0, fmt.Errorf("grant to %s: %w", username, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO grant_payouts (campaign_id, employee_id, amount, batch_id)
+			VALUES ($1, $2, $3, $4)
+		`, c.ID, employees[username].id, c.Amount, batchID)
+		if err != nil {
+			return 0, fmt.Errorf("could not insert grant payout: %w", err)
+		}
+	}
+
+	return batchID, nil
+}
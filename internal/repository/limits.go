@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// limitWindows возвращает начало текущих суток и недели (с понедельника) в UTC.
+func limitWindows(now time.Time) (day, week time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	week = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return day, week
+}
+
+// effectiveLimits накладывает переопределения сотрудника на лимиты из конфига.
+func (r *Repository) effectiveLimits(o models.TransferLimitOverride) models.TransferLimits {
+	l := models.TransferLimits{
+		MaxTransfer:     r.limits.MaxTransfer,
+		Daily:           r.limits.Daily,
+		Weekly:          r.limits.Weekly,
+		DailyRecipients: r.limits.DailyRecipients,
+	}
+	if o.MaxTransfer != nil {
+		l.MaxTransfer = *o.MaxTransfer
+	}
+	if o.Daily != nil {
+		l.Daily = *o.Daily
+	}
+	if o.Weekly != nil {
+		l.Weekly = *o.Weekly
+	}
+	if o.DailyRecipients != nil {
+		l.DailyRecipients = *o.DailyRecipients
+	}
+
+	return l
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func limitOverride(ctx context.Context, db queryRower, employeeID int) (models.TransferLimitOverride, error) {
+	var o models.TransferLimitOverride
+	err := db.QueryRowContext(ctx, `
+		SELECT max_transfer, daily, weekly, daily_recipients
+		FROM transfer_limit_overrides
+		WHERE employee_id = $1
+	`, employeeID).Scan(&o.MaxTransfer, &o.Daily, &o.Weekly, &o.DailyRecipients)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return o, fmt.Errorf("could not fetch transfer limit override: %w", err)
+	}
+
+	return o, nil
+}
+
+// transferUsage считает исходящие переводы сотрудника в текущих окнах лимитов.
+// toID - получатель нового перевода: если ему уже переводили сегодня, known = true.
+func transferUsage(ctx context.Context, db queryRower, employeeID, toID int, now time.Time) (usage models.TransferUsage, known bool, err error) {
+	day, week := limitWindows(now)
+	usage.DayResetsAt = day.AddDate(0, 0, 1)
+	usage.WeekResetsAt = week.AddDate(0, 0, 7)
+
+	err = db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE created_at >= $2), 0),
+			COALESCE(SUM(amount), 0),
+			COUNT(DISTINCT receiver_id) FILTER (WHERE created_at >= $2),
+			COALESCE(bool_or(receiver_id = $4 AND created_at >= $2), false)
+		FROM transactions
+		WHERE sender_id = $1 AND created_at >= $3
+	`, employeeID, day, week, toID).Scan(&usage.Daily, &usage.Weekly, &usage.DailyRecipients, &known)
+	if err != nil {
+		return usage, false, fmt.Errorf("could not count outgoing transfers: %w", err)
+	}
+
+	return usage, known, nil
+}
+
+// checkLimits проверяет, что перевод amount монет от senderID к receiverID
+// укладывается в лимиты отправителя. Строка отправителя должна быть заблокирована,
+// тогда параллельные переводы не обойдут лимит. Ничего не меняет.
+func (r *Repository) checkLimits(ctx context.Context, tx *sql.Tx, senderID, receiverID, amount int, now time.Time) error {
+	override, err := limitOverride(ctx, tx, senderID)
+	if err != nil {
+		return err
+	}
+	limits := r.effectiveLimits(override)
+
+	if limits.MaxTransfer > 0 && amount > limits.MaxTransfer {
+		return &storage.LimitError{Limit: models.LimitPerTransfer, Max: limits.MaxTransfer}
+	}
+	if limits.Daily == 0 && limits.Weekly == 0 && limits.DailyRecipients == 0 {
+		return nil
+	}
+
+	usage, known, err := transferUsage(ctx, tx, senderID, receiverID, now)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case limits.Daily > 0 && usage.Daily+amount > limits.Daily:
+		return &storage.LimitError{Limit: models.LimitDaily, Max: limits.Daily, ResetsAt: &usage.DayResetsAt}
+	case limits.Weekly > 0 && usage.Weekly+amount > limits.Weekly:
+		return &storage.LimitError{Limit: models.LimitWeekly, Max: limits.Weekly, ResetsAt: &usage.WeekResetsAt}
+	case limits.DailyRecipients > 0 && !known && usage.DailyRecipients >= limits.DailyRecipients:
+		return &storage.LimitError{Limit: models.LimitDailyRecipients, Max: limits.DailyRecipients, ResetsAt: &usage.DayResetsAt}
+	}
+
+	return nil
+}
+
+// GetEmployeeLimits возвращает действующие лимиты сотрудника и расход в текущих окнах.
+func (r *Repository) GetEmployeeLimits(ctx context.Context, username string) (models.EmployeeLimits, error) {
+	const op = "repository.GetEmployeeLimits"
+
+	l := models.EmployeeLimits{Username: username}
+
+	var id int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM employees WHERE username = $1 AND NOT is_system
+	`, username).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return l, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return l, fmt.Errorf("%s: error fetching employee: %w", op, err)
+	}
+
+	l.Override, err = limitOverride(ctx, r.db, id)
+	if err != nil {
+		return l, fmt.Errorf("%s: %w", op, err)
+	}
+	l.Limits = r.effectiveLimits(l.Override)
+
+	l.Usage, _, err = transferUsage(ctx, r.db, id, 0, time.Now())
+	if err != nil {
+		return l, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return l, nil
+}
+
+// SetTransferLimits заменяет переопределения лимитов сотрудника.
+// Пустой override возвращает сотруднику лимиты из конфига.
+func (r *Repository) SetTransferLimits(ctx context.Context, actor, username string, override models.TransferLimitOverride) (models.EmployeeLimits, error) {
+	const op = "repository.SetTransferLimits"
+
+	entry := models.AuditEntry{
+		Actor:   actor,
+		Action:  audit.ActionAdminLimits,
+		Target:  username,
+		Details: formatOverride(override),
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var id int
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM employees WHERE username = $1 AND NOT is_system
+		`, username).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch employee: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO transfer_limit_overrides (employee_id, max_transfer, daily, weekly, daily_recipients, updated_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (employee_id) DO UPDATE
+			SET max_transfer = EXCLUDED.max_transfer, daily = EXCLUDED.daily, weekly = EXCLUDED.weekly,
+				daily_recipients = EXCLUDED.daily_recipients, updated_by = EXCLUDED.updated_by, updated_at = now()
+		`, id, override.MaxTransfer, override.Daily, override.Weekly, override.DailyRecipients, actor)
+		if err != nil {
+			return fmt.Errorf("could not save transfer limit override: %w", err)
+		}
+
+		return writeAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return models.EmployeeLimits{}, r.auditFailure(ctx, entry, fmt.Errorf("%s: %w", op, err))
+	}
+
+	return r.GetEmployeeLimits(ctx, username)
+}
+
+func formatOverride(o models.TransferLimitOverride) string {
+	value := func(v *int) string {
+		if v == nil {
+			return "default"
+		}
+		return fmt.Sprint(*v)
+	}
+
+	return fmt.Sprintf("maxTransfer=%s daily=%s weekly=%s dailyRecipients=%s",
+		value(o.MaxTransfer), value(o.Daily), value(o.Weekly), value(o.DailyRecipients))
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// Монеты сотрудника хранятся лотами (coin_lots), у каждого свой срок годности.
+// employees.balance - сумма лотов, ее по-прежнему меняет тот, кто меняет лоты.
+// Каждое изменение лота пишется в ledger_entries.
+
+// ledgerRef - к чему относится запись журнала движения монет.
+type ledgerRef struct {
+	kind          string
+	transactionID *int64
+	details       string
+}
+
+// lotPart - сколько монет взять из одного лота.
+type lotPart struct {
+	lotID     int64
+	amount    int
+	expiresAt *time.Time
+}
+
+// lotExpiry возвращает срок нового лота: LotTTL от now, а при переводе
+// с правилом keep - срок лота отправителя from. nil - лот не сгорает.
+func (r *Repository) lotExpiry(now time.Time, from *time.Time) *time.Time {
+	if from != nil && r.coins.TransferExpiry == config.LotExpiryKeep {
+		return from
+	}
+	if r.coins.LotTTL <= 0 {
+		return nil
+	}
+
+	expiresAt := now.Add(r.coins.LotTTL)
+	return &expiresAt
+}
+
+// takeLots блокирует лоты сотрудника и выбирает, из каких взять amount монет:
+// сначала те, что сгорят раньше, бессрочные - последними. Просроченные,
+// но еще не списанные лоты не используются. Сами лоты не меняются, поэтому
+// после ErrInsufficientBalance транзакцию можно продолжать.
+func takeLots(ctx context.Context, tx *sql.Tx, employeeID, amount int, now time.Time) ([]lotPart, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, amount, expires_at
+		FROM coin_lots
+		WHERE employee_id = $1 AND amount > 0 AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY expires_at NULLS LAST, id
+		FOR UPDATE
+	`, employeeID, now)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch coin lots: %w", err)
+	}
+	defer rows.Close()
+
+	var parts []lotPart
+	left := amount
+	for rows.Next() {
+		var p lotPart
+		if err := rows.Scan(&p.lotID, &p.amount, &p.expiresAt); err != nil {
+			return nil, fmt.Errorf("could not scan coin lot: %w", err)
+		}
+		if left == 0 {
+			continue
+		}
+		p.amount = min(p.amount, left)
+		left -= p.amount
+		parts = append(parts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate coin lots: %w", err)
+	}
+
+	if left > 0 {
+		return nil, fmt.Errorf("%d of %d coins are expired or missing: %w", left, amount, storage.ErrInsufficientBalance)
+	}
+
+	return parts, nil
+}
+
+// debitLots списывает выбранные takeLots части лотов.
+func debitLots(ctx context.Context, tx *sql.Tx, employeeID int, parts []lotPart, ref ledgerRef) error {
+	for _, p := range parts {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE coin_lots SET amount = amount - $2 WHERE id = $1
+		`, p.lotID, p.amount)
+		if err != nil {
+			return fmt.Errorf("could not update coin lot: %w", err)
+		}
+
+		if err := addLedgerEntry(ctx, tx, employeeID, p.lotID, -p.amount, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// creditLot начисляет сотруднику amount монет новым лотом.
+func creditLot(ctx context.Context, tx *sql.Tx, employeeID, amount int, source string, expiresAt *time.Time, ref ledgerRef) error {
+	var lotID int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO coin_lots (employee_id, amount, initial_amount, source, expires_at)
+		VALUES ($1, $2, $2, $3, $4)
+		RETURNING id
+	`, employeeID, amount, source, expiresAt).Scan(&lotID)
+	if err != nil {
+		return fmt.Errorf("could not insert coin lot: %w", err)
+	}
+
+	return addLedgerEntry(ctx, tx, employeeID, lotID, amount, ref)
+}
+
+// moveLots списывает части лотов отправителя и начисляет их получателю.
+// При правиле keep части с одинаковым сроком становятся одним лотом,
+// при reset получатель получает один лот с новым сроком.
+func (r *Repository) moveLots(ctx context.Context, tx *sql.Tx, senderID, receiverID int, parts []lotPart, transactionID int64, now time.Time) error {
+	err := debitLots(ctx, tx, senderID, parts, ledgerRef{kind: models.LedgerTransferOut, transactionID: &transactionID})
+	if err != nil {
+		return err
+	}
+
+	ref := ledgerRef{kind: models.LedgerTransferIn, transactionID: &transactionID}
+	for i := 0; i < len(parts); {
+		expiresAt := r.lotExpiry(now, parts[i].expiresAt)
+		amount := 0
+		for ; i < len(parts) && sameExpiry(r.lotExpiry(now, parts[i].expiresAt), expiresAt); i++ {
+			amount += parts[i].amount
+		}
+
+		if err := creditLot(ctx, tx, receiverID, amount, models.LotTransfer, expiresAt, ref); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func addLedgerEntry(ctx context.Context, tx *sql.Tx, employeeID int, lotID int64, delta int, ref ledgerRef) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO ledger_entries (employee_id, lot_id, delta, kind, transaction_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, employeeID, lotID, delta, ref.kind, ref.transactionID, ref.details)
+	if err != nil {
+		return fmt.Errorf("could not insert ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetExpiringCoins возвращает, сколько монет сотрудника сгорит и в какой день.
+func (r *Repository) GetExpiringCoins(userID int) ([]models.ExpiringCoins, error) {
+	const op = "repository.GetExpiringCoins"
+
+	rows, err := r.db.Query(`
+		SELECT to_char((expires_at AT TIME ZONE 'UTC')::date, 'YYYY-MM-DD') AS day, SUM(amount)
+		FROM coin_lots
+		WHERE employee_id = $1 AND amount > 0 AND expires_at IS NOT NULL
+		GROUP BY day
+		ORDER BY day
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching expiring coins: %w", op, err)
+	}
+	defer rows.Close()
+
+	expiring := []models.ExpiringCoins{}
+	for rows.Next() {
+		var e models.ExpiringCoins
+		if err := rows.Scan(&e.ExpiresOn, &e.Amount); err != nil {
+			return nil, fmt.Errorf("%s: error scanning expiring coins: %w", op, err)
+		}
+		expiring = append(expiring, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating expiring coins: %w", op, err)
+	}
+
+	return expiring, nil
+}
+
+// DueCoinLots возвращает непустые лоты, срок которых наступил к now.
+func (r *Repository) DueCoinLots(ctx context.Context, now time.Time, limit int) ([]models.CoinLot, error) {
+	const op = "repository.DueCoinLots"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, e.username, l.amount, l.expires_at
+		FROM coin_lots l
+		JOIN employees e ON e.id = l.employee_id
+		WHERE l.amount > 0 AND l.expires_at <= $1
+		ORDER BY l.expires_at
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching due coin lots: %w", op, err)
+	}
+	defer rows.Close()
+
+	lots := []models.CoinLot{}
+	for rows.Next() {
+		var l models.CoinLot
+		if err := rows.Scan(&l.ID, &l.Username, &l.Amount, &l.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("%s: error scanning coin lot: %w", op, err)
+		}
+		lots = append(lots, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating coin lots: %w", op, err)
+	}
+
+	return lots, nil
+}
+
+// ExpireCoinLot списывает остаток просроченного лота с баланса сотрудника.
+// Если лот уже потрачен или списан (например, другой репликой), ничего не делает.
+func (r *Repository) ExpireCoinLot(ctx context.Context, actor string, lot models.CoinLot, now time.Time) error {
+	const op = "repository.ExpireCoinLot"
+
+	entry := models.AuditEntry{
+		Actor:   actor,
+		Action:  audit.ActionCoinsExpire,
+		Target:  lot.Username,
+		Details: fmt.Sprintf("coin lot %d", lot.ID),
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		employees, err := lockEmployees(ctx, tx, lot.Username)
+		if err != nil {
+			return fmt.Errorf("could not fetch employee data: %w", err)
+		}
+		employee := employees[lot.Username]
+
+		var amount int
+		err = tx.QueryRowContext(ctx, `
+			SELECT amount
+			FROM coin_lots
+			WHERE id = $1 AND employee_id = $2 AND amount > 0 AND expires_at <= $3
+			FOR UPDATE
+		`, lot.ID, employee.id, now).Scan(&amount)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch coin lot: %w", err)
+		}
+
+		err = debitLots(ctx, tx, employee.id, []lotPart{{lotID: lot.ID, amount: amount}}, ledgerRef{kind: models.LedgerExpire})
+		if err != nil {
+			return err
+		}
+
+		before := employee.balance
+		err = tx.QueryRowContext(ctx, `
+			UPDATE employees
+			SET balance = balance - $1
+			WHERE id = $2
+			RETURNING balance
+		`, amount, employee.id).Scan(&employee.balance)
+		if err != nil {
+			return fmt.Errorf("could not update employee balance: %w", err)
+		}
+
+		err = addEvent(ctx, tx, events.CoinsExpired{
+			Username: lot.Username,
+			Amount:   amount,
+			Balance:  employee.balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		entry.Amount = &amount
+		entry.TargetBalanceBefore = &before
+		entry.TargetBalanceAfter = &employee.balance
+		return writeAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
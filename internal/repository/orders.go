@@ -0,0 +1,803 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+const orderSelect = `
+	SELECT o.id, e.username, COALESCE(g.username, ''), o.gift_message, m.merch_name, v.size, v.color, o.quantity, o.price, o.discount, o.total, o.status, o.policy,
+		o.created_at, o.approve_by, COALESCE(o.resolved_by, ''), o.resolved_at, o.reason,
+		COALESCE((SELECT max(h.changed_at) FROM order_status_history h WHERE h.order_id = o.id), o.created_at),
+		ARRAY(
+			SELECT p.name FROM order_promotions op JOIN promotions p ON p.id = op.promotion_id
+			WHERE op.order_id = o.id ORDER BY p.id
+		)
+	FROM orders o
+	JOIN employees e ON e.id = o.employee_id
+	LEFT JOIN employees g ON g.id = o.recipient_id
+	JOIN merch m ON m.id = o.merch_id
+	JOIN merch_variants v ON v.id = o.variant_id
+`
+
+func scanOrder(row rowScanner) (models.Order, error) {
+	var o models.Order
+	err := row.Scan(&o.ID, &o.Username, &o.Recipient, &o.Message, &o.Item, &o.Size, &o.Color, &o.Quantity, &o.Price, &o.Discount, &o.Total, &o.Status, &o.Policy,
+		&o.CreatedAt, &o.ApproveBy, &o.ResolvedBy, &o.ResolvedAt, &o.Reason, &o.UpdatedAt, pq.Array(&o.Promotions))
+	return o, err
+}
+
+func scanOrders(rows *sql.Rows) ([]models.Order, error) {
+	orders := []models.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, o)
+	}
+
+	return orders, rows.Err()
+}
+
+// orderTransitions - куда персонал склада может перевести заказ из текущего статуса.
+// Согласованием (pending_approval) занимаются руководители, а не склад.
+var orderTransitions = map[string][]string{
+	models.OrderPlaced:         {models.OrderReadyForPickup, models.OrderShipped, models.OrderCancelled},
+	models.OrderReadyForPickup: {models.OrderDelivered, models.OrderCancelled},
+	models.OrderShipped:        {models.OrderDelivered},
+}
+
+// addOrderHistory переводит заказ в status и запоминает, кто и когда это сделал.
+// Сам orders.status обновляет вызывающий.
+func addOrderHistory(ctx context.Context, tx *sql.Tx, o *models.Order, status, actor, note string) error {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO order_status_history (order_id, status, changed_by, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING changed_at
+	`, o.ID, status, actor, note).Scan(&o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("could not save order history: %w", err)
+	}
+
+	o.Status = status
+	return nil
+}
+
+func orderHistory(ctx context.Context, db queryer, id int64) ([]models.OrderTransition, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT status, changed_by, note, changed_at
+		FROM order_status_history
+		WHERE order_id = $1
+		ORDER BY id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.OrderTransition{}
+	for rows.Next() {
+		var t models.OrderTransition
+		if err := rows.Scan(&t.Status, &t.ChangedBy, &t.Note, &t.ChangedAt); err != nil {
+			return nil, fmt.Errorf("could not scan order history: %w", err)
+		}
+		history = append(history, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate order history: %w", err)
+	}
+
+	return history, nil
+}
+
+// approvalPolicy возвращает первое правило согласования, под которое попадает покупка.
+func (r *Repository) approvalPolicy(department, item string, total int) (config.ApprovalPolicy, bool) {
+	for _, p := range r.approvals.Policies {
+		if len(p.Departments) > 0 && !slices.Contains(p.Departments, department) {
+			continue
+		}
+		if len(p.Items) > 0 && !slices.Contains(p.Items, item) {
+			continue
+		}
+		if total < p.MinTotal {
+			continue
+		}
+		return p, true
+	}
+
+	return config.ApprovalPolicy{}, false
+}
+
+// saveOrderLots запоминает, из каких лотов оплачен заказ.
+func saveOrderLots(ctx context.Context, tx *sql.Tx, orderID int64, parts []lotPart) error {
+	for _, p := range parts {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO order_lots (order_id, lot_id, amount) VALUES ($1, $2, $3)
+		`, orderID, p.lotID, p.amount)
+		if err != nil {
+			return fmt.Errorf("could not save order lots: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// orderApprovers - руководители отдела покупателя, кроме него самого.
+// У сотрудников без отдела руководителя нет, их заказы согласуют администраторы.
+func orderApprovers(ctx context.Context, db queryer, department, buyer string) ([]string, error) {
+	if department == "" {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT username
+		FROM employees
+		WHERE is_manager AND active AND department = $1 AND username <> $2
+		ORDER BY id
+	`, department, buyer)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch order approvers: %w", err)
+	}
+	defer rows.Close()
+
+	var approvers []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("could not scan order approver: %w", err)
+		}
+		approvers = append(approvers, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate order approvers: %w", err)
+	}
+
+	return approvers, nil
+}
+
+func orderEvent(o models.Order, balance int, approvers []string) events.OrderStatusChanged {
+	return events.OrderStatusChanged{
+		OrderID:   o.ID,
+		Username:  o.Username,
+		Item:      o.Item,
+		Quantity:  o.Quantity,
+		Total:     o.Total,
+		Status:    o.Status,
+		Reason:    o.Reason,
+		Balance:   balance,
+		Approvers: approvers,
+	}
+}
+
+// completeOrder выдает оплаченный заказ: товар попадает в инвентарь ownerID -
+// покупателя или получателя подарка.
+func completeOrder(ctx context.Context, tx *sql.Tx, ownerID, merchID, variantID int, o models.Order, balance int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO purchases (employee_id, merch_id, variant_id, count)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (employee_id, variant_id)
+		DO UPDATE SET count = purchases.count + EXCLUDED.count
+	`, ownerID, merchID, variantID, o.Quantity)
+	if err != nil {
+		return fmt.Errorf("could not update purchases: %w", err)
+	}
+
+	return addEvent(ctx, tx, events.MerchPurchased{
+		Username:  o.Username,
+		Recipient: o.Recipient,
+		Message:   o.Message,
+		Item:      o.Item,
+		Size:      o.Size,
+		Color:     o.Color,
+		Quantity:  o.Quantity,
+		Total:     o.Total,
+		Balance:   balance,
+	})
+}
+
+// orderRow - заказ, заблокированный до конца транзакции. ownerID - в чей инвентарь
+// попадает товар: получатель подарка или сам покупатель.
+type orderRow struct {
+	models.Order
+	employeeID int
+	ownerID    int
+	merchID    int
+	variantID  int
+	department string
+}
+
+func lockOrder(ctx context.Context, tx *sql.Tx, id int64) (orderRow, error) {
+	var row orderRow
+	o, err := scanOrder(tx.QueryRowContext(ctx, orderSelect+" WHERE o.id = $1 FOR UPDATE OF o", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return row, storage.ErrOrderNotFound
+	}
+	if err != nil {
+		return row, fmt.Errorf("could not fetch order: %w", err)
+	}
+	row.Order = o
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT o.employee_id, COALESCE(o.recipient_id, o.employee_id), o.merch_id, o.variant_id, e.department
+		FROM orders o
+		JOIN employees e ON e.id = o.employee_id
+		WHERE o.id = $1
+	`, id).Scan(&row.employeeID, &row.ownerID, &row.merchID, &row.variantID, &row.department)
+	if err != nil {
+		return row, fmt.Errorf("could not fetch order: %w", err)
+	}
+
+	return row, nil
+}
+
+// releaseOrder возвращает монеты заказа в те лоты, из которых они были списаны,
+// и возвращает баланс покупателя до и после.
+func releaseOrder(ctx context.Context, tx *sql.Tx, o orderRow) (before, after int, err error) {
+	employees, err := lockEmployees(ctx, tx, o.Username)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not fetch employee data: %w", err)
+	}
+	before = employees[o.Username].balance
+
+	rows, err := tx.QueryContext(ctx, "SELECT lot_id, amount FROM order_lots WHERE order_id = $1 ORDER BY lot_id", o.ID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not fetch order lots: %w", err)
+	}
+	var parts []lotPart
+	for rows.Next() {
+		var p lotPart
+		if err := rows.Scan(&p.lotID, &p.amount); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("could not scan order lot: %w", err)
+		}
+		parts = append(parts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("could not iterate order lots: %w", err)
+	}
+
+	// монеты, срок которых уже вышел, вернутся и будут списаны при следующем сгорании
+	ref := ledgerRef{kind: models.LedgerRefund, details: fmt.Sprintf("order %d", o.ID)}
+	for _, p := range parts {
+		_, err := tx.ExecContext(ctx, "UPDATE coin_lots SET amount = amount + $2 WHERE id = $1", p.lotID, p.amount)
+		if err != nil {
+			return 0, 0, fmt.Errorf("could not update coin lot: %w", err)
+		}
+		if err := addLedgerEntry(ctx, tx, o.employeeID, p.lotID, p.amount, ref); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE employees SET balance = balance + $1 WHERE id = $2 RETURNING balance
+	`, o.Total, o.employeeID).Scan(&after)
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not update employee balance: %w", err)
+	}
+
+	return before, after, nil
+}
+
+// refundOrder возвращает покупателю монеты заказа, а товар - на склад.
+// Выданный заказ к тому же убирается из инвентаря.
+func refundOrder(ctx context.Context, tx *sql.Tx, o orderRow) (before, after int, err error) {
+	before, after, err = releaseOrder(ctx, tx, o)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := restock(ctx, tx, o.variantID, o.Quantity); err != nil {
+		return 0, 0, err
+	}
+	if o.Status != models.OrderPendingApproval {
+		if err := revokePurchase(ctx, tx, o); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return before, after, nil
+}
+
+// takeStock списывает вариант товара со склада. Вариант без учета остатка
+// (stock IS NULL) не ограничен.
+func takeStock(ctx context.Context, tx *sql.Tx, variantID, quantity int) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE merch_variants SET stock = stock - $2 WHERE id = $1 AND stock >= $2
+	`, variantID, quantity)
+	if err != nil {
+		return fmt.Errorf("could not update merch stock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("could not get affected rows: %w", err)
+	}
+	if n == 1 {
+		return nil
+	}
+
+	var stock sql.NullInt64
+	if err := tx.QueryRowContext(ctx, "SELECT stock FROM merch_variants WHERE id = $1", variantID).Scan(&stock); err != nil {
+		return fmt.Errorf("could not fetch merch stock: %w", err)
+	}
+	if stock.Valid {
+		return storage.ErrOutOfStock
+	}
+
+	return nil
+}
+
+func restock(ctx context.Context, tx *sql.Tx, variantID, quantity int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE merch_variants SET stock = stock + $2 WHERE id = $1 AND stock IS NOT NULL
+	`, variantID, quantity)
+	if err != nil {
+		return fmt.Errorf("could not update merch stock: %w", err)
+	}
+
+	return nil
+}
+
+// setOrderStatus переводит заказ в status и запоминает переход в истории.
+func setOrderStatus(ctx context.Context, tx *sql.Tx, o *orderRow, status, actor, note string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE orders SET status = $2 WHERE id = $1", o.ID, status)
+	if err != nil {
+		return fmt.Errorf("could not update order: %w", err)
+	}
+
+	return addOrderHistory(ctx, tx, &o.Order, status, actor, note)
+}
+
+func resolveOrder(ctx context.Context, tx *sql.Tx, o *orderRow, status, actor, reason string) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE orders SET status = $2, resolved_by = $3, resolved_at = now(), reason = $4
+		WHERE id = $1
+		RETURNING resolved_at
+	`, o.ID, status, actor, reason).Scan(&o.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("could not update order: %w", err)
+	}
+
+	o.ResolvedBy, o.Reason = actor, reason
+	return addOrderHistory(ctx, tx, &o.Order, status, actor, reason)
+}
+
+// checkApprover проверяет, что approver может решать по заказу: это администратор
+// или руководитель отдела покупателя. Свой заказ согласовать нельзя.
+func checkApprover(ctx context.Context, tx *sql.Tx, o orderRow, approver string, admin bool) error {
+	if approver == o.Username {
+		return storage.ErrNotApprover
+	}
+	if admin {
+		return nil
+	}
+
+	var manager bool
+	var department string
+	err := tx.QueryRowContext(ctx, `
+		SELECT is_manager, department FROM employees WHERE username = $1
+	`, approver).Scan(&manager, &department)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrNotApprover
+	}
+	if err != nil {
+		return fmt.Errorf("could not fetch approver: %w", err)
+	}
+	if !manager || department == "" || department != o.department {
+		return storage.ErrNotApprover
+	}
+
+	return nil
+}
+
+// ListPendingApprovals возвращает заказы, которые ждут решения approver:
+// администратор видит все, руководитель - заказы своего отдела.
+func (r *Repository) ListPendingApprovals(ctx context.Context, approver string, admin bool) ([]models.Order, error) {
+	const op = "repository.ListPendingApprovals"
+
+	rows, err := r.db.QueryContext(ctx, orderSelect+`
+		WHERE o.status = $3 AND o.approve_by > now() AND e.username <> $1
+			AND ($2 OR EXISTS (
+				SELECT 1 FROM employees mgr
+				WHERE mgr.username = $1 AND mgr.is_manager
+					AND mgr.department <> '' AND mgr.department = e.department
+			))
+		ORDER BY o.id
+	`, approver, admin, models.OrderPendingApproval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return orders, nil
+}
+
+// ApproveOrder согласует заказ: монеты уже списаны, товар попадает в инвентарь,
+// а заказ - на склад.
+func (r *Repository) ApproveOrder(ctx context.Context, approver string, admin bool, id int64) (models.Order, error) {
+	const op = "repository.ApproveOrder"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockPendingOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := checkApprover(ctx, tx, o, approver, admin); err != nil {
+			return err
+		}
+
+		if err := resolveOrder(ctx, tx, &o, models.OrderPlaced, approver, ""); err != nil {
+			return err
+		}
+
+		var balance int
+		if err := tx.QueryRowContext(ctx, "SELECT balance FROM employees WHERE id = $1", o.employeeID).Scan(&balance); err != nil {
+			return fmt.Errorf("could not fetch employee balance: %w", err)
+		}
+
+		if err := completeOrder(ctx, tx, o.ownerID, o.merchID, o.variantID, o.Order, balance); err != nil {
+			return err
+		}
+		if err := addEvent(ctx, tx, orderEvent(o.Order, balance, nil)); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   approver,
+			Action:  audit.ActionOrderApprove,
+			Target:  o.Username,
+			Amount:  &o.Total,
+			Details: fmt.Sprintf("order %d: %s x%d", o.ID, o.Item, o.Quantity),
+		})
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+// RejectOrder отклоняет заказ и возвращает покупателю зарезервированные монеты.
+func (r *Repository) RejectOrder(ctx context.Context, approver string, admin bool, id int64, reason string) (models.Order, error) {
+	const op = "repository.RejectOrder"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockPendingOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := checkApprover(ctx, tx, o, approver, admin); err != nil {
+			return err
+		}
+
+		return cancelPendingOrder(ctx, tx, &o, models.OrderRejected, approver, audit.ActionOrderReject, reason)
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+// OverdueOrders возвращает заказы, которые не согласовали вовремя.
+func (r *Repository) OverdueOrders(ctx context.Context, now time.Time, limit int) ([]models.Order, error) {
+	const op = "repository.OverdueOrders"
+
+	rows, err := r.db.QueryContext(ctx, orderSelect+`
+		WHERE o.status = $1 AND o.approve_by <= $2
+		ORDER BY o.approve_by
+		LIMIT $3
+	`, models.OrderPendingApproval, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var orders []models.Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return orders, nil
+}
+
+// ExpireOrder отменяет несогласованный вовремя заказ и возвращает монеты.
+// Если заказ уже решен (например, другой репликой), ничего не делает.
+func (r *Repository) ExpireOrder(ctx context.Context, actor string, id int64, now time.Time) error {
+	const op = "repository.ExpireOrder"
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		o, err := lockOrder(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if o.Status != models.OrderPendingApproval || o.ApproveBy.After(now) {
+			return nil
+		}
+
+		return cancelPendingOrder(ctx, tx, &o, models.OrderExpired, actor, audit.ActionOrderExpire, "approval timed out")
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// lockPendingOrder блокирует заказ, который еще можно согласовать или отклонить.
+func lockPendingOrder(ctx context.Context, tx *sql.Tx, id int64) (orderRow, error) {
+	o, err := lockOrder(ctx, tx, id)
+	if err != nil {
+		return o, err
+	}
+	if o.Status != models.OrderPendingApproval {
+		return o, storage.ErrOrderResolved
+	}
+	// монеты вернет планировщик
+	if !o.ApproveBy.After(time.Now()) {
+		return o, storage.ErrOrderExpired
+	}
+
+	return o, nil
+}
+
+func cancelPendingOrder(ctx context.Context, tx *sql.Tx, o *orderRow, status, actor, action, reason string) error {
+	before, after, err := refundOrder(ctx, tx, *o)
+	if err != nil {
+		return err
+	}
+
+	if err := resolveOrder(ctx, tx, o, status, actor, reason); err != nil {
+		return err
+	}
+	if err := addEvent(ctx, tx, orderEvent(o.Order, after, nil)); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("order %d: %s x%d", o.ID, o.Item, o.Quantity)
+	if reason != "" {
+		details += ": " + reason
+	}
+
+	return writeAudit(ctx, tx, models.AuditEntry{
+		Actor:               actor,
+		Action:              action,
+		Target:              o.Username,
+		Amount:              &o.Total,
+		TargetBalanceBefore: &before,
+		TargetBalanceAfter:  &after,
+		Details:             details,
+	})
+}
+
+// ListOrders возвращает заказы от новых к старым.
+func (r *Repository) ListOrders(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	const op = "repository.ListOrders"
+
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Username != "" {
+		where("(e.username = $%[1]d OR g.username = $%[1]d)", filter.Username)
+	}
+	if filter.Status != "" {
+		where("o.status = $%d", filter.Status)
+	}
+
+	query := orderSelect
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY o.id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return orders, nil
+}
+
+// GetOpenOrders возвращает невыданные заказы сотрудника, включая подарки ему, от старых к новым.
+func (r *Repository) GetOpenOrders(userID int) ([]models.Order, error) {
+	const op = "repository.GetOpenOrders"
+
+	rows, err := r.db.Query(orderSelect+`
+		WHERE (o.employee_id = $1 OR o.recipient_id = $1) AND o.status = ANY($2)
+		ORDER BY o.id
+	`, userID, pq.Array(models.OrderOpenStatuses))
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching open orders: %w", op, err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error scanning open orders: %w", op, err)
+	}
+
+	return orders, nil
+}
+
+// GetOrder возвращает заказ вместе с историей статусов.
+func (r *Repository) GetOrder(ctx context.Context, id int64) (models.Order, error) {
+	const op = "repository.GetOrder"
+
+	o, err := scanOrder(r.db.QueryRowContext(ctx, orderSelect+" WHERE o.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return o, fmt.Errorf("%s: %w", op, storage.ErrOrderNotFound)
+	}
+	if err != nil {
+		return o, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if o.History, err = orderHistory(ctx, r.db, id); err != nil {
+		return o, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o, nil
+}
+
+// AdvanceOrder переводит оплаченный заказ в следующий статус по orderTransitions.
+// При отмене монеты возвращаются покупателю, а товар убирается из инвентаря
+// и возвращается на склад.
+func (r *Repository) AdvanceOrder(ctx context.Context, actor string, id int64, status, note string) (models.Order, error) {
+	const op = "repository.AdvanceOrder"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		if !slices.Contains(orderTransitions[o.Status], status) {
+			return storage.ErrOrderTransition
+		}
+
+		if status == models.OrderCancelled {
+			err = closeOrder(ctx, tx, &o, status, actor, audit.ActionOrderCancel, note)
+		} else {
+			err = moveOrder(ctx, tx, &o, status, actor, audit.ActionOrderStatus, note)
+		}
+		if err != nil {
+			return err
+		}
+
+		o.History, err = orderHistory(ctx, tx, o.ID)
+		return err
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+// moveOrder переводит заказ в status без движения монет и товара.
+func moveOrder(ctx context.Context, tx *sql.Tx, o *orderRow, status, actor, action, note string) error {
+	from := o.Status
+
+	var balance int
+	if err := tx.QueryRowContext(ctx, "SELECT balance FROM employees WHERE id = $1", o.employeeID).Scan(&balance); err != nil {
+		return fmt.Errorf("could not fetch employee balance: %w", err)
+	}
+
+	if err := setOrderStatus(ctx, tx, o, status, actor, note); err != nil {
+		return err
+	}
+
+	event := orderEvent(o.Order, balance, nil)
+	event.Reason = note
+	if err := addEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("order %d: %s -> %s", o.ID, from, status)
+	if note != "" {
+		details += ": " + note
+	}
+
+	return writeAudit(ctx, tx, models.AuditEntry{
+		Actor:   actor,
+		Action:  action,
+		Target:  o.Username,
+		Details: details,
+	})
+}
+
+// closeOrder отменяет оплаченный заказ или принимает возврат: монеты возвращаются
+// покупателю по цене покупки, товар - на склад.
+func closeOrder(ctx context.Context, tx *sql.Tx, o *orderRow, status, actor, action, note string) error {
+	from := o.Status
+
+	before, after, err := refundOrder(ctx, tx, *o)
+	if err != nil {
+		return err
+	}
+
+	if err := setOrderStatus(ctx, tx, o, status, actor, note); err != nil {
+		return err
+	}
+
+	event := orderEvent(o.Order, after, nil)
+	event.Reason = note
+	if err := addEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("order %d: %s -> %s: %s x%d", o.ID, from, status, o.Item, o.Quantity)
+	if note != "" {
+		details += ": " + note
+	}
+
+	return writeAudit(ctx, tx, models.AuditEntry{
+		Actor:               actor,
+		Action:              action,
+		Target:              o.Username,
+		Amount:              &o.Total,
+		TargetBalanceBefore: &before,
+		TargetBalanceAfter:  &after,
+		Details:             details,
+	})
+}
+
+// revokePurchase убирает товар отмененного заказа из инвентаря, куда он был выдан.
+func revokePurchase(ctx context.Context, tx *sql.Tx, o orderRow) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE purchases SET count = count - $3 WHERE employee_id = $1 AND variant_id = $2
+	`, o.ownerID, o.variantID, o.Quantity)
+	if err != nil {
+		return fmt.Errorf("could not update purchases: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM purchases WHERE employee_id = $1 AND variant_id = $2 AND count <= 0
+	`, o.ownerID, o.variantID)
+	if err != nil {
+		return fmt.Errorf("could not update purchases: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/events"
+)
+
+// addEvent кладет событие в outbox в рамках той же транзакции,
+// что и изменение, которое оно описывает.
+func addEvent(ctx context.Context, db execer, payload events.Payload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode %s event: %w", payload.EventType(), err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO outbox (event_type, usernames, payload)
+		VALUES ($1, $2, $3)
+	`, payload.EventType(), pq.Array(payload.Participants()), data)
+	if err != nil {
+		return fmt.Errorf("could not insert %s event: %w", payload.EventType(), err)
+	}
+
+	return nil
+}
+
+// ClaimEvents забирает до limit неопубликованных событий и откладывает их
+// следующую попытку на lease: пока диспетчер раздает события получателям,
+// другие реплики их не возьмут, а транзакция и блокировки строк уже отпущены.
+func (r *Repository) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]events.Pending, error) {
+	const op = "repository.ClaimEvents"
+
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE outbox
+		SET next_attempt_at = $2, dispatched_at = clock_timestamp()
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, usernames, payload, created_at, attempts, delivered_sinks
+	`, limit, time.Now().Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("%s: error claiming events: %w", op, err)
+	}
+	defer rows.Close()
+
+	var batch []events.Pending
+	for rows.Next() {
+		var p events.Pending
+		var payload []byte
+		err := rows.Scan(
+			&p.ID, &p.Type, pq.Array(&p.Usernames), &payload,
+			&p.CreatedAt, &p.Attempts, pq.Array(&p.DeliveredSinks),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning event: %w", op, err)
+		}
+		p.Payload = payload
+		batch = append(batch, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating events: %w", op, err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(batch, func(a, b events.Pending) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return batch, nil
+}
+
+// CompleteEvent фиксирует результат попытки: delivered - все получатели,
+// принявшие событие на этой и прошлых попытках.
+// nextAttempt = nil при ошибке переводит событие в dead-letter.
+func (r *Repository) CompleteEvent(ctx context.Context, id int64, delivered []string, dispatchErr error, nextAttempt *time.Time) error {
+	const op = "repository.CompleteEvent"
+
+	var err error
+	switch {
+	case dispatchErr == nil:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE outbox
+			SET published_at = now(), attempts = attempts + 1,
+				delivered_sinks = $2, last_error = ''
+			WHERE id = $1
+		`, id, pq.Array(delivered))
+	case nextAttempt == nil:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE outbox
+			SET dead_at = now(), attempts = attempts + 1,
+				delivered_sinks = $2, last_error = $3
+			WHERE id = $1
+		`, id, pq.Array(delivered), dispatchErr.Error())
+	default:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE outbox
+			SET attempts = attempts + 1, delivered_sinks = $2,
+				last_error = $3, next_attempt_at = $4
+			WHERE id = $1
+		`, id, pq.Array(delivered), dispatchErr.Error(), *nextAttempt)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: could not update event: %w", op, err)
+	}
+
+	return nil
+}
+
+// EventsSince возвращает события сотрудника с ID больше afterID.
+// Нужен для продолжения потока уведомлений после переподключения.
+func (r *Repository) EventsSince(ctx context.Context, username string, afterID int64, limit int) ([]events.Event, error) {
+	const op = "repository.EventsSince"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_type, usernames, payload, created_at
+		FROM outbox
+		WHERE id > $2 AND $1::text = ANY(usernames)
+		ORDER BY id
+		LIMIT $3
+	`, username, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching events: %w", op, err)
+	}
+	defer rows.Close()
+
+	return scanEvents(op, rows)
+}
+
+// LateEvents возвращает события сотрудника с ID меньше lastID, переданные в sinks
+// не раньше чем за window до события lastID. ID выдается до коммита, поэтому
+// такие события клиент мог не получить, хотя уже видел lastID.
+func (r *Repository) LateEvents(ctx context.Context, username string, lastID int64, window time.Duration, limit int) ([]events.Event, error) {
+	const op = "repository.LateEvents"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, event_type, usernames, payload, created_at
+		FROM outbox
+		WHERE id < $2 AND $1::text = ANY(usernames)
+			AND dispatched_at >= COALESCE(
+				(SELECT dispatched_at FROM outbox WHERE id = $2), now()
+			) - make_interval(secs => $3)
+		ORDER BY id
+		LIMIT $4
+	`, username, lastID, window.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching events: %w", op, err)
+	}
+	defer rows.Close()
+
+	return scanEvents(op, rows)
+}
+
+func scanEvents(op string, rows *sql.Rows) ([]events.Event, error) {
+	var result []events.Event
+	for rows.Next() {
+		var e events.Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, pq.Array(&e.Usernames), &payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: error scanning event: %w", op, err)
+		}
+		e.Payload = payload
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating events: %w", op, err)
+	}
+
+	return result, nil
+}
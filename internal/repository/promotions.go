@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/pricing"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// promotionUses - применения промоакций в заказах, которые считаются (см. orderCounts).
+const promotionUses = `
+	SELECT op.promotion_id, o.employee_id
+	FROM order_promotions op
+	JOIN orders o ON o.id = op.order_id
+	WHERE ` + orderCounts
+
+const promotionSelect = `
+	SELECT p.id, p.name, COALESCE(p.code, ''), p.kind, p.value, p.items, p.categories, p.usernames,
+		p.starts_at, p.ends_at, p.max_uses, p.max_uses_per_user, p.stackable, p.active,
+		p.created_by, p.created_at,
+		(SELECT COUNT(*) FROM (` + promotionUses + `) u WHERE u.promotion_id = p.id)
+	FROM promotions p
+`
+
+func scanPromotion(row rowScanner) (models.Promotion, error) {
+	var p models.Promotion
+	err := row.Scan(&p.ID, &p.Name, &p.Code, &p.Kind, &p.Value,
+		pq.Array(&p.Items), pq.Array(&p.Categories), pq.Array(&p.Usernames),
+		&p.StartsAt, &p.EndsAt, &p.MaxUses, &p.MaxUsesPerUser, &p.Stackable, &p.Active,
+		&p.CreatedBy, &p.CreatedAt, &p.Uses)
+	return p, err
+}
+
+func queryPromotions(ctx context.Context, db queryer, tail string, args ...any) ([]models.Promotion, error) {
+	rows, err := db.QueryContext(ctx, promotionSelect+tail, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promos := []models.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan promotion: %w", err)
+		}
+		promos = append(promos, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not iterate promotions: %w", err)
+	}
+
+	return promos, nil
+}
+
+// CreatePromotion заводит промоакцию. Код промоакции уникален.
+func (r *Repository) CreatePromotion(ctx context.Context, p models.Promotion) (models.Promotion, error) {
+	const op = "repository.CreatePromotion"
+
+	var code *string
+	if p.Code != "" {
+		code = &p.Code
+	}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO promotions (name, code, kind, value, items, categories, usernames,
+				starts_at, ends_at, max_uses, max_uses_per_user, stackable, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			ON CONFLICT (code) DO NOTHING
+			RETURNING id, active, created_at
+		`, p.Name, code, p.Kind, p.Value, pq.Array(p.Items), pq.Array(p.Categories), pq.Array(p.Usernames),
+			p.StartsAt, p.EndsAt, p.MaxUses, p.MaxUsesPerUser, p.Stackable, p.CreatedBy).
+			Scan(&p.ID, &p.Active, &p.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrPromoExists
+		}
+		if err != nil {
+			return fmt.Errorf("could not insert promotion: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   p.CreatedBy,
+			Action:  audit.ActionPromoCreate,
+			Target:  p.Name,
+			Details: promotionDetails(p),
+		})
+	})
+	if err != nil {
+		return p, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return p, nil
+}
+
+func (r *Repository) ListPromotions(ctx context.Context) ([]models.Promotion, error) {
+	const op = "repository.ListPromotions"
+
+	promos, err := queryPromotions(ctx, r.db, " ORDER BY p.id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return promos, nil
+}
+
+func (r *Repository) GetPromotion(ctx context.Context, id int64) (models.Promotion, error) {
+	const op = "repository.GetPromotion"
+
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, promotionSelect+" WHERE p.id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, fmt.Errorf("%s: %w", op, storage.ErrPromoNotFound)
+	}
+	if err != nil {
+		return p, fmt.Errorf("%s: error fetching promotion: %w", op, err)
+	}
+
+	return p, nil
+}
+
+// DisablePromotion прекращает промоакцию. Уже сделанные со скидкой заказы не меняются.
+func (r *Repository) DisablePromotion(ctx context.Context, actor string, id int64) (models.Promotion, error) {
+	const op = "repository.DisablePromotion"
+
+	var p models.Promotion
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "UPDATE promotions SET active = false WHERE id = $1", id)
+		if err != nil {
+			return fmt.Errorf("could not update promotion: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("could not get affected rows: %w", err)
+		}
+		if n == 0 {
+			return storage.ErrPromoNotFound
+		}
+
+		p, err = scanPromotion(tx.QueryRowContext(ctx, promotionSelect+" WHERE p.id = $1", id))
+		if err != nil {
+			return fmt.Errorf("could not fetch promotion: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionPromoDisable,
+			Target:  p.Name,
+			Details: fmt.Sprintf("promotion %d", p.ID),
+		})
+	})
+	if err != nil {
+		return p, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return p, nil
+}
+
+// priceOrder считает цену покупки со скидками: действующие промоакции без кода
+// и промоакция с кодом code, если он введен. Неподходящий или исчерпанный код - ошибка,
+// промоакции без кода в таком случае просто не применяются.
+func priceOrder(ctx context.Context, tx *sql.Tx, employeeID int, line pricing.Line, code string, now time.Time) (pricing.Quote, error) {
+	promos, err := queryPromotions(ctx, tx, `
+		WHERE (p.active AND p.code IS NULL) OR p.code = $1
+		ORDER BY p.id
+	`, code)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+
+	codeFound := false
+	var usable []models.Promotion
+	for _, p := range promos {
+		isCode := p.Code != ""
+		codeFound = codeFound || isCode
+
+		if !pricing.Applies(p, line, now) {
+			if isCode {
+				return pricing.Quote{}, storage.ErrPromoNotApplicable
+			}
+			continue
+		}
+
+		ok, err := promotionAvailable(ctx, tx, p, employeeID)
+		if err != nil {
+			return pricing.Quote{}, err
+		}
+		if !ok {
+			if isCode {
+				return pricing.Quote{}, storage.ErrPromoExhausted
+			}
+			continue
+		}
+
+		usable = append(usable, p)
+	}
+	if code != "" && !codeFound {
+		return pricing.Quote{}, storage.ErrPromoNotFound
+	}
+
+	return pricing.Price(line, usable), nil
+}
+
+// promotionAvailable проверяет лимиты использования промоакции. Общий лимит
+// защищен блокировкой промоакции до конца транзакции, лимит сотрудника - блокировкой
+// самого покупателя.
+func promotionAvailable(ctx context.Context, tx *sql.Tx, p models.Promotion, employeeID int) (bool, error) {
+	if p.MaxUses == nil && p.MaxUsesPerUser == nil {
+		return true, nil
+	}
+
+	if p.MaxUses != nil {
+		if _, err := tx.ExecContext(ctx, "SELECT 1 FROM promotions WHERE id = $1 FOR UPDATE", p.ID); err != nil {
+			return false, fmt.Errorf("could not lock promotion: %w", err)
+		}
+	}
+
+	var total, own int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE u.employee_id = $2)
+		FROM (`+promotionUses+`) u
+		WHERE u.promotion_id = $1
+	`, p.ID, employeeID).Scan(&total, &own)
+	if err != nil {
+		return false, fmt.Errorf("could not count promotion uses: %w", err)
+	}
+
+	if p.MaxUses != nil && total >= *p.MaxUses {
+		return false, nil
+	}
+	if p.MaxUsesPerUser != nil && own >= *p.MaxUsesPerUser {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// saveOrderPromotions запоминает, какие промоакции дали заказу скидку.
+func saveOrderPromotions(ctx context.Context, tx *sql.Tx, orderID int64, applied []pricing.Applied) error {
+	for _, a := range applied {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO order_promotions (order_id, promotion_id, amount) VALUES ($1, $2, $3)
+		`, orderID, a.Promotion.ID, a.Amount)
+		if err != nil {
+			return fmt.Errorf("could not save order promotions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func promotionDetails(p models.Promotion) string {
+	details := fmt.Sprintf("promotion %d: %s %d", p.ID, p.Kind, p.Value)
+	if p.Code != "" {
+		details += fmt.Sprintf(" code=%q", p.Code)
+	}
+	if p.Stackable {
+		details += " stackable"
+	}
+
+	return details
+}
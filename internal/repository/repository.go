@@ -3,45 +3,100 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/audit"
 	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/pricing"
+	"github.com/magneless/merch-shop/internal/storage"
 )
 
 type Repository struct {
-	db *sql.DB
+	db        *sql.DB
+	coins     config.Coins
+	limits    config.TransferLimits
+	approvals config.Approvals
+	orders    config.Orders
 }
 
-func New(db *sql.DB) *Repository {
-	return &Repository{db: db}
+func New(db *sql.DB, coins config.Coins, limits config.TransferLimits, approvals config.Approvals, orders config.Orders) *Repository {
+	return &Repository{db: db, coins: coins, limits: limits, approvals: approvals, orders: orders}
 }
 
-func (r *Repository) GetUser(username, passwordHash string) error {
+// GetUser входит в аккаунт или создает его, если сотрудника еще нет.
+// canRegister = false запрещает создание: логин или пароль не проходят
+// правила для новых аккаунтов, а существующие сотрудники входят как раньше.
+func (r *Repository) GetUser(ctx context.Context, username, passwordHash string, canRegister bool) error {
 	const op = "repository.GetUser"
 
+	entry := models.AuditEntry{Actor: username, Action: audit.ActionLogin}
+
 	var realPasswordHash string
-	err := r.db.QueryRow(
-		"SELECT password_hash FROM employees WHERE username = $1",
+	var system bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT password_hash, is_system FROM employees WHERE username = $1",
 		username,
-	).Scan(&realPasswordHash)
+	).Scan(&realPasswordHash, &system)
 
 	switch {
-	case err == sql.ErrNoRows:
-		_, err = r.db.Exec(
-			"INSERT INTO employees (username, password_hash, balance) VALUES ($1, $2, 1000)",
-			username, passwordHash,
-		)
+	case errors.Is(err, sql.ErrNoRows):
+		if !canRegister {
+			return fmt.Errorf("%s: %w", op, storage.ErrRegistrationRejected)
+		}
+
+		balance := r.coins.InitialBalance
+		entry.Action = audit.ActionRegister
+		entry.ActorBalanceAfter = &balance
+
+		err = r.withTx(ctx, func(tx *sql.Tx) error {
+			var id int
+			err := tx.QueryRowContext(ctx,
+				"INSERT INTO employees (username, password_hash, balance) VALUES ($1, $2, $3) RETURNING id",
+				username, passwordHash, balance,
+			).Scan(&id)
+			if err != nil {
+				return err
+			}
+
+			if balance > 0 {
+				ref := ledgerRef{kind: models.LedgerIssue, details: "initial balance"}
+				if err := creditLot(ctx, tx, id, balance, models.LotInitial, r.lotExpiry(time.Now(), nil), ref); err != nil {
+					return err
+				}
+			}
+
+			if err := addEvent(ctx, tx, events.UserRegistered{
+				Username: username,
+				Balance:  balance,
+			}); err != nil {
+				return err
+			}
+
+			return writeAudit(ctx, tx, entry)
+		})
 		if err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
+		return nil
 	case err != nil:
 		return fmt.Errorf("%s: %w", op, err)
 	default:
-		if passwordHash != realPasswordHash {
-			return fmt.Errorf("%s: wrong password", op)
+		// под системными сотрудниками (казна) войти нельзя
+		if system || passwordHash != realPasswordHash {
+			return fmt.Errorf("%s: %w", op, r.auditFailure(ctx, entry, storage.ErrWrongPassword))
 		}
 	}
 
+	if err := r.WriteAudit(ctx, entry); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	return nil
 }
 
@@ -61,7 +116,7 @@ func (r *Repository) GetBalanceAndId(username string) (int, int, error) {
 func (r *Repository) GetSentTransactions(userID int, fromUsername string) ([]models.CoinTransaction, error) {
 	const op = "repository.GetSentTransactions"
 	rows, err := r.db.Query(`
-		SELECT t.amount, e.username
+		SELECT t.amount, e.username, t.batch_id
 		FROM transactions t
 		JOIN employees e ON t.receiver_id = e.id
 		WHERE t.sender_id = $1
@@ -71,17 +126,19 @@ func (r *Repository) GetSentTransactions(userID int, fromUsername string) ([]mod
 	}
 	defer rows.Close()
 
-	var transactions []models.CoinTransaction
+	transactions := []models.CoinTransaction{}
 	for rows.Next() {
 		var amount int
 		var toUsername string
-		if err := rows.Scan(&amount, &toUsername); err != nil {
+		var batchID *int64
+		if err := rows.Scan(&amount, &toUsername, &batchID); err != nil {
 			return nil, fmt.Errorf("%s: error scanning sent transaction: %w", op, err)
 		}
 		transactions = append(transactions, models.CoinTransaction{
 			FromUser: fromUsername,
 			ToUser:   toUsername,
 			Amount:   amount,
+			BatchID:  batchID,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -94,7 +151,7 @@ func (r *Repository) GetSentTransactions(userID int, fromUsername string) ([]mod
 func (r *Repository) GetReceivedTransactions(userID int, toUsername string) ([]models.CoinTransaction, error) {
 	const op = "repository.GetReceivedTransactions"
 	rows, err := r.db.Query(`
-		SELECT t.amount, e.username
+		SELECT t.amount, e.username, t.batch_id
 		FROM transactions t
 		JOIN employees e ON t.sender_id = e.id
 		WHERE t.receiver_id = $1
@@ -104,17 +161,19 @@ func (r *Repository) GetReceivedTransactions(userID int, toUsername string) ([]m
 	}
 	defer rows.Close()
 
-	var transactions []models.CoinTransaction
+	transactions := []models.CoinTransaction{}
 	for rows.Next() {
 		var amount int
 		var fromUsername string
-		if err := rows.Scan(&amount, &fromUsername); err != nil {
+		var batchID *int64
+		if err := rows.Scan(&amount, &fromUsername, &batchID); err != nil {
 			return nil, fmt.Errorf("%s: error scanning received transaction: %w", op, err)
 		}
 		transactions = append(transactions, models.CoinTransaction{
 			FromUser: fromUsername,
 			ToUser:   toUsername,
 			Amount:   amount,
+			BatchID:  batchID,
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -127,27 +186,25 @@ func (r *Repository) GetReceivedTransactions(userID int, toUsername string) ([]m
 func (r *Repository) GetInventory(userID int) ([]models.InventoryItem, error) {
 	const op = "repository.GetInventory"
 	rows, err := r.db.Query(`
-		SELECT m.merch_name, p.count
+		SELECT m.merch_name, v.size, v.color, p.count
 		FROM purchases p
 		JOIN merch m ON p.merch_id = m.id
+		JOIN merch_variants v ON p.variant_id = v.id
 		WHERE p.employee_id = $1
+		ORDER BY m.merch_name, v.id
 	`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: error fetching inventory: %w", op, err)
 	}
 	defer rows.Close()
 
-	var inventory []models.InventoryItem
+	inventory := []models.InventoryItem{}
 	for rows.Next() {
-		var merchName string
-		var count int
-		if err := rows.Scan(&merchName, &count); err != nil {
+		var item models.InventoryItem
+		if err := rows.Scan(&item.Type, &item.Size, &item.Color, &item.Quantity); err != nil {
 			return nil, fmt.Errorf("%s: error scanning inventory item: %w", op, err)
 		}
-		inventory = append(inventory, models.InventoryItem{
-			Type:     merchName,
-			Quantity: count,
-		})
+		inventory = append(inventory, item)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: error iterating inventory items: %w", op, err)
@@ -156,144 +213,387 @@ func (r *Repository) GetInventory(userID int) ([]models.InventoryItem, error) {
 	return inventory, nil
 }
 
-func (r *Repository) PurchaseMerch(username, merchName string, quantity int) error {
+// PurchaseMerch покупает p.Quantity штук выбранного варианта товара по цене со скидками
+// промоакций (см. pricing.Price), если это позволяет правило покупки товара. Если покупка
+// попадает под правило согласования, монеты списываются, а заказ ждет решения руководителя
+// (статус OrderPendingApproval); иначе покупка сразу попадает в инвентарь.
+// Подарок (p.Recipient) оплачивает покупатель, а товар попадает в инвентарь получателя:
+// лимит покупок считается по получателю, промоакции и согласование - по покупателю.
+func (r *Repository) PurchaseMerch(ctx context.Context, username string, p models.Purchase) (models.Order, error) {
 	const op = "repository.PurchaseMerch"
-	ctx := context.Background()
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("%s: could not begin transaction: %w", op, err)
-	}
-	defer func() {
+	merchName, quantity := p.Item, p.Quantity
+	entry := models.AuditEntry{Actor: username, Action: audit.ActionPurchase, Target: merchName}
+	order := models.Order{Username: username, Recipient: p.Recipient, Message: p.Message, Item: merchName, Quantity: quantity}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		usernames := []string{username}
+		if p.Recipient != "" {
+			usernames = append(usernames, p.Recipient)
+		}
+		employees, err := lockEmployees(ctx, tx, usernames...)
 		if err != nil {
-			tx.Rollback()
+			return fmt.Errorf("%s: could not fetch employee data: %w", op, err)
+		}
+		employee, owner := employees[username], employees[username]
+
+		var recipientID *int
+		if p.Recipient != "" {
+			owner = employees[p.Recipient]
+			recipientID = &owner.id
+			if err := checkGiftRecipient(ctx, tx, owner.id, p.Recipient); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
 		}
-	}()
 
-	var employeeID, balance int
-	err = tx.QueryRowContext(ctx, `
-		SELECT id, balance 
-		FROM employees 
-		WHERE username = $1
-	`, username).Scan(&employeeID, &balance)
-	if err != nil {
-		return fmt.Errorf("%s: could not fetch employee data: %w", op, err)
-	}
+		var (
+			merchID     int
+			category    string
+			maxQuantity *int
+			period      string
+			releaseAt   *time.Time
+		)
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, price, category, purchase_limit, limit_period, release_at
+			FROM merch 
+			WHERE merch_name = $1
+		`, merchName).Scan(&merchID, &order.Price, &category, &maxQuantity, &period, &releaseAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrMerchNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: could not fetch merch price and id: %w", op, err)
+		}
 
-	var price, merchID int
-	err = tx.QueryRowContext(ctx, `
-		SELECT id, price
-		FROM merch 
-		WHERE merch_name = $1
-	`, merchName).Scan(&merchID, &price)
-	if err != nil {
-		return fmt.Errorf("%s: could not fetch merch price and id: %w", op, err)
-	}
+		variant, err := pickVariant(ctx, tx, merchID, p.Variant)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		order.Size, order.Color = variant.Size, variant.Color
 
-	totalCost := price * quantity
-	if balance < totalCost {
-		return fmt.Errorf("%s: insufficient balance", op)
-	}
+		now := time.Now()
+		rule := scanPurchaseRule(maxQuantity, period, releaseAt)
+		if err := checkPurchaseRule(ctx, tx, owner.id, merchID, rule, quantity, now); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	res, err := tx.ExecContext(ctx, `
-		UPDATE employees 
-		SET balance = balance - $1 
-		WHERE id = $2
-	`, totalCost, employeeID)
-	if err != nil {
-		return fmt.Errorf("%s: could not update employee balance: %w", op, err)
-	}
-	rowsAffected, err := res.RowsAffected()
+		line := pricing.Line{Username: username, Item: merchName, Category: category, Price: order.Price, Quantity: quantity}
+		quote, err := priceOrder(ctx, tx, employee.id, line, p.PromoCode, now)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		order.Discount, order.Total = quote.Discount, quote.Total
+		for _, a := range quote.Applied {
+			order.Promotions = append(order.Promotions, a.Promotion.Name)
+		}
+
+		balanceBefore, balanceAfter := employee.balance, employee.balance-order.Total
+		entry.Amount = &order.Total
+		entry.ActorBalanceBefore = &balanceBefore
+		entry.ActorBalanceAfter = &balanceAfter
+
+		if employee.balance < order.Total {
+			return fmt.Errorf("%s: %w", op, storage.ErrInsufficientBalance)
+		}
+		if err := takeStock(ctx, tx, variant.ID, quantity); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		lots, err := takeLots(ctx, tx, employee.id, order.Total, now)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE employees 
+			SET balance = balance - $1 
+			WHERE id = $2
+		`, order.Total, employee.id)
+		if err != nil {
+			return fmt.Errorf("%s: could not update employee balance: %w", op, err)
+		}
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: could not get affected rows: %w", op, err)
+		}
+		if rowsAffected != 1 {
+			return fmt.Errorf("%s: no employee row updated", op)
+		}
+
+		order.Status = models.OrderPlaced
+		if policy, ok := r.approvalPolicy(employee.department, merchName, order.Total); ok {
+			approveBy := now.Add(r.approvals.Timeout)
+			order.Status = models.OrderPendingApproval
+			order.Policy = policy.Name
+			order.ApproveBy = &approveBy
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO orders (employee_id, recipient_id, gift_message, merch_id, variant_id, quantity, price, discount, total, status, policy, approve_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id, created_at
+		`, employee.id, recipientID, p.Message, merchID, variant.ID, quantity, order.Price, order.Discount, order.Total, order.Status, order.Policy, order.ApproveBy).
+			Scan(&order.ID, &order.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("%s: could not insert order: %w", op, err)
+		}
+		if err := saveOrderPromotions(ctx, tx, order.ID, quote.Applied); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := addOrderHistory(ctx, tx, &order, order.Status, username, ""); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		ref := ledgerRef{kind: models.LedgerPurchase, details: fmt.Sprintf("order %d: %s x%d", order.ID, merchName, quantity)}
+		if err := debitLots(ctx, tx, employee.id, lots, ref); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := saveOrderLots(ctx, tx, order.ID, lots); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if order.Status == models.OrderPendingApproval {
+			approvers, err := orderApprovers(ctx, tx, employee.department, username)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			err = addEvent(ctx, tx, orderEvent(order, balanceAfter, approvers))
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			entry.Details = fmt.Sprintf("order %d awaits approval (policy %q)", order.ID, order.Policy)
+			if p.Recipient != "" {
+				entry.Details += ", gift to " + p.Recipient
+			}
+			if err := writeAudit(ctx, tx, entry); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			return nil
+		}
+
+		if err := completeOrder(ctx, tx, owner.id, merchID, variant.ID, order, balanceAfter); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		entry.Details = fmt.Sprintf("order %d", order.ID)
+		if p.Recipient != "" {
+			entry.Details += ", gift to " + p.Recipient
+		}
+		if order.Discount > 0 {
+			entry.Details += fmt.Sprintf(", discount %d (%s)", order.Discount, strings.Join(order.Promotions, ", "))
+		}
+		if err := writeAudit(ctx, tx, entry); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("%s: could not get affected rows: %w", op, err)
-	}
-	if rowsAffected != 1 {
-		return fmt.Errorf("%s: no employee row updated", op)
+		entry.ActorBalanceAfter = entry.ActorBalanceBefore
+		return order, r.auditFailure(ctx, entry, err)
 	}
 
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO purchases (employee_id, merch_id, count)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (employee_id, merch_id)
-		DO UPDATE SET count = purchases.count + EXCLUDED.count
-	`, employeeID, merchID, quantity)
+	return order, nil
+}
+
+// checkGiftRecipient проверяет, что сотруднику можно подарить товар: казне
+// и деактивированным сотрудникам подарки не отправляются.
+func checkGiftRecipient(ctx context.Context, tx *sql.Tx, id int, username string) error {
+	var system, active bool
+	err := tx.QueryRowContext(ctx, "SELECT is_system, active FROM employees WHERE id = $1", id).Scan(&system, &active)
 	if err != nil {
-		return fmt.Errorf("%s: could not update purchases: %w", op, err)
+		return fmt.Errorf("could not fetch recipient: %w", err)
 	}
-
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("%s: could not commit transaction: %w", op, err)
+	if system || !active {
+		return fmt.Errorf("%w: %s", storage.ErrUserNotFound, username)
 	}
 
 	return nil
 }
 
-func (r *Repository) SendCoins(senderUsername, receiverUsername string, amount int) error {
+func (r *Repository) SendCoins(ctx context.Context, senderUsername, receiverUsername string, amount int) error {
 	const op = "repository.SendCoins"
-	ctx := context.Background()
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("%s: could not begin transaction: %w", op, err)
+	entry := models.AuditEntry{
+		Actor:  senderUsername,
+		Action: audit.ActionTransfer,
+		Target: receiverUsername,
+		Amount: &amount,
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := r.transfer(ctx, tx, senderUsername, receiverUsername, amount, nil, &entry); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
 		}
-	}()
+		return nil
+	})
+	if err != nil {
+		entry.ActorBalanceAfter = entry.ActorBalanceBefore
+		entry.TargetBalanceAfter = entry.TargetBalanceBefore
+		return r.auditFailure(ctx, entry, err)
+	}
 
-	var senderID, senderBalance int
-	err = tx.QueryRowContext(ctx, `
-		SELECT id, balance 
-		FROM employees 
-		WHERE username = $1
-	`, senderUsername).Scan(&senderID, &senderBalance)
+	return nil
+}
+
+// transfer переводит монеты внутри транзакции: блокирует обоих сотрудников,
+// проверяет лимиты отправителя, меняет балансы и лоты, пишет перевод,
+// событие и запись аудита entry.
+// Балансы до и после попадают в entry и при ошибке. batchID - массовый перевод, может быть nil.
+func (r *Repository) transfer(ctx context.Context, tx *sql.Tx, senderUsername, receiverUsername string, amount int, batchID *int64, entry *models.AuditEntry) error {
+	employees, err := lockEmployees(ctx, tx, senderUsername, receiverUsername)
 	if err != nil {
-		return fmt.Errorf("%s: could not fetch sender data: %w", op, err)
+		return fmt.Errorf("could not fetch participants data: %w", err)
 	}
+	sender, receiver := employees[senderUsername], employees[receiverUsername]
 
-	if senderBalance < amount {
-		return fmt.Errorf("%s: insufficient balance", op)
+	senderBefore, receiverBefore := sender.balance, receiver.balance
+	entry.ActorBalanceBefore = &senderBefore
+	entry.TargetBalanceBefore = &receiverBefore
+
+	// казне и деактивированным сотрудникам монеты не переводятся, как и подарки
+	if receiver.system || !receiver.active {
+		return fmt.Errorf("%w: %s", storage.ErrUserNotFound, receiverUsername)
+	}
+	if sender.held {
+		return storage.ErrTransfersHeld
+	}
+	if sender.balance < amount {
+		return storage.ErrInsufficientBalance
 	}
 
-	var receiverID int
-	err = tx.QueryRowContext(ctx, `
-		SELECT id 
-		FROM employees 
-		WHERE username = $1
-	`, receiverUsername).Scan(&receiverID)
+	now := time.Now()
+	// у казны нет лимитов: из нее платятся гранты. Проверяется флаг, а не имя,
+	// чтобы обычный сотрудник с именем treasury не обходил лимиты
+	if !sender.system {
+		if err := r.checkLimits(ctx, tx, sender.id, receiver.id, amount, now); err != nil {
+			return err
+		}
+	}
+
+	lots, err := takeLots(ctx, tx, sender.id, amount, now)
 	if err != nil {
-		return fmt.Errorf("%s: could not fetch receiver data: %w", op, err)
+		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `
+	err = tx.QueryRowContext(ctx, `
 		UPDATE employees 
 		SET balance = balance - $1 
 		WHERE id = $2
-	`, amount, senderID)
+		RETURNING balance
+	`, amount, sender.id).Scan(&sender.balance)
 	if err != nil {
-		return fmt.Errorf("%s: could not update sender balance: %w", op, err)
+		return fmt.Errorf("could not update sender balance: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx, `
+	err = tx.QueryRowContext(ctx, `
 		UPDATE employees 
 		SET balance = balance + $1 
 		WHERE id = $2
-	`, amount, receiverID)
+		RETURNING balance
+	`, amount, receiver.id).Scan(&receiver.balance)
+	if err != nil {
+		return fmt.Errorf("could not update receiver balance: %w", err)
+	}
+
+	entry.ActorBalanceAfter = &sender.balance
+	entry.TargetBalanceAfter = &receiver.balance
+
+	var transactionID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (sender_id, receiver_id, amount, batch_id) 
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, sender.id, receiver.id, amount, batchID).Scan(&transactionID)
+	if err != nil {
+		return fmt.Errorf("could not insert transaction record: %w", err)
+	}
+
+	if err := r.moveLots(ctx, tx, sender.id, receiver.id, lots, transactionID, now); err != nil {
+		return err
+	}
+
+	event := events.CoinsTransferred{
+		FromUser:    senderUsername,
+		ToUser:      receiverUsername,
+		Amount:      amount,
+		FromBalance: sender.balance,
+		ToBalance:   receiver.balance,
+	}
+	if batchID != nil {
+		event.BatchID = *batchID
+	}
+	if err := addEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return writeAudit(ctx, tx, *entry)
+}
+
+type employeeRow struct {
+	id         int
+	balance    int
+	department string
+	// held - исходящие переводы запрещены до проверки на мошенничество
+	held bool
+	// system - служебный сотрудник (казна)
+	system bool
+	active bool
+}
+
+// lockEmployees блокирует строки сотрудников до конца транзакции.
+// Строки блокируются по возрастанию id, чтобы параллельные переводы не упирались в дедлок.
+func lockEmployees(ctx context.Context, tx *sql.Tx, usernames ...string) (map[string]*employeeRow, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, username, balance, department, transfers_held, is_system, active
+		FROM employees
+		WHERE username = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`, pq.Array(usernames))
 	if err != nil {
-		return fmt.Errorf("%s: could not update receiver balance: %w", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := make(map[string]*employeeRow, len(usernames))
+	for rows.Next() {
+		var username string
+		var row employeeRow
+		if err := rows.Scan(&row.id, &username, &row.balance, &row.department, &row.held, &row.system, &row.active); err != nil {
+			return nil, err
+		}
+		employees[username] = &row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	_, err = tx.ExecContext(ctx, `
-		INSERT INTO transactions (sender_id, receiver_id, amount) 
-		VALUES ($1, $2, $3)
-	`, senderID, receiverID, amount)
+	for _, username := range usernames {
+		if _, ok := employees[username]; !ok {
+			return nil, fmt.Errorf("%w: %s", storage.ErrUserNotFound, username)
+		}
+	}
+
+	return employees, nil
+}
+
+func (r *Repository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("%s: could not insert transaction record: %w", op, err)
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("%s: could not commit transaction: %w", op, err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// cancellableStatuses - заказ еще не отправлен и не выдан, покупатель может отменить его сам.
+var cancellableStatuses = []string{models.OrderPendingApproval, models.OrderPlaced, models.OrderReadyForPickup}
+
+// returnableStatuses - заказ оплачен и не отменен, товар можно вернуть через склад.
+var returnableStatuses = []string{models.OrderPlaced, models.OrderReadyForPickup, models.OrderShipped, models.OrderDelivered}
+
+// lockOwnOrder блокирует заказ username. Чужой заказ выглядит как несуществующий.
+func lockOwnOrder(ctx context.Context, tx *sql.Tx, username string, id int64) (orderRow, error) {
+	o, err := lockOrder(ctx, tx, id)
+	if err != nil {
+		return o, err
+	}
+	if o.Username != username {
+		return o, storage.ErrOrderNotFound
+	}
+
+	return o, nil
+}
+
+// CancelOrder отменяет свой неотправленный заказ в течение orders.cancel_window после
+// покупки: монеты возвращаются по цене покупки, товар - на склад и из инвентаря.
+func (r *Repository) CancelOrder(ctx context.Context, username string, id int64) (models.Order, error) {
+	const op = "repository.CancelOrder"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockOwnOrder(ctx, tx, username, id); err != nil {
+			return err
+		}
+		if !slices.Contains(cancellableStatuses, o.Status) {
+			return storage.ErrOrderTransition
+		}
+		if time.Since(o.CreatedAt) > r.orders.CancelWindow {
+			return storage.ErrCancelWindowClosed
+		}
+
+		if err := closeOrder(ctx, tx, &o, models.OrderCancelled, username, audit.ActionOrderCancel, ""); err != nil {
+			return err
+		}
+
+		o.History, err = orderHistory(ctx, tx, o.ID)
+		return err
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+// RequestReturn просит склад принять товар обратно. До решения товар остается в инвентаре.
+func (r *Repository) RequestReturn(ctx context.Context, username string, id int64, reason string) (models.Order, error) {
+	const op = "repository.RequestReturn"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockOwnOrder(ctx, tx, username, id); err != nil {
+			return err
+		}
+		if !slices.Contains(returnableStatuses, o.Status) {
+			return storage.ErrOrderTransition
+		}
+
+		err = moveOrder(ctx, tx, &o, models.OrderReturnRequested, username, audit.ActionOrderReturnRequest, reason)
+		if err != nil {
+			return err
+		}
+
+		o.History, err = orderHistory(ctx, tx, o.ID)
+		return err
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+// ResolveReturn принимает возврат (монеты и товар возвращаются, статус OrderReturned)
+// или отказывает в нем: заказ возвращается в статус, из которого просили возврат.
+func (r *Repository) ResolveReturn(ctx context.Context, actor string, id int64, accept bool, note string) (models.Order, error) {
+	const op = "repository.ResolveReturn"
+
+	var o orderRow
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if o, err = lockOrder(ctx, tx, id); err != nil {
+			return err
+		}
+		if o.Status != models.OrderReturnRequested {
+			return storage.ErrOrderTransition
+		}
+
+		if accept {
+			err = closeOrder(ctx, tx, &o, models.OrderReturned, actor, audit.ActionOrderReturn, note)
+		} else {
+			var prev string
+			if prev, err = statusBeforeReturn(ctx, tx, o.ID); err != nil {
+				return err
+			}
+			err = moveOrder(ctx, tx, &o, prev, actor, audit.ActionOrderReturnReject, note)
+		}
+		if err != nil {
+			return err
+		}
+
+		o.History, err = orderHistory(ctx, tx, o.ID)
+		return err
+	})
+	if err != nil {
+		return o.Order, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return o.Order, nil
+}
+
+func statusBeforeReturn(ctx context.Context, tx *sql.Tx, id int64) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `
+		SELECT status
+		FROM order_status_history
+		WHERE order_id = $1 AND status <> $2
+		ORDER BY id DESC
+		LIMIT 1
+	`, id, models.OrderReturnRequested).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.OrderDelivered, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not fetch order history: %w", err)
+	}
+
+	return status, nil
+}
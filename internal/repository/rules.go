@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// orderCounts - заказ считается в лимитах и использованиях промоакций.
+// Отклоненные, просроченные, отмененные и возвращенные заказы не считаются.
+const orderCounts = `o.status NOT IN ('rejected', 'expired', 'cancelled', 'returned')`
+
+// scanPurchaseRule собирает правило из колонок merch. Пустое правило - nil.
+func scanPurchaseRule(maxQuantity *int, period string, releaseAt *time.Time) *models.PurchaseRule {
+	rule := models.PurchaseRule{MaxQuantity: maxQuantity, Period: period, ReleaseAt: releaseAt}
+	if rule.Empty() {
+		return nil
+	}
+
+	return &rule
+}
+
+// purchaseWindow возвращает начало текущего периода правила и начало следующего.
+// У PeriodTotal окна нет: считаются все покупки.
+func purchaseWindow(period string, now time.Time) (since time.Time, resetsAt *time.Time) {
+	day, week := limitWindows(now)
+
+	var next time.Time
+	switch period {
+	case models.PeriodDay:
+		since, next = day, day.AddDate(0, 0, 1)
+	case models.PeriodWeek:
+		since, next = week, week.AddDate(0, 0, 7)
+	case models.PeriodMonth:
+		since = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = since.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, nil
+	}
+
+	return since, &next
+}
+
+// checkPurchaseRule проверяет, что employeeID может получить еще quantity штук товара:
+// считаются и свои покупки, и подарки ему. Сотрудник заблокирован до конца транзакции,
+// поэтому параллельные покупки не обойдут лимит.
+func checkPurchaseRule(ctx context.Context, tx *sql.Tx, employeeID, merchID int, rule *models.PurchaseRule, quantity int, now time.Time) error {
+	if rule == nil {
+		return nil
+	}
+	if rule.ReleaseAt != nil && now.Before(*rule.ReleaseAt) {
+		return &storage.NotReleasedError{ReleaseAt: *rule.ReleaseAt}
+	}
+	if rule.MaxQuantity == nil {
+		return nil
+	}
+
+	since, resetsAt := purchaseWindow(rule.Period, now)
+
+	var bought int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(o.quantity), 0)
+		FROM orders o
+		WHERE COALESCE(o.recipient_id, o.employee_id) = $1 AND o.merch_id = $2 AND o.created_at >= $3 AND `+orderCounts,
+		employeeID, merchID, since).Scan(&bought)
+	if err != nil {
+		return fmt.Errorf("could not count purchases: %w", err)
+	}
+	if bought+quantity > *rule.MaxQuantity {
+		return &storage.PurchaseLimitError{Period: rule.Period, Max: *rule.MaxQuantity, ResetsAt: resetsAt}
+	}
+
+	return nil
+}
+
+// SetPurchaseRule задает правило покупки товара. Пустое правило снимает ограничения.
+// Уже сделанные покупки считаются в новом лимите.
+func (r *Repository) SetPurchaseRule(ctx context.Context, actor, item string, rule models.PurchaseRule) (models.PurchaseRule, error) {
+	const op = "repository.SetPurchaseRule"
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE merch SET purchase_limit = $2, limit_period = $3, release_at = $4
+			WHERE merch_name = $1
+			RETURNING purchase_limit, limit_period, release_at
+		`, item, rule.MaxQuantity, rule.Period, rule.ReleaseAt).Scan(&rule.MaxQuantity, &rule.Period, &rule.ReleaseAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrMerchNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not update merch: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionAdminPurchaseRule,
+			Target:  item,
+			Details: purchaseRuleDetails(rule),
+		})
+	})
+	if err != nil {
+		return rule, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rule, nil
+}
+
+func purchaseRuleDetails(rule models.PurchaseRule) string {
+	limit := "unlimited"
+	if rule.MaxQuantity != nil {
+		limit = fmt.Sprintf("%d per %s", *rule.MaxQuantity, rule.Period)
+	}
+	release := "none"
+	if rule.ReleaseAt != nil {
+		release = rule.ReleaseAt.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("limit=%s release=%s", limit, release)
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+const scheduledTransferSelect = `
+	SELECT s.id, snd.username, rcv.username, s.amount, s.note, s.kind, s.cron,
+		s.interval_seconds, s.start_at, s.catch_up, s.status, s.pause_reason,
+		s.next_run_at, s.last_run_at, s.runs, s.created_at
+	FROM scheduled_transfers s
+	JOIN employees snd ON snd.id = s.sender_id
+	JOIN employees rcv ON rcv.id = s.receiver_id
+`
+
+func scanScheduledTransfer(row rowScanner) (models.ScheduledTransfer, error) {
+	var st models.ScheduledTransfer
+	err := row.Scan(&st.ID, &st.FromUser, &st.ToUser, &st.Amount, &st.Note, &st.Kind, &st.Cron,
+		&st.IntervalSeconds, &st.StartAt, &st.CatchUp, &st.Status, &st.PauseReason,
+		&st.NextRunAt, &st.LastRunAt, &st.Runs, &st.CreatedAt)
+	return st, err
+}
+
+// CreateScheduledTransfer сохраняет расписание. st.NextRunAt должен быть посчитан заранее.
+func (r *Repository) CreateScheduledTransfer(ctx context.Context, st models.ScheduledTransfer) (models.ScheduledTransfer, error) {
+	const op = "repository.CreateScheduledTransfer"
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO scheduled_transfers (
+			sender_id, receiver_id, amount, note, kind, cron,
+			interval_seconds, start_at, catch_up, next_run_at
+		)
+		SELECT snd.id, rcv.id, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM employees snd, employees rcv
+		WHERE snd.username = $1 AND rcv.username = $2
+		RETURNING id, status, runs, created_at
+	`, st.FromUser, st.ToUser, st.Amount, st.Note, st.Kind, st.Cron,
+		st.IntervalSeconds, st.StartAt, st.CatchUp, st.NextRunAt,
+	).Scan(&st.ID, &st.Status, &st.Runs, &st.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return st, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return st, fmt.Errorf("%s: could not insert scheduled transfer: %w", op, err)
+	}
+
+	return st, nil
+}
+
+// ListScheduledTransfers возвращает расписания отправителя.
+func (r *Repository) ListScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error) {
+	const op = "repository.ListScheduledTransfers"
+
+	rows, err := r.db.QueryContext(ctx, scheduledTransferSelect+`
+		WHERE snd.username = $1
+		ORDER BY s.id
+	`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching scheduled transfers: %w", op, err)
+	}
+	defer rows.Close()
+
+	transfers := []models.ScheduledTransfer{}
+	for rows.Next() {
+		st, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning scheduled transfer: %w", op, err)
+		}
+		transfers = append(transfers, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating scheduled transfers: %w", op, err)
+	}
+
+	return transfers, nil
+}
+
+func (r *Repository) GetScheduledTransfer(ctx context.Context, owner string, id int64) (models.ScheduledTransfer, error) {
+	const op = "repository.GetScheduledTransfer"
+
+	st, err := scanScheduledTransfer(r.db.QueryRowContext(ctx, scheduledTransferSelect+`
+		WHERE s.id = $1 AND snd.username = $2
+	`, id, owner))
+	if errors.Is(err, sql.ErrNoRows) {
+		return st, fmt.Errorf("%s: %w", op, storage.ErrScheduleNotFound)
+	}
+	if err != nil {
+		return st, fmt.Errorf("%s: error fetching scheduled transfer: %w", op, err)
+	}
+
+	return st, nil
+}
+
+// UpdateScheduledTransfer блокирует расписание и сохраняет изменения, внесенные update.
+// Меняются только сумма, комментарий, правило догоняния, статус и время следующего запуска.
+func (r *Repository) UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update func(st *models.ScheduledTransfer) error) (models.ScheduledTransfer, error) {
+	const op = "repository.UpdateScheduledTransfer"
+
+	var st models.ScheduledTransfer
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		st, err = scanScheduledTransfer(tx.QueryRowContext(ctx, scheduledTransferSelect+`
+			WHERE s.id = $1 AND snd.username = $2
+			FOR UPDATE OF s
+		`, id, owner))
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrScheduleNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch scheduled transfer: %w", err)
+		}
+
+		if err := update(&st); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE scheduled_transfers
+			SET amount = $2, note = $3, catch_up = $4, status = $5,
+				pause_reason = $6, next_run_at = $7
+			WHERE id = $1
+		`, st.ID, st.Amount, st.Note, st.CatchUp, st.Status, st.PauseReason, st.NextRunAt)
+		if err != nil {
+			return fmt.Errorf("could not update scheduled transfer: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return st, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return st, nil
+}
+
+func (r *Repository) DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error {
+	const op = "repository.DeleteScheduledTransfer"
+
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM scheduled_transfers s
+		USING employees e
+		WHERE s.sender_id = e.id AND e.username = $1 AND s.id = $2
+	`, owner, id)
+	if err != nil {
+		return fmt.Errorf("%s: could not delete scheduled transfer: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: could not get affected rows: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrScheduleNotFound)
+	}
+
+	return nil
+}
+
+// DueScheduledTransfers возвращает активные расписания, чей запуск уже наступил.
+func (r *Repository) DueScheduledTransfers(ctx context.Context, limit int) ([]models.ScheduledTransfer, error) {
+	const op = "repository.DueScheduledTransfers"
+
+	rows, err := r.db.QueryContext(ctx, scheduledTransferSelect+`
+		WHERE s.status = $1 AND s.next_run_at <= now()
+		ORDER BY s.next_run_at
+		LIMIT $2
+	`, models.ScheduleActive, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching due scheduled transfers: %w", op, err)
+	}
+	defer rows.Close()
+
+	var transfers []models.ScheduledTransfer
+	for rows.Next() {
+		st, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning scheduled transfer: %w", op, err)
+		}
+		transfers = append(transfers, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating scheduled transfers: %w", op, err)
+	}
+
+	return transfers, nil
+}
+
+// RunScheduledTransfer выполняет запуск st, назначенный на st.NextRunAt, и
+// переносит следующий запуск на next (nil - расписание завершено).
+// Если монет не хватает, получателя больше нет или перевод упирается в лимит,
+// расписание ставится на паузу, отправитель получает событие ScheduledTransferPaused,
+// а метод возвращает причину.
+// Запуск, который уже выполнил другой экземпляр сервиса, пропускается.
+func (r *Repository) RunScheduledTransfer(ctx context.Context, st models.ScheduledTransfer, next *time.Time) error {
+	const op = "repository.RunScheduledTransfer"
+
+	entry := models.AuditEntry{
+		Actor:   st.FromUser,
+		Action:  audit.ActionTransfer,
+		Target:  st.ToUser,
+		Amount:  &st.Amount,
+		Details: fmt.Sprintf("scheduled transfer %d", st.ID),
+	}
+
+	var pauseErr error
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		ok, err := claimScheduledRun(ctx, tx, st)
+		if err != nil || !ok {
+			return err
+		}
+
+		err = r.transfer(ctx, tx, st.FromUser, st.ToUser, st.Amount, nil, &entry)
+
+		reason := ""
+		switch {
+		case errors.Is(err, storage.ErrInsufficientBalance):
+			reason = models.PauseInsufficientBalance
+		case errors.Is(err, storage.ErrUserNotFound):
+			reason = models.PauseReceiverNotFound
+		case errors.Is(err, storage.ErrLimitExceeded):
+			reason = models.PauseTransferLimit
+		case errors.Is(err, storage.ErrTransfersHeld):
+			reason = models.PauseTransfersHeld
+		case err != nil:
+			return err
+		}
+		if reason != "" {
+			// перевод упал до изменения балансов, транзакцией еще можно пользоваться
+			pauseErr = err
+			return pauseScheduledTransfer(ctx, tx, st, reason)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE scheduled_transfers
+			SET runs = runs + 1, last_run_at = now(), next_run_at = $2,
+				status = CASE WHEN $2::timestamptz IS NULL THEN $3 ELSE status END
+			WHERE id = $1
+		`, st.ID, next, models.ScheduleCompleted)
+		if err != nil {
+			return fmt.Errorf("could not update scheduled transfer: %w", err)
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = pauseErr
+	}
+	if err != nil {
+		entry.ActorBalanceAfter = entry.ActorBalanceBefore
+		entry.TargetBalanceAfter = entry.TargetBalanceBefore
+		return r.auditFailure(ctx, entry, fmt.Errorf("%s: %w", op, err))
+	}
+
+	return nil
+}
+
+// SkipScheduledRun пропускает запуск st, назначенный на st.NextRunAt, без перевода.
+func (r *Repository) SkipScheduledRun(ctx context.Context, st models.ScheduledTransfer, next *time.Time) error {
+	const op = "repository.SkipScheduledRun"
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET next_run_at = $3,
+			status = CASE WHEN $3::timestamptz IS NULL THEN $4 ELSE status END
+		WHERE id = $1 AND status = $5 AND next_run_at = $2
+	`, st.ID, st.NextRunAt, next, models.ScheduleCompleted, models.ScheduleActive)
+	if err != nil {
+		return fmt.Errorf("%s: could not skip scheduled run: %w", op, err)
+	}
+
+	return nil
+}
+
+// claimScheduledRun блокирует расписание, если его запуск все еще ждет выполнения.
+func claimScheduledRun(ctx context.Context, tx *sql.Tx, st models.ScheduledTransfer) (bool, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM scheduled_transfers
+		WHERE id = $1 AND status = $2 AND next_run_at = $3
+		FOR UPDATE SKIP LOCKED
+	`, st.ID, models.ScheduleActive, st.NextRunAt).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not lock scheduled transfer: %w", err)
+	}
+
+	return true, nil
+}
+
+func pauseScheduledTransfer(ctx context.Context, tx *sql.Tx, st models.ScheduledTransfer, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = $2, pause_reason = $3
+		WHERE id = $1
+	`, st.ID, models.SchedulePaused, reason)
+	if err != nil {
+		return fmt.Errorf("could not pause scheduled transfer: %w", err)
+	}
+
+	return addEvent(ctx, tx, events.ScheduledTransferPaused{
+		Username:   st.FromUser,
+		ScheduleID: st.ID,
+		ToUser:     st.ToUser,
+		Amount:     st.Amount,
+		Reason:     reason,
+	})
+}
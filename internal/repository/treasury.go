@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// UpdateEmployee меняет отдел, признак активности, роль руководителя и запрет переводов сотрудника.
+// Системных сотрудников менять нельзя.
+func (r *Repository) UpdateEmployee(ctx context.Context, actor, username string, upd models.EmployeeUpdate) (models.Employee, error) {
+	const op = "repository.UpdateEmployee"
+
+	e := models.Employee{Username: username}
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE employees
+			SET department = COALESCE($2, department), active = COALESCE($3, active),
+				transfers_held = COALESCE($4, transfers_held), is_manager = COALESCE($5, is_manager)
+			WHERE username = $1 AND NOT is_system
+			RETURNING department, active, is_manager, balance, transfers_held
+		`, username, upd.Department, upd.Active, upd.TransfersHeld, upd.Manager).
+			Scan(&e.Department, &e.Active, &e.Manager, &e.Balance, &e.TransfersHeld)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not update employee: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionAdminEmployee,
+			Target:  username,
+			Details: fmt.Sprintf("department=%q active=%t manager=%t transfers_held=%t", e.Department, e.Active, e.Manager, e.TransfersHeld),
+		})
+	})
+	if err != nil {
+		return e, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return e, nil
+}
+
+func (r *Repository) TreasuryBalance(ctx context.Context) (int, error) {
+	const op = "repository.TreasuryBalance"
+
+	var balance int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT balance FROM employees WHERE username = $1 AND is_system
+	`, models.TreasuryUsername).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s: treasury account is missing: %w", op, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: error fetching treasury balance: %w", op, err)
+	}
+
+	return balance, nil
+}
+
+// MintTreasury выпускает amount новых монет в казну и возвращает ее баланс.
+func (r *Repository) MintTreasury(ctx context.Context, actor string, amount int, note string) (int, error) {
+	const op = "repository.MintTreasury"
+
+	var id, before, after int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE employees
+			SET balance = balance + $2
+			WHERE username = $1 AND is_system
+			RETURNING id, balance - $2, balance
+		`, models.TreasuryUsername, amount).Scan(&id, &before, &after)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("treasury account is missing: %w", storage.ErrUserNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("could not update treasury balance: %w", err)
+		}
+
+		// монеты казны не сгорают, срок появляется при выплате гранта
+		if err := creditLot(ctx, tx, id, amount, models.LotMint, nil, ledgerRef{kind: models.LedgerIssue, details: note}); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:               actor,
+			Action:              audit.ActionTreasuryMint,
+			Target:              models.TreasuryUsername,
+			Amount:              &amount,
+			TargetBalanceBefore: &before,
+			TargetBalanceAfter:  &after,
+			Details:             note,
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return after, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/lib/audit"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+// ListMerch возвращает каталог: товары с активными вариантами и правилами покупки.
+func (r *Repository) ListMerch(ctx context.Context) ([]models.MerchItem, error) {
+	const op = "repository.ListMerch"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.merch_name, m.category, m.price, m.purchase_limit, m.limit_period, m.release_at, v.id, v.size, v.color, v.stock
+		FROM merch m
+		JOIN merch_variants v ON v.merch_id = m.id AND v.active
+		ORDER BY m.price, m.merch_name, v.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching merch: %w", op, err)
+	}
+	defer rows.Close()
+
+	items := []models.MerchItem{}
+	for rows.Next() {
+		var (
+			item        models.MerchItem
+			maxQuantity *int
+			period      string
+			releaseAt   *time.Time
+		)
+		v := models.MerchVariant{Active: true}
+		err := rows.Scan(&item.Name, &item.Category, &item.Price, &maxQuantity, &period, &releaseAt, &v.ID, &v.Size, &v.Color, &v.Stock)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning merch item: %w", op, err)
+		}
+		item.Rule = scanPurchaseRule(maxQuantity, period, releaseAt)
+
+		if n := len(items); n > 0 && items[n-1].Name == item.Name {
+			items[n-1].Variants = append(items[n-1].Variants, v)
+			continue
+		}
+		item.Variants = []models.MerchVariant{v}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating merch: %w", op, err)
+	}
+
+	return items, nil
+}
+
+// pickVariant находит активный вариант товара по выбору покупателя. Пустое поле
+// выбора подходит к любому значению; если подходит несколько вариантов, берется
+// совпадающий точно (без выбора - вариант по умолчанию), иначе покупатель должен уточнить.
+func pickVariant(ctx context.Context, tx *sql.Tx, merchID int, choice models.VariantChoice) (models.MerchVariant, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, size, color, stock
+		FROM merch_variants
+		WHERE merch_id = $1 AND active
+			AND ($2 = '' OR size = $2) AND ($3 = '' OR color = $3)
+		ORDER BY id
+	`, merchID, choice.Size, choice.Color)
+	if err != nil {
+		return models.MerchVariant{}, fmt.Errorf("could not fetch merch variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []models.MerchVariant
+	for rows.Next() {
+		v := models.MerchVariant{Active: true}
+		if err := rows.Scan(&v.ID, &v.Size, &v.Color, &v.Stock); err != nil {
+			return models.MerchVariant{}, fmt.Errorf("could not scan merch variant: %w", err)
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return models.MerchVariant{}, fmt.Errorf("could not iterate merch variants: %w", err)
+	}
+
+	switch len(variants) {
+	case 0:
+		return models.MerchVariant{}, storage.ErrVariantNotFound
+	case 1:
+		return variants[0], nil
+	}
+	for _, v := range variants {
+		if v.Size == choice.Size && v.Color == choice.Color {
+			return v, nil
+		}
+	}
+
+	return models.MerchVariant{}, storage.ErrVariantRequired
+}
+
+const variantSelect = `
+	SELECT v.id, v.size, v.color, v.stock, v.active
+	FROM merch_variants v
+`
+
+func scanVariant(row rowScanner) (models.MerchVariant, error) {
+	var v models.MerchVariant
+	err := row.Scan(&v.ID, &v.Size, &v.Color, &v.Stock, &v.Active)
+	return v, err
+}
+
+// ListMerchVariants возвращает все варианты товара, включая снятые с продажи.
+func (r *Repository) ListMerchVariants(ctx context.Context, item string) ([]models.MerchVariant, error) {
+	const op = "repository.ListMerchVariants"
+
+	var merchID int
+	err := r.db.QueryRowContext(ctx, "SELECT id FROM merch WHERE merch_name = $1", item).Scan(&merchID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrMerchNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, variantSelect+" WHERE v.merch_id = $1 ORDER BY v.id", merchID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	variants := []models.MerchVariant{}
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		variants = append(variants, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return variants, nil
+}
+
+// CreateMerchVariant добавляет товару вариант v.
+func (r *Repository) CreateMerchVariant(ctx context.Context, actor, item string, v models.MerchVariant) (models.MerchVariant, error) {
+	const op = "repository.CreateMerchVariant"
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var merchID int
+		err := tx.QueryRowContext(ctx, "SELECT id FROM merch WHERE merch_name = $1", item).Scan(&merchID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrMerchNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not fetch merch: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO merch_variants (merch_id, size, color, stock, active)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (merch_id, size, color) DO NOTHING
+			RETURNING id
+		`, merchID, v.Size, v.Color, v.Stock, v.Active).Scan(&v.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrVariantExists
+		}
+		if err != nil {
+			return fmt.Errorf("could not insert merch variant: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionAdminVariant,
+			Target:  item,
+			Details: variantDetails("create", v),
+		})
+	})
+	if err != nil {
+		return v, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return v, nil
+}
+
+// UpdateMerchVariant задает остаток варианта (nil - без ограничений) и снимает его
+// с продажи или возвращает в продажу.
+func (r *Repository) UpdateMerchVariant(ctx context.Context, actor, item string, id int, stock *int, active bool) (models.MerchVariant, error) {
+	const op = "repository.UpdateMerchVariant"
+
+	var v models.MerchVariant
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		v, err = scanVariant(tx.QueryRowContext(ctx, `
+			UPDATE merch_variants v SET stock = $3, active = $4
+			FROM merch m
+			WHERE v.id = $1 AND m.id = v.merch_id AND m.merch_name = $2
+			RETURNING v.id, v.size, v.color, v.stock, v.active
+		`, id, item, stock, active))
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrVariantNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("could not update merch variant: %w", err)
+		}
+
+		return writeAudit(ctx, tx, models.AuditEntry{
+			Actor:   actor,
+			Action:  audit.ActionAdminVariant,
+			Target:  item,
+			Details: variantDetails("update", v),
+		})
+	})
+	if err != nil {
+		return v, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return v, nil
+}
+
+func variantDetails(action string, v models.MerchVariant) string {
+	stock := "unlimited"
+	if v.Stock != nil {
+		stock = fmt.Sprint(*v.Stock)
+	}
+
+	return fmt.Sprintf("%s variant %d size=%q color=%q stock=%s active=%t", action, v.ID, v.Size, v.Color, stock, v.Active)
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+func (r *Repository) CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	const op = "repository.CreateWebhook"
+
+	var employee *string
+	if sub.Employee != "" {
+		employee = &sub.Employee
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (owner_id, url, secret, event_types, employee)
+		SELECT id, $2, $3, $4, $5
+		FROM employees
+		WHERE username = $1
+		RETURNING id, created_at
+	`, sub.Owner, sub.URL, sub.Secret, pq.Array(sub.EventTypes), employee).Scan(&sub.ID, &sub.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sub, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+	if err != nil {
+		return sub, fmt.Errorf("%s: could not insert webhook: %w", op, err)
+	}
+
+	return sub, nil
+}
+
+// ListWebhooks возвращает подписки владельца без секретов.
+func (r *Repository) ListWebhooks(ctx context.Context, owner string) ([]models.WebhookSubscription, error) {
+	const op = "repository.ListWebhooks"
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.id, w.url, w.event_types, COALESCE(w.employee, ''), w.created_at
+		FROM webhook_subscriptions w
+		JOIN employees e ON w.owner_id = e.id
+		WHERE e.username = $1
+		ORDER BY w.id
+	`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching webhooks: %w", op, err)
+	}
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub := models.WebhookSubscription{Owner: owner}
+		err := rows.Scan(&sub.ID, &sub.URL, pq.Array(&sub.EventTypes), &sub.Employee, &sub.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning webhook: %w", op, err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating webhooks: %w", op, err)
+	}
+
+	return subs, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, owner string, id int64) error {
+	const op = "repository.DeleteWebhook"
+
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM webhook_subscriptions w
+		USING employees e
+		WHERE w.owner_id = e.id AND e.username = $1 AND w.id = $2
+	`, owner, id)
+	if err != nil {
+		return fmt.Errorf("%s: could not delete webhook: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: could not get affected rows: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries возвращает историю доставок подписки от новых к старым.
+// Пустой status - доставки в любом статусе.
+func (r *Repository) ListWebhookDeliveries(ctx context.Context, owner string, subID int64, status string, limit int) ([]models.WebhookDelivery, error) {
+	const op = "repository.ListWebhookDeliveries"
+
+	if err := r.checkWebhookOwner(ctx, owner, subID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, subscription_id, event_id, event_type, status, attempts,
+			next_attempt_at, last_status_code, last_error, created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2::text = '' OR status = $2::text)
+		ORDER BY id DESC
+		LIMIT $3
+	`, subID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error fetching deliveries: %w", op, err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		err := rows.Scan(
+			&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: error scanning delivery: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating deliveries: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook возвращает доставку из dead-letter в очередь.
+func (r *Repository) RedeliverWebhook(ctx context.Context, owner string, subID, deliveryID int64) error {
+	const op = "repository.RedeliverWebhook"
+
+	if err := r.checkWebhookOwner(ctx, owner, subID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $3, attempts = 0, next_attempt_at = now(), last_error = ''
+		WHERE id = $1 AND subscription_id = $2 AND status = $4
+	`, deliveryID, subID, models.DeliveryPending, models.DeliveryDead)
+	if err != nil {
+		return fmt.Errorf("%s: could not requeue delivery: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: could not get affected rows: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrWebhookNotFound)
+	}
+
+	return nil
+}
+
+func (r *Repository) checkWebhookOwner(ctx context.Context, owner string, subID int64) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM webhook_subscriptions w
+			JOIN employees e ON w.owner_id = e.id
+			WHERE e.username = $1 AND w.id = $2
+		)
+	`, owner, subID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("could not check webhook owner: %w", err)
+	}
+	if !exists {
+		return storage.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries создает доставки события для всех подходящих подписок.
+// Повторный вызов для того же события ничего не меняет.
+func (r *Repository) EnqueueWebhookDeliveries(ctx context.Context, event events.Event, body []byte) (int, error) {
+	const op = "repository.EnqueueWebhookDeliveries"
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, body)
+		SELECT id, $1, $2::text, $3
+		FROM webhook_subscriptions
+		WHERE $2::text = ANY(event_types) AND (employee IS NULL OR employee = ANY($4::text[]))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, event.ID, event.Type, body, pq.Array(event.Usernames))
+	if err != nil {
+		return 0, fmt.Errorf("%s: could not insert deliveries: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: could not get affected rows: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// ClaimWebhookDeliveries берет в работу готовые к отправке доставки.
+// Взятые доставки откладываются на lease: если воркер упадет, их заберет другой.
+func (r *Repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	const op = "repository.ClaimWebhookDeliveries"
+
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_subscriptions w
+		WHERE w.id = d.subscription_id AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = $3 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.event_id, d.event_type, d.attempts, w.url, w.secret, d.body
+	`, limit, time.Now().Add(lease), models.DeliveryPending)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error claiming deliveries: %w", op, err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.PendingDelivery
+	for rows.Next() {
+		var d models.PendingDelivery
+		if err := rows.Scan(&d.ID, &d.EventID, &d.EventType, &d.Attempts, &d.URL, &d.Secret, &d.Body); err != nil {
+			return nil, fmt.Errorf("%s: error scanning delivery: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error iterating deliveries: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// CompleteWebhookDelivery фиксирует результат попытки.
+// nextAttempt = nil при ошибке переводит доставку в dead-letter.
+func (r *Repository) CompleteWebhookDelivery(ctx context.Context, id int64, statusCode int, deliveryErr error, nextAttempt *time.Time) error {
+	const op = "repository.CompleteWebhookDelivery"
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var err error
+	switch {
+	case deliveryErr == nil:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = attempts + 1, last_status_code = $3,
+				last_error = '', delivered_at = now()
+			WHERE id = $1
+		`, id, models.DeliveryDelivered, code)
+	case nextAttempt == nil:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = attempts + 1, last_status_code = $3, last_error = $4
+			WHERE id = $1
+		`, id, models.DeliveryDead, code, deliveryErr.Error())
+	default:
+		_, err = r.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, last_status_code = $2, last_error = $3, next_attempt_at = $4
+			WHERE id = $1
+		`, id, code, deliveryErr.Error(), *nextAttempt)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: could not update delivery: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+// Package scheduler выполняет запланированные переводы и кампании грантов
+// и отменяет заказы, которые не согласовали вовремя.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/lib/schedule"
+	"github.com/magneless/merch-shop/internal/models"
+	"github.com/magneless/merch-shop/internal/storage"
+)
+
+type Store interface {
+	DueScheduledTransfers(ctx context.Context, limit int) ([]models.ScheduledTransfer, error)
+	RunScheduledTransfer(ctx context.Context, st models.ScheduledTransfer, next *time.Time) error
+	SkipScheduledRun(ctx context.Context, st models.ScheduledTransfer, next *time.Time) error
+	DueGrantCampaigns(ctx context.Context, limit int) ([]models.GrantCampaign, error)
+	ExecuteGrantCampaign(ctx context.Context, actor string, id int64) (models.GrantRun, error)
+	OverdueOrders(ctx context.Context, now time.Time, limit int) ([]models.Order, error)
+	ExpireOrder(ctx context.Context, actor string, id int64, now time.Time) error
+}
+
+// Actor - от чьего имени планировщик пишет в журнал аудита.
+const Actor = "scheduler"
+
+// Scheduler раз в PollInterval выполняет наступившие запуски. Расписания
+// хранятся в базе, поэтому после простоя пропущенные запуски догоняются
+// по правилу catchUp каждого расписания.
+type Scheduler struct {
+	log   *slog.Logger
+	store Store
+	cfg   config.Scheduler
+}
+
+func New(log *slog.Logger, store Store, cfg config.Scheduler) *Scheduler {
+	return &Scheduler{
+		log:   log.With(slog.String("component", "scheduler")),
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	s.log.Info("scheduler started")
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx)
+		s.runGrants(ctx)
+		s.expireOrders(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.log.Info("scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	due, err := s.store.DueScheduledTransfers(ctx, s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("failed to fetch due scheduled transfers", sl.Err(err))
+		}
+		return
+	}
+
+	for _, st := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, st, time.Now())
+	}
+}
+
+func (s *Scheduler) runGrants(ctx context.Context) {
+	due, err := s.store.DueGrantCampaigns(ctx, s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("failed to fetch due grant campaigns", sl.Err(err))
+		}
+		return
+	}
+
+	for _, c := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		log := s.log.With(slog.Int64("campaign_id", c.ID), slog.String("name", c.Name))
+
+		// ошибка сохраняется в кампании, повторно ее запускает администратор
+		run, err := s.store.ExecuteGrantCampaign(ctx, Actor, c.ID)
+		if err != nil {
+			log.Error("grant campaign failed", sl.Err(err))
+			continue
+		}
+
+		log.Info("grant campaign executed", slog.Int("recipients", run.Recipients), slog.Int("total", run.Total))
+	}
+}
+
+func (s *Scheduler) expireOrders(ctx context.Context) {
+	now := time.Now()
+
+	overdue, err := s.store.OverdueOrders(ctx, now, s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.log.Error("failed to fetch overdue orders", sl.Err(err))
+		}
+		return
+	}
+
+	for _, o := range overdue {
+		if ctx.Err() != nil {
+			return
+		}
+
+		log := s.log.With(slog.Int64("order_id", o.ID), slog.String("username", o.Username))
+
+		if err := s.store.ExpireOrder(ctx, Actor, o.ID, now); err != nil {
+			log.Error("failed to expire order", sl.Err(err))
+			continue
+		}
+
+		log.Info("order approval expired, coins released", slog.Int("total", o.Total))
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, st models.ScheduledTransfer, now time.Time) {
+	log := s.log.With(
+		slog.Int64("schedule_id", st.ID),
+		slog.String("sender", st.FromUser),
+		slog.Time("due", *st.NextRunAt),
+	)
+
+	next, skip, err := s.plan(st, now)
+	if err != nil {
+		log.Error("failed to compute next run", sl.Err(err))
+		return
+	}
+
+	if skip {
+		if err := s.store.SkipScheduledRun(ctx, st, next); err != nil {
+			log.Error("failed to skip scheduled run", sl.Err(err))
+			return
+		}
+		log.Warn("late scheduled run skipped")
+		return
+	}
+
+	err = s.store.RunScheduledTransfer(ctx, st, next)
+	switch {
+	case errors.Is(err, storage.ErrInsufficientBalance), errors.Is(err, storage.ErrUserNotFound),
+		errors.Is(err, storage.ErrLimitExceeded), errors.Is(err, storage.ErrTransfersHeld):
+		log.Warn("scheduled transfer paused", sl.Err(err))
+	case err != nil:
+		// запуск остался в очереди и будет повторен на следующем проходе
+		log.Error("failed to run scheduled transfer", sl.Err(err))
+	default:
+		log.Info("scheduled transfer completed")
+	}
+}
+
+// plan решает, что делать с наступившим запуском: выполнить или пропустить,
+// и когда запускать дальше (nil - больше не запускать).
+//   - catchUp=all: каждый пропущенный запуск выполняется, следующий считается от текущего;
+//   - catchUp=once: все пропущенные запуски выполняются одним, следующий считается от now;
+//   - catchUp=skip: как once, но запуск, опоздавший больше чем на Grace, не выполняется.
+func (s *Scheduler) plan(st models.ScheduledTransfer, now time.Time) (*time.Time, bool, error) {
+	due := *st.NextRunAt
+
+	after := now
+	if st.CatchUp == models.CatchUpAll {
+		after = due
+	}
+	skip := st.CatchUp == models.CatchUpSkip && now.Sub(due) > s.cfg.Grace
+
+	next, ok, err := schedule.Next(st, after)
+	if err != nil || !ok {
+		return nil, skip, err
+	}
+
+	return &next, skip, nil
+}
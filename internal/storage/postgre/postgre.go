@@ -11,10 +11,7 @@ import (
 func New(cfg config.Storage) (*sql.DB, error) {
 	const op = "storage.postgre.New"
 
-	db, err := sql.Open("postgres", fmt.Sprintf(
-		"host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode,
-	))
+	db, err := sql.Open("postgres", DSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -26,3 +23,10 @@ func New(cfg config.Storage) (*sql.DB, error) {
 
 	return db, nil
 }
+
+func DSN(cfg config.Storage) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode,
+	)
+}
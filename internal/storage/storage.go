@@ -1,11 +1,101 @@
 package storage
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrUserExists = errors.New("user exists")
+	ErrUserExists          = errors.New("user exists")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrWrongPassword       = errors.New("wrong password")
+	ErrMerchNotFound       = errors.New("merch not found")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrCoinRequestNotFound = errors.New("coin request not found")
+	ErrCoinRequestResolved = errors.New("coin request already resolved")
+	ErrCoinRequestExpired  = errors.New("coin request expired")
+	ErrScheduleNotFound    = errors.New("scheduled transfer not found")
+	ErrGrantNotFound       = errors.New("grant campaign not found")
+	ErrGrantExecuted       = errors.New("grant campaign already paid out")
+	ErrLimitExceeded       = errors.New("transfer limit exceeded")
+	ErrTransfersHeld       = errors.New("transfers are held for fraud review")
+	ErrFraudFlagNotFound   = errors.New("fraud flag not found")
+	ErrFraudFlagResolved   = errors.New("fraud flag already resolved")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrOrderResolved       = errors.New("order is not awaiting approval")
+	ErrOrderExpired        = errors.New("order approval expired")
+	ErrNotApprover         = errors.New("not allowed to approve this order")
+	ErrOrderTransition     = errors.New("order cannot move to this status")
+	ErrCancelWindowClosed  = errors.New("order cancellation window has closed")
+	ErrOutOfStock          = errors.New("merch is out of stock")
+	ErrVariantNotFound     = errors.New("merch variant not found")
+	ErrVariantRequired     = errors.New("merch has several variants, choose one")
+	ErrVariantExists       = errors.New("merch variant already exists")
+	ErrPromoNotFound       = errors.New("promotion not found")
+	ErrPromoNotApplicable  = errors.New("promotion does not apply to this purchase")
+	ErrPromoExhausted      = errors.New("promotion usage limit reached")
+	ErrPromoExists         = errors.New("promotion code already exists")
+	ErrPurchaseLimit       = errors.New("merch purchase limit exceeded")
+	ErrNotReleased         = errors.New("merch is not released yet")
+
+	// ErrRegistrationRejected - логин или пароль нового аккаунта не проходят правила.
+	ErrRegistrationRejected = errors.New("new account does not meet username or password policy")
 )
 
+// LimitError - перевод превышает лимит Limit (models.Limit*) со значением Max.
+// ResetsAt - когда начнется новое окно лимита, nil для лимита на один перевод.
+type LimitError struct {
+	Limit    string
+	Max      int
+	ResetsAt *time.Time
+}
+
+func (e *LimitError) Error() string {
+	if e.ResetsAt == nil {
+		return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+	}
+	return fmt.Sprintf("%s limit of %d exceeded until %s", e.Limit, e.Max, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// PurchaseLimitError - покупка превышает правило товара: не больше Max штук
+// за период Period (models.Period*). ResetsAt - начало следующего периода,
+// nil для лимита за все время.
+type PurchaseLimitError struct {
+	Period   string
+	Max      int
+	ResetsAt *time.Time
+}
+
+func (e *PurchaseLimitError) Error() string {
+	if e.ResetsAt == nil {
+		return fmt.Sprintf("purchase limit of %d per employee exceeded", e.Max)
+	}
+	return fmt.Sprintf("%s purchase limit of %d exceeded until %s", e.Period, e.Max, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *PurchaseLimitError) Unwrap() error {
+	return ErrPurchaseLimit
+}
+
+// NotReleasedError - товар поступит в продажу в ReleaseAt.
+type NotReleasedError struct {
+	ReleaseAt time.Time
+}
+
+func (e *NotReleasedError) Error() string {
+	return fmt.Sprintf("merch is on sale from %s", e.ReleaseAt.Format(time.RFC3339))
+}
+
+func (e *NotReleasedError) Unwrap() error {
+	return ErrNotReleased
+}
+
 const (
 	UniqueViolationErrorCode = "23505"
-)
\ No newline at end of file
+)
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"syscall"
+)
+
+// ErrForbiddenAddress - адрес вебхука ведет в локальную или внутреннюю сеть.
+var ErrForbiddenAddress = errors.New("webhook address is not allowed")
+
+// sharedAddressSpace - 100.64.0.0/10 (CGNAT), netip не считает его частным.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// addressGuard не дает вебхукам ходить во внутреннюю сеть сервиса.
+// Адрес проверяется при каждом соединении, уже после DNS, поэтому
+// не помогают ни редиректы, ни имена, которые резолвятся во внутренние адреса.
+type addressGuard struct {
+	allowed []netip.Prefix
+}
+
+func newAddressGuard(log *slog.Logger, networks []string) addressGuard {
+	var g addressGuard
+	for _, n := range networks {
+		prefix, err := netip.ParsePrefix(n)
+		if err != nil {
+			log.Error("invalid webhooks.allowed_networks entry, ignored", slog.String("network", n))
+			continue
+		}
+		g.allowed = append(g.allowed, prefix.Masked())
+	}
+	return g
+}
+
+func (g addressGuard) check(addr netip.Addr) error {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+
+	return nil
+}
+
+// control подключается к net.Dialer и проверяет адрес перед соединением.
+func (g addressGuard) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	return g.check(addrPort.Addr())
+}
@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/events"
+	"github.com/magneless/merch-shop/internal/lib/logger/sl"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+const (
+	HeaderEvent     = "X-Merch-Event"
+	HeaderDelivery  = "X-Merch-Delivery"
+	HeaderTimestamp = "X-Merch-Timestamp"
+	HeaderSignature = "X-Merch-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign считает подпись запроса: HMAC-SHA256 от "<timestamp>.<body>" с секретом подписки.
+// Получатель должен проверить подпись и отбросить запросы со старым timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет значение заголовка X-Merch-Signature.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// NewSecret генерирует секрет для новой подписки.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type DeliveryQueue interface {
+	EnqueueWebhookDeliveries(ctx context.Context, event events.Event, body []byte) (int, error)
+}
+
+// Sink - получатель outbox, который ставит событие в очередь доставки
+// каждой подходящей подписке.
+type Sink struct {
+	queue DeliveryQueue
+}
+
+func NewSink(queue DeliveryQueue) *Sink {
+	return &Sink{queue: queue}
+}
+
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+func (s *Sink) Publish(ctx context.Context, event events.Event) error {
+	body, err := json.Marshal(struct {
+		ID        int64           `json:"id"`
+		Type      string          `json:"type"`
+		CreatedAt time.Time       `json:"createdAt"`
+		Data      json.RawMessage `json:"data"`
+	}{event.ID, event.Type, event.CreatedAt, event.Payload})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	if _, err := s.queue.EnqueueWebhookDeliveries(ctx, event, body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type DeliveryStore interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64, statusCode int, deliveryErr error, nextAttempt *time.Time) error
+}
+
+// Deliverer отправляет доставки подписчикам и повторяет неудачные
+// с экспоненциальной задержкой, пока не кончатся попытки.
+type Deliverer struct {
+	log    *slog.Logger
+	store  DeliveryStore
+	client *http.Client
+	cfg    config.Webhooks
+}
+
+func NewDeliverer(log *slog.Logger, store DeliveryStore, cfg config.Webhooks) *Deliverer {
+	log = log.With(slog.String("component", "webhook.deliverer"))
+	guard := newAddressGuard(log, cfg.AllowedNetworks)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// через прокси проверка адреса назначения теряет смысл
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout: cfg.Timeout,
+		Control: guard.control,
+	}).DialContext
+
+	return &Deliverer{
+		log:    log,
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		cfg:    cfg,
+	}
+}
+
+func (d *Deliverer) Run(ctx context.Context) {
+	d.log.Info("webhook deliverer started")
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.deliverBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			d.log.Info("webhook deliverer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Deliverer) deliverBatch(ctx context.Context) {
+	deliveries, err := d.store.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.log.Error("failed to claim webhook deliveries", sl.Err(err))
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+func (d *Deliverer) deliver(ctx context.Context, delivery models.PendingDelivery) {
+	log := d.log.With(
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("event_id", delivery.EventID),
+	)
+
+	statusCode, err := d.send(ctx, delivery)
+
+	var nextAttempt *time.Time
+	if err != nil {
+		attempts := delivery.Attempts + 1
+		if attempts < d.cfg.MaxAttempts {
+			at := time.Now().Add(d.retryDelay(attempts))
+			nextAttempt = &at
+			log.Warn("webhook delivery failed, will retry", slog.Time("next_attempt", at), sl.Err(err))
+		} else {
+			log.Error("webhook delivery moved to dead letter", slog.Int("attempts", attempts), sl.Err(err))
+		}
+	}
+
+	if err := d.store.CompleteWebhookDelivery(ctx, delivery.ID, statusCode, err, nextAttempt); err != nil {
+		log.Error("failed to save webhook delivery result", sl.Err(err))
+	}
+}
+
+func (d *Deliverer) send(ctx context.Context, delivery models.PendingDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return 0, fmt.Errorf("%w: scheme %q", ErrForbiddenAddress, req.URL.Scheme)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "merch-shop-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Deliverer) retryDelay(attempts int) time.Duration {
+	delay := float64(d.cfg.RetryBase) * math.Pow(2, float64(attempts-1))
+	if delay > float64(d.cfg.RetryMax) {
+		return d.cfg.RetryMax
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,274 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/magneless/merch-shop/internal/config"
+	"github.com/magneless/merch-shop/internal/models"
+)
+
+// fakeStore хранит одну доставку и ведет ее так же, как репозиторий:
+// неудача с nextAttempt - новая попытка, без nextAttempt - dead letter.
+type fakeStore struct {
+	mu       sync.Mutex
+	delivery models.PendingDelivery
+	status   string
+	codes    []int
+	delays   []time.Duration
+}
+
+func (s *fakeStore) ClaimWebhookDeliveries(context.Context, int, time.Duration) ([]models.PendingDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != "pending" {
+		return nil, nil
+	}
+	return []models.PendingDelivery{s.delivery}, nil
+}
+
+func (s *fakeStore) CompleteWebhookDelivery(_ context.Context, _ int64, statusCode int, deliveryErr error, nextAttempt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delivery.Attempts++
+	s.codes = append(s.codes, statusCode)
+	switch {
+	case deliveryErr == nil:
+		s.status = "delivered"
+	case nextAttempt != nil:
+		s.delays = append(s.delays, time.Until(*nextAttempt))
+	default:
+		s.status = "dead"
+	}
+	return nil
+}
+
+// newTestDeliverer разрешает loopback: httptest слушает 127.0.0.1.
+func newTestDeliverer(store DeliveryStore, cfg config.Webhooks) *Deliverer {
+	cfg.AllowedNetworks = append(cfg.AllowedNetworks, "127.0.0.0/8")
+	return NewDeliverer(slog.New(slog.NewTextHandler(io.Discard, nil)), store, cfg)
+}
+
+func newDelivery(url string) models.PendingDelivery {
+	return models.PendingDelivery{
+		ID:        7,
+		EventID:   42,
+		EventType: "CoinsTransferred",
+		URL:       url,
+		Secret:    "s3cret",
+		Body:      []byte(`{"id":42,"type":"CoinsTransferred"}`),
+	}
+}
+
+func TestDeliverSignsBody(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{delivery: newDelivery(srv.URL), status: "pending"}
+	newTestDeliverer(store, config.Webhooks{Timeout: time.Second, MaxAttempts: 3}).deliverBatch(context.Background())
+
+	r := <-got
+	if string(r.body) != string(store.delivery.Body) {
+		t.Fatalf("body = %s, want %s", r.body, store.delivery.Body)
+	}
+	if r.header.Get(HeaderEvent) != "CoinsTransferred" || r.header.Get(HeaderDelivery) != "7" {
+		t.Errorf("event headers = %q, %q", r.header.Get(HeaderEvent), r.header.Get(HeaderDelivery))
+	}
+
+	timestamp, err := strconv.ParseInt(r.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp header: %v", err)
+	}
+	signature := r.header.Get(HeaderSignature)
+	if !Verify("s3cret", timestamp, r.body, signature) {
+		t.Errorf("signature %q does not match body", signature)
+	}
+	if Verify("other", timestamp, r.body, signature) {
+		t.Error("signature verified with a wrong secret")
+	}
+	if Verify("s3cret", timestamp+1, r.body, signature) {
+		t.Error("signature verified with a wrong timestamp")
+	}
+	if store.status != "delivered" {
+		t.Errorf("status = %s, want delivered", store.status)
+	}
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{delivery: newDelivery(srv.URL), status: "pending"}
+	d := newTestDeliverer(store, config.Webhooks{
+		Timeout:     time.Second,
+		MaxAttempts: 8,
+		RetryBase:   time.Minute,
+		RetryMax:    time.Hour,
+	})
+	for i := 0; i < 10 && store.status == "pending"; i++ {
+		d.deliverBatch(context.Background())
+	}
+
+	if store.status != "delivered" || store.delivery.Attempts != 4 {
+		t.Fatalf("status = %s after %d attempts, want delivered after 4", store.status, store.delivery.Attempts)
+	}
+	wantCodes := []int{503, 503, 503, 200}
+	for i, code := range wantCodes {
+		if store.codes[i] != code {
+			t.Errorf("attempt %d status = %d, want %d", i+1, store.codes[i], code)
+		}
+	}
+
+	// 1, 2, 4 минуты
+	for i, delay := range store.delays {
+		want := time.Minute << i
+		if delay < want-time.Second || delay > want {
+			t.Errorf("retry %d delay = %s, want %s", i+1, delay, want)
+		}
+	}
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	d := newTestDeliverer(&fakeStore{}, config.Webhooks{RetryBase: 5 * time.Second, RetryMax: time.Minute})
+
+	tests := map[int]time.Duration{
+		1: 5 * time.Second,
+		2: 10 * time.Second,
+		3: 20 * time.Second,
+		4: 40 * time.Second,
+		5: time.Minute,
+		9: time.Minute,
+	}
+	for attempts, want := range tests {
+		if got := d.retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestDeliverMovesToDeadLetter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{delivery: newDelivery(srv.URL), status: "pending"}
+	d := newTestDeliverer(store, config.Webhooks{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryBase:   time.Second,
+		RetryMax:    time.Minute,
+	})
+	for i := 0; i < 10 && store.status == "pending"; i++ {
+		d.deliverBatch(context.Background())
+	}
+
+	if store.status != "dead" {
+		t.Fatalf("status = %s, want dead", store.status)
+	}
+	if requests != 3 || store.delivery.Attempts != 3 {
+		t.Errorf("requests = %d, attempts = %d, want 3", requests, store.delivery.Attempts)
+	}
+	if len(store.delays) != 2 {
+		t.Errorf("retries scheduled = %d, want 2", len(store.delays))
+	}
+}
+
+func TestDeliverRejectsLocalAddresses(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	store := &fakeStore{delivery: newDelivery(srv.URL), status: "pending"}
+	d := NewDeliverer(slog.New(slog.NewTextHandler(io.Discard, nil)), store, config.Webhooks{
+		Timeout:     time.Second,
+		MaxAttempts: 1,
+	})
+	d.deliverBatch(context.Background())
+
+	if requests != 0 {
+		t.Fatalf("requests = %d, want none to loopback", requests)
+	}
+	if store.status != "dead" {
+		t.Errorf("status = %s, want dead", store.status)
+	}
+
+	if _, err := d.send(context.Background(), newDelivery("ftp://example.com/hook")); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("ftp scheme: err = %v, want ErrForbiddenAddress", err)
+	}
+}
+
+func TestAddressGuard(t *testing.T) {
+	g := newAddressGuard(slog.New(slog.NewTextHandler(io.Discard, nil)), []string{"10.20.0.0/16", "bad"})
+
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"10.20.3.4":        true,
+		"10.21.0.1":        false,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"::ffff:127.0.0.1": false,
+		"0.0.0.0":          false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"192.168.1.10":     false,
+		"172.16.0.1":       false,
+		"fd00::1":          false,
+		"100.64.0.1":       false,
+	}
+	for addr, allowed := range tests {
+		err := g.check(netip.MustParseAddr(addr))
+		if allowed && err != nil {
+			t.Errorf("%s: unexpected error %v", addr, err)
+		}
+		if !allowed && !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("%s: err = %v, want ErrForbiddenAddress", addr, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS coin_requests (
+    id BIGSERIAL PRIMARY KEY,
+    requester_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    note TEXT NOT NULL DEFAULT '',
+    -- pending -> accepted | declined | expired
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    CHECK (requester_id <> payer_id)
+);
+
+CREATE INDEX IF NOT EXISTS coin_requests_payer_idx ON coin_requests (payer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS coin_requests_requester_idx ON coin_requests (requester_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS transfer_batches;
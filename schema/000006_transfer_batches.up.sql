@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id BIGSERIAL PRIMARY KEY,
+    sender_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    total INT NOT NULL CHECK (total > 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS batch_id BIGINT REFERENCES transfer_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS transactions_batch_idx ON transactions (batch_id) WHERE batch_id IS NOT NULL;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    sender_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    receiver_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    note TEXT NOT NULL DEFAULT '',
+    -- once | cron | interval
+    kind VARCHAR(16) NOT NULL,
+    cron TEXT NOT NULL DEFAULT '',
+    interval_seconds BIGINT NOT NULL DEFAULT 0,
+    start_at TIMESTAMPTZ NOT NULL,
+    catch_up VARCHAR(16) NOT NULL DEFAULT 'once',
+    -- active | paused | completed
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    pause_reason VARCHAR(64) NOT NULL DEFAULT '',
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    runs INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (sender_id <> receiver_id)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfers_sender_idx ON scheduled_transfers (sender_id);
+CREATE INDEX IF NOT EXISTS scheduled_transfers_due_idx ON scheduled_transfers (next_run_at) WHERE status = 'active';
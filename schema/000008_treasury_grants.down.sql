@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS grant_payouts;
+DROP TABLE IF EXISTS grant_campaigns;
+
+DELETE FROM employees WHERE username = 'treasury' AND is_system;
+
+ALTER TABLE employees
+    DROP COLUMN IF EXISTS is_system,
+    DROP COLUMN IF EXISTS active,
+    DROP COLUMN IF EXISTS department;
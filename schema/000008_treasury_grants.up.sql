@@ -0,0 +1,47 @@
+ALTER TABLE employees
+    ADD COLUMN IF NOT EXISTS department VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT false;
+
+-- казна: системный сотрудник, из которого выплачиваются гранты; войти под ним нельзя.
+-- Обычный сотрудник с именем treasury казной стать не должен: миграция останавливается
+-- и подсказывает, как освободить имя
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM employees WHERE username = 'treasury') THEN
+        RAISE EXCEPTION 'employee "treasury" already exists, but the name is reserved for the system treasury account'
+            USING HINT = 'Rename the employee (UPDATE employees SET username = ''treasury_1'' WHERE username = ''treasury''), tell them the new login and run the migration again.';
+    END IF;
+END
+$$;
+
+INSERT INTO employees (username, balance, password_hash, active, is_system)
+VALUES ('treasury', 0, '!', false, true);
+
+CREATE TABLE IF NOT EXISTS grant_campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    amount INT NOT NULL CHECK (amount > 0),
+    -- пусто - все активные сотрудники
+    department VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    -- draft | scheduled | completed | failed
+    status VARCHAR(16) NOT NULL,
+    scheduled_at TIMESTAMPTZ,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    executed_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS grant_campaigns_due_idx ON grant_campaigns (scheduled_at) WHERE status = 'scheduled';
+
+-- одна выплата на сотрудника: повторный запуск кампании доплачивает только тем, кто еще не получил
+CREATE TABLE IF NOT EXISTS grant_payouts (
+    campaign_id BIGINT NOT NULL REFERENCES grant_campaigns(id) ON DELETE CASCADE,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    amount INT NOT NULL,
+    batch_id BIGINT NOT NULL REFERENCES transfer_batches(id),
+    paid_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (campaign_id, employee_id)
+);
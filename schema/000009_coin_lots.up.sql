@@ -0,0 +1,45 @@
+-- монеты хранятся лотами: у каждого начисления свой срок годности.
+-- employees.balance остается суммой amount по лотам сотрудника
+CREATE TABLE IF NOT EXISTS coin_lots (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    -- сколько монет в лоте осталось
+    amount INT NOT NULL CHECK (amount >= 0),
+    initial_amount INT NOT NULL,
+    -- initial | mint | transfer | migration
+    source VARCHAR(16) NOT NULL,
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- NULL - не сгорает (казна)
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS coin_lots_employee_idx ON coin_lots (employee_id, expires_at) WHERE amount > 0;
+CREATE INDEX IF NOT EXISTS coin_lots_expires_idx ON coin_lots (expires_at) WHERE amount > 0;
+
+-- каждое изменение лота: начисление, перевод, покупка, сгорание
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    lot_id BIGINT NOT NULL REFERENCES coin_lots(id) ON DELETE CASCADE,
+    delta INT NOT NULL,
+    -- issue | transfer_in | transfer_out | purchase | expire
+    kind VARCHAR(16) NOT NULL,
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_employee_idx ON ledger_entries (employee_id, id);
+
+-- текущие балансы становятся одним лотом на сотрудника со сроком по умолчанию (12 месяцев)
+WITH lots AS (
+    INSERT INTO coin_lots (employee_id, amount, initial_amount, source, expires_at)
+    SELECT id, balance, balance, 'migration',
+        CASE WHEN is_system THEN NULL ELSE now() + interval '12 months' END
+    FROM employees
+    WHERE balance > 0
+    RETURNING id, employee_id, amount
+)
+INSERT INTO ledger_entries (employee_id, lot_id, delta, kind, details)
+SELECT employee_id, id, amount, 'issue', 'migration'
+FROM lots;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS transfer_limit_overrides;
+
+DROP INDEX IF EXISTS transactions_sender_created_idx;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,16 @@
+-- время старых переводов неизвестно: они не должны попадать в окна лимитов
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch';
+ALTER TABLE transactions ALTER COLUMN created_at SET DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS transactions_sender_created_idx ON transactions (sender_id, created_at);
+
+-- лимиты сотрудника, заданные администратором; NULL - как в конфиге, 0 - без ограничения
+CREATE TABLE IF NOT EXISTS transfer_limit_overrides (
+    employee_id INT PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    max_transfer INT CHECK (max_transfer >= 0),
+    daily INT CHECK (daily >= 0),
+    weekly INT CHECK (weekly >= 0),
+    daily_recipients INT CHECK (daily_recipients >= 0),
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS fraud_cursor;
+DROP TABLE IF EXISTS fraud_flags;
+
+ALTER TABLE employees
+    DROP COLUMN IF EXISTS transfers_held,
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,32 @@
+-- у существующих сотрудников дата регистрации неизвестна, они не считаются новыми
+ALTER TABLE employees
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
+    ADD COLUMN IF NOT EXISTS transfers_held BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE employees ALTER COLUMN created_at SET DEFAULT now();
+
+CREATE TABLE IF NOT EXISTS fraud_flags (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    -- cycle | ping_pong | fresh_fan_in
+    rule VARCHAR(32) NOT NULL,
+    -- правило и участники схемы: одна открытая отметка на схему
+    fingerprint TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    transaction_ids BIGINT[] NOT NULL,
+    -- open | dismissed | confirmed
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS fraud_flags_open_idx ON fraud_flags (employee_id, fingerprint) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS fraud_flags_status_idx ON fraud_flags (status, id);
+
+-- последний перевод, который видел инкрементальный анализ
+CREATE TABLE IF NOT EXISTS fraud_cursor (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    last_transaction_id BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO fraud_cursor (id) VALUES (true) ON CONFLICT DO NOTHING;
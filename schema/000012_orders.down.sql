@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_lots;
+DROP TABLE IF EXISTS orders;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS is_manager;
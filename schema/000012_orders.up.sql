@@ -0,0 +1,40 @@
+-- руководитель согласует покупки сотрудников своего отдела
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS is_manager BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    merch_id INT NOT NULL REFERENCES merch(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    price INT NOT NULL,
+    total INT NOT NULL,
+    -- completed | pending_approval | rejected | expired
+    status VARCHAR(32) NOT NULL,
+    -- правило согласования, под которое попал заказ
+    policy VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- до какого момента заказ ждет согласования
+    approve_by TIMESTAMPTZ,
+    resolved_by VARCHAR(255),
+    resolved_at TIMESTAMPTZ,
+    reason TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS orders_employee_idx ON orders (employee_id, id);
+CREATE INDEX IF NOT EXISTS orders_pending_idx ON orders (approve_by) WHERE status = 'pending_approval';
+
+-- из каких лотов списаны монеты заказа: при отказе они возвращаются туда же
+CREATE TABLE IF NOT EXISTS order_lots (
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    lot_id BIGINT NOT NULL REFERENCES coin_lots(id) ON DELETE CASCADE,
+    amount INT NOT NULL,
+    PRIMARY KEY (order_id, lot_id)
+);
+
+-- покупки, сделанные до заказов, переносятся в заказы: лимиты покупок и история
+-- смотрят только на orders. Дата неизвестна - 'epoch', как у старых переводов
+INSERT INTO orders (employee_id, merch_id, quantity, price, total, status, created_at)
+SELECT p.employee_id, p.merch_id, p.count, m.price, m.price * p.count, 'completed', 'epoch'
+FROM purchases p
+JOIN merch m ON m.id = p.merch_id
+WHERE p.count > 0;
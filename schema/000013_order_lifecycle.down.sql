@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_status_idx;
+DROP TABLE IF EXISTS order_status_history;
+
+UPDATE orders SET status = 'completed' WHERE status IN ('placed', 'ready_for_pickup', 'shipped', 'delivered');
+UPDATE orders SET status = 'rejected' WHERE status = 'cancelled';
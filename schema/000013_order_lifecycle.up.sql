@@ -0,0 +1,23 @@
+-- выданные заказы теперь проходят путь placed -> ready_for_pickup | shipped -> delivered
+-- покупки, перенесенные из purchases (000012), уже на руках у сотрудников
+UPDATE orders SET status = 'delivered' WHERE status = 'completed' AND created_at = 'epoch';
+UPDATE orders SET status = 'placed' WHERE status = 'completed';
+
+-- когда и кем заказ переведен в каждый статус
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_idx ON order_status_history (order_id, id);
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status, id);
+
+-- у заказов, созданных раньше, истории нет: записываем текущий статус
+INSERT INTO order_status_history (order_id, status, changed_by, note, changed_at)
+SELECT o.id, o.status, COALESCE(o.resolved_by, e.username), o.reason, COALESCE(o.resolved_at, o.created_at)
+FROM orders o
+JOIN employees e ON e.id = o.employee_id;
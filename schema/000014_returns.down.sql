@@ -0,0 +1,4 @@
+UPDATE orders SET status = 'cancelled' WHERE status = 'returned';
+UPDATE orders SET status = 'delivered' WHERE status = 'return_requested';
+
+ALTER TABLE merch DROP COLUMN IF EXISTS stock;
@@ -0,0 +1,2 @@
+-- остаток на складе, NULL - без ограничений
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS stock INT CHECK (stock >= 0);
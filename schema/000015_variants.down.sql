@@ -0,0 +1,22 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS variant_id;
+
+-- покупки разных вариантов одного товара снова складываются
+CREATE TEMP TABLE purchases_by_merch AS
+SELECT employee_id, merch_id, SUM(count)::INT AS count
+FROM purchases
+GROUP BY employee_id, merch_id;
+
+DELETE FROM purchases;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS purchases_pkey;
+ALTER TABLE purchases DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE purchases ADD PRIMARY KEY (employee_id, merch_id);
+INSERT INTO purchases (employee_id, merch_id, count)
+SELECT employee_id, merch_id, count FROM purchases_by_merch;
+DROP TABLE purchases_by_merch;
+
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS stock INT CHECK (stock >= 0);
+UPDATE merch m SET stock = v.stock
+FROM merch_variants v
+WHERE v.merch_id = m.id AND v.size = '' AND v.color = '';
+
+DROP TABLE IF EXISTS merch_variants;
@@ -0,0 +1,34 @@
+-- товар (merch) продается в вариантах: размер и цвет, у каждого свой остаток
+CREATE TABLE IF NOT EXISTS merch_variants (
+    id SERIAL PRIMARY KEY,
+    merch_id INT NOT NULL REFERENCES merch(id) ON DELETE CASCADE,
+    size VARCHAR(32) NOT NULL DEFAULT '',
+    color VARCHAR(32) NOT NULL DEFAULT '',
+    -- NULL - без ограничений
+    stock INT CHECK (stock >= 0),
+    active BOOLEAN NOT NULL DEFAULT true,
+    UNIQUE (merch_id, size, color)
+);
+
+-- вариант по умолчанию (без размера и цвета) забирает остаток товара
+INSERT INTO merch_variants (merch_id, stock)
+SELECT id, stock FROM merch
+ON CONFLICT (merch_id, size, color) DO NOTHING;
+
+ALTER TABLE merch DROP COLUMN IF EXISTS stock;
+
+-- история покупок и заказов не должна пропадать вместе с вариантом:
+-- снятый с продажи вариант выключается через active
+ALTER TABLE purchases ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES merch_variants(id) ON DELETE RESTRICT;
+UPDATE purchases p SET variant_id = v.id
+FROM merch_variants v
+WHERE v.merch_id = p.merch_id AND v.size = '' AND v.color = '';
+ALTER TABLE purchases ALTER COLUMN variant_id SET NOT NULL;
+ALTER TABLE purchases DROP CONSTRAINT IF EXISTS purchases_pkey;
+ALTER TABLE purchases ADD PRIMARY KEY (employee_id, variant_id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS variant_id INT REFERENCES merch_variants(id) ON DELETE RESTRICT;
+UPDATE orders o SET variant_id = v.id
+FROM merch_variants v
+WHERE v.merch_id = o.merch_id AND v.size = '' AND v.color = '';
+ALTER TABLE orders ALTER COLUMN variant_id SET NOT NULL;
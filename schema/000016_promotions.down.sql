@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+
+DROP TABLE IF EXISTS order_promotions;
+DROP TABLE IF EXISTS promotions;
+
+ALTER TABLE merch DROP COLUMN IF EXISTS category;
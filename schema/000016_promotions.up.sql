@@ -0,0 +1,42 @@
+-- категория товара, на нее можно ограничить скидку
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS category VARCHAR(32) NOT NULL DEFAULT '';
+
+UPDATE merch SET category = 'hoodies' WHERE merch_name IN ('hoody', 'pink-hoody');
+UPDATE merch SET category = 'apparel' WHERE merch_name IN ('t-shirt', 'socks');
+UPDATE merch SET category = 'office' WHERE merch_name IN ('cup', 'book', 'pen');
+UPDATE merch SET category = 'accessories' WHERE merch_name IN ('powerbank', 'umbrella', 'wallet');
+
+-- промоакция без кода применяется сама, с кодом - только если покупатель его ввел;
+-- пустой список товаров, категорий или сотрудников - без ограничения
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(32) UNIQUE,
+    kind VARCHAR(16) NOT NULL,
+    value INT NOT NULL CHECK (value > 0),
+    items TEXT[] NOT NULL DEFAULT '{}',
+    categories TEXT[] NOT NULL DEFAULT '{}',
+    usernames TEXT[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    -- NULL - без ограничений
+    max_uses INT CHECK (max_uses > 0),
+    max_uses_per_user INT CHECK (max_uses_per_user > 0),
+    stackable BOOLEAN NOT NULL DEFAULT false,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- скидки, примененные к заказу; по ним же считаются использования промоакции
+CREATE TABLE IF NOT EXISTS order_promotions (
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    amount INT NOT NULL,
+    PRIMARY KEY (order_id, promotion_id)
+);
+
+CREATE INDEX IF NOT EXISTS order_promotions_promotion_idx ON order_promotions (promotion_id);
+
+-- price - цена по прайсу, total = price * quantity - discount
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount INT NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_employee_merch_idx;
+
+ALTER TABLE merch DROP COLUMN IF EXISTS release_at;
+ALTER TABLE merch DROP COLUMN IF EXISTS limit_period;
+ALTER TABLE merch DROP COLUMN IF EXISTS purchase_limit;
@@ -0,0 +1,7 @@
+-- правило покупки товара: не больше purchase_limit штук на сотрудника за limit_period
+-- (total - за все время) и не раньше release_at; NULL - без ограничения
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS purchase_limit INT CHECK (purchase_limit > 0);
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS limit_period VARCHAR(16) NOT NULL DEFAULT 'total';
+ALTER TABLE merch ADD COLUMN IF NOT EXISTS release_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS orders_employee_merch_idx ON orders (employee_id, merch_id, created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orders_owner_merch_idx;
+DROP INDEX IF EXISTS orders_recipient_idx;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS gift_message;
+ALTER TABLE orders DROP COLUMN IF EXISTS recipient_id;
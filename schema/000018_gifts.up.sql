@@ -0,0 +1,7 @@
+-- подарок: платит employee_id, товар получает recipient_id
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS recipient_id INT REFERENCES employees(id) ON DELETE CASCADE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS gift_message VARCHAR(200) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS orders_recipient_idx ON orders (recipient_id) WHERE recipient_id IS NOT NULL;
+-- лимиты покупок считаются по тому, кто получает товар
+CREATE INDEX IF NOT EXISTS orders_owner_merch_idx ON orders ((COALESCE(recipient_id, employee_id)), merch_id, created_at);
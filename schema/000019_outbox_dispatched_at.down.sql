@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS outbox_dispatched_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS dispatched_at;
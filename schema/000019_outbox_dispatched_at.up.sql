@@ -0,0 +1,7 @@
+-- момент передачи события в sinks. ID выдается до коммита и не отражает
+-- порядок, в котором события видят подписчики, поэтому досылка после
+-- переподключения ищет опоздавшие события по этому времени
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS dispatched_at TIMESTAMPTZ;
+UPDATE outbox SET dispatched_at = published_at WHERE dispatched_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS outbox_dispatched_idx ON outbox (dispatched_at);
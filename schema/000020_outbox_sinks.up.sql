@@ -0,0 +1,8 @@
+-- получатели, уже принявшие событие: повтор идет только в остальные
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS delivered_sinks TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at)
+    WHERE published_at IS NULL AND dead_at IS NULL;